@@ -0,0 +1,48 @@
+// Package controller 审计日志控制器
+package controller
+
+import (
+	"godash/domain"
+	"godash/domain/vo"
+	"godash/infra"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindController("/audit", &AuditController{}, Policy("audit"))
+	})
+}
+
+// AuditController 审计日志控制器
+type AuditController struct {
+	BaseController
+	AuditSev *domain.AuditService
+}
+
+// Get 审计日志列表，支持按实体和操作人筛选
+// GET /audit?entity=user&actor=admin
+func (c *AuditController) Get() freedom.Result {
+	var params vo.SearchParams
+	if err := c.Request.ReadQuery(&params, false); err != nil {
+		params = vo.SearchParams{}
+	}
+	var filter vo.AuditFilter
+	c.ReadFilter(&filter)
+
+	query := c.ListQuery(vo.SearchParams{Page: params.Page, PageSize: params.PageSize})
+	page, err := c.AuditSev.List(query, filter)
+	if err != nil {
+		return c.HandleServiceError(err, "审计日志")
+	}
+
+	return &infra.ViewResponse{
+		Name: "audit/list.html",
+		Data: vo.AuditListData{
+			Logs:     page.Items,
+			PageInfo: page.PageInfo,
+			Filter:   filter,
+		},
+	}
+}
@@ -0,0 +1,109 @@
+// Package controller 登录认证控制器
+package controller
+
+import (
+	"errors"
+	"godash/domain"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/infra"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		// 绑定登录控制器，提供 /login 和 /logout
+		initiator.BindController("/", &AuthController{})
+	})
+}
+
+// AuthController 登录认证控制器
+type AuthController struct {
+	BaseController
+	UserSev *domain.UserService
+	Session *infra.Session
+}
+
+// GetLogin 显示登录页面
+// GET /login
+func (c *AuthController) GetLogin() freedom.Result {
+	if _, ok := c.Session.User(); ok {
+		return &infra.RedirectResponse{URL: "/"}
+	}
+	return &infra.ViewResponse{
+		Name: "auth/login.html",
+		Data: map[string]interface{}{
+			"Form": vo.LoginFormData{},
+		},
+	}
+}
+
+// PostLogin 校验用户名和密码并写入会话
+// POST /login
+func (c *AuthController) PostLogin() freedom.Result {
+	var formData vo.LoginFormData
+	if err := c.Request.ReadForm(&formData, true); err != nil {
+		return c.loginFailed(formData, "请输入用户名和密码")
+	}
+
+	user, err := c.UserSev.Authenticate(formData.Username, formData.Password)
+	if errors.Is(err, domain.ErrInvalidCredentials) || errors.Is(err, domain.ErrUserInactive) {
+		c.Worker.Logger().Warn("登录失败", freedom.LogFields{"username": formData.Username, "reason": err.Error()})
+		return c.loginFailed(formData, err.Error())
+	}
+	if err != nil {
+		return c.HandleServiceError(err, "用户")
+	}
+
+	c.Session.Login(infra.SessionUser{
+		ID:       user.ID,
+		Username: user.Username,
+		RealName: user.RealName,
+		Role:     user.Role,
+	})
+	c.Worker.Logger().Info("用户登录", freedom.LogFields{"id": user.ID, "username": user.Username})
+	return &infra.RedirectResponse{URL: "/"}
+}
+
+// LoadSessionUser 认证中间件使用的 infra.UserLoader：按 ID 读取状态正常的用户
+func LoadSessionUser(ctx freedom.Context, id int64) (infra.SessionUser, bool, error) {
+	var user *vo.User
+	err := freedom.ServiceLocator().Call(func(service *domain.UserService) (e error) {
+		user, e = service.Active(id)
+		return
+	})
+	if errors.Is(err, dependency.ErrNotFound) || errors.Is(err, domain.ErrUserInactive) {
+		return infra.SessionUser{}, false, nil
+	}
+	if err != nil {
+		return infra.SessionUser{}, false, err
+	}
+	return infra.SessionUser{ID: user.ID, Username: user.Username, RealName: user.RealName, Role: user.Role}, true, nil
+}
+
+// GetLogout 退出登录
+// GET /logout
+func (c *AuthController) GetLogout() freedom.Result {
+	if user, ok := c.Session.User(); ok {
+		c.Worker.Logger().Info("用户退出", freedom.LogFields{"id": user.ID, "username": user.Username})
+	}
+	c.Session.Logout()
+	return &infra.RedirectResponse{URL: infra.LoginPath}
+}
+
+// loginFailed HTMX 请求只刷新提示区域，普通表单提交返回整个登录页
+func (c *AuthController) loginFailed(formData vo.LoginFormData, message string) freedom.Result {
+	formData.Password = ""
+	name := "auth/login.html"
+	if c.Worker.IrisContext().GetHeader("HX-Request") == "true" {
+		name = "auth/alert.html"
+	}
+	return &infra.ViewResponse{
+		Name: name,
+		Data: map[string]interface{}{
+			"Form":  formData,
+			"Error": message,
+		},
+	}
+}
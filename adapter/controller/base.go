@@ -2,12 +2,16 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"godash/domain"
+	"godash/domain/dependency"
 	"godash/domain/vo"
 	"godash/infra"
-	"math"
 	"net/url"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/8treenet/freedom"
 )
@@ -18,56 +22,116 @@ type BaseController struct {
 	Request *infra.Request
 }
 
-// PaginationResult 分页结果
-type PaginationResult struct {
-	Page       int
-	PageSize   int
-	Total      int64
-	TotalPages int
-	Start      int
-	End        int
-}
+// maxPageSize 每页条数上限
+const maxPageSize = 100
 
-// SearchHelper 搜索和分页助手
-func (c *BaseController) SearchHelper(params vo.SearchParams) (vo.SearchParams, PaginationResult) {
-	// 设置默认值
+// ListQuery 由搜索参数生成列表查询规格并补齐分页默认值，默认每页条数取自系统设置
+func (c *BaseController) ListQuery(params vo.SearchParams) vo.ListQuery {
 	if params.Page <= 0 {
 		params.Page = 1
 	}
 	if params.PageSize <= 0 {
-		params.PageSize = 10
+		params.PageSize = domain.CurrentSettings().PageSize()
+	}
+	if params.PageSize > maxPageSize {
+		params.PageSize = maxPageSize
+	}
+	mode := vo.PageModeOffset
+	if params.Mode == vo.PageModeCursor {
+		mode = vo.PageModeCursor
 	}
 
-	return params, PaginationResult{
+	return vo.ListQuery{
+		Keyword:  params.Keyword,
+		Sort:     vo.ParseSort(params.SortBy, params.Order),
 		Page:     params.Page,
 		PageSize: params.PageSize,
+		Mode:     mode,
+		Cursor:   params.Cursor,
 	}
 }
 
-// Paginate 分页处理
-func (c *BaseController) Paginate(items []interface{}, pagination PaginationResult) ([]interface{}, PaginationResult) {
-	total := int64(len(items))
-	totalPages := int(math.Ceil(float64(total) / float64(pagination.PageSize)))
-	start := (pagination.Page - 1) * pagination.PageSize
-	end := start + pagination.PageSize
+// ReadFilter 读取并校验列表筛选条件，filter 为筛选结构体指针；校验失败时提示错误并清空筛选
+func (c *BaseController) ReadFilter(filter interface{}) {
+	if err := c.Request.ReadQuery(filter, true); err != nil {
+		c.SetErrorToast("筛选条件无效: " + err.Error())
+		v := reflect.ValueOf(filter).Elem()
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
 
-	if end > len(items) {
-		end = len(items)
+// ReadExportQuery 按列表页的参数读取导出的搜索和筛选条件；筛选条件不合法时返回错误，避免导出全表
+func (c *BaseController) ReadExportQuery(filter interface{}) (vo.ListQuery, error) {
+	var params vo.SearchParams
+	if err := c.Request.ReadQuery(&params, false); err != nil {
+		params = vo.SearchParams{}
 	}
-	if start > len(items) {
-		start = len(items)
+	if err := c.Request.ReadQuery(filter, true); err != nil {
+		return vo.ListQuery{}, fmt.Errorf("筛选条件无效: %w", err)
 	}
+	return c.ListQuery(params), nil
+}
 
-	pagination.Total = total
-	pagination.TotalPages = totalPages
-	pagination.Start = start
-	pagination.End = end
+// ExportFilename 导出文件名，如 orders-20250125
+func (c *BaseController) ExportFilename(name string) string {
+	return name + "-" + time.Now().Format("20060102")
+}
 
-	if start >= len(items) {
-		return []interface{}{}, pagination
+// ReadBulkForm 读取批量操作表单。删除操作需要模块的 delete 权限，其余操作已由 Policy 按 POST 校验 write 权限；
+// 返回 false 时错误响应已经写出
+func (c *BaseController) ReadBulkForm(module string) (vo.BulkForm, bool) {
+	var form vo.BulkForm
+	if err := c.Request.ReadForm(&form, true); err != nil {
+		if len(form.IDs) == 0 {
+			c.rejectBulk("请先勾选要操作的记录")
+		} else {
+			c.rejectBulk("批量操作参数无效: " + err.Error())
+		}
+		return form, false
 	}
+	if form.Action == domain.BulkDelete && !allowed(c.Worker.IrisContext(), module+":delete") {
+		forbid(c.Worker.IrisContext(), module+":delete")
+		return form, false
+	}
+	return form, true
+}
 
-	return items[start:end], pagination
+// rejectBulk 以 400 拒绝批量操作，HTMX 按响应头显示错误提示
+func (c *BaseController) rejectBulk(message string) {
+	c.SetErrorToast(message)
+	c.Worker.IrisContext().StatusCode(400)
+	c.Worker.IrisContext().WriteString(message)
+}
+
+// bulkResponse 按批量操作结果设置一条汇总提示（有失败时附上第一条原因），
+// 返回受影响记录的带外替换片段；deleted 为 true 时片段移除对应元素
+func bulkResponse[T any](c *BaseController, view string, result vo.BulkResult[T], err error, deleted bool) freedom.Result {
+	if errors.Is(err, domain.ErrBulkAction) {
+		c.rejectBulk(err.Error())
+		return nil
+	}
+	if err != nil {
+		return c.HandleServiceError(err, "批量操作")
+	}
+
+	message := fmt.Sprintf("批量操作完成：成功 %d 条，失败 %d 条", len(result.Succeeded), len(result.Failed))
+	toastType := "success"
+	if len(result.Failed) > 0 {
+		first := result.Failed[0]
+		message += fmt.Sprintf("（#%d %s）", first.ID, first.Reason)
+		toastType = "warning"
+		if len(result.Succeeded) == 0 {
+			toastType = "error"
+		}
+	}
+	c.SetToastMessage(message, toastType)
+	return &infra.ViewResponse{
+		Name: view,
+		Data: map[string]interface{}{
+			"Items":   result.Succeeded,
+			"Deleted": deleted,
+		},
+	}
 }
 
 // SetToastMessage 设置 Toast 消息
@@ -95,6 +159,19 @@ func (c *BaseController) HandleNotFoundError(resource string) freedom.Result {
 	}
 }
 
+// HandleServiceError 处理领域服务返回的错误
+func (c *BaseController) HandleServiceError(err error, resource string) freedom.Result {
+	if errors.Is(err, dependency.ErrNotFound) {
+		return c.HandleNotFoundError(resource)
+	}
+	c.Worker.Logger().Error("领域服务错误", freedom.LogFields{"resource": resource, "error": err.Error()})
+	c.SetErrorToast("操作失败: " + err.Error())
+	return &infra.JSONResponse{
+		Code:  500,
+		Error: err,
+	}
+}
+
 // HandleValidationError 处理验证错误
 func (c *BaseController) HandleValidationError(err error, viewName string, data interface{}) freedom.Result {
 	c.SetErrorToast("表单验证失败: " + err.Error())
@@ -121,13 +198,3 @@ func (c *BaseController) FilterHelper(keyword, searchField string) bool {
 
 	return strings.Contains(lowerField, lowerKeyword)
 }
-
-// CreatePageInfo 创建页面信息
-func (c *BaseController) CreatePageInfo(pagination PaginationResult) vo.PageInfo {
-	return vo.PageInfo{
-		Page:       pagination.Page,
-		PageSize:   pagination.PageSize,
-		Total:      pagination.Total,
-		TotalPages: pagination.TotalPages,
-	}
-}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"encoding/json"
+	"godash/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestDashboardHidesModulesWithoutPermission(t *testing.T) {
+	admin := loginAdmin(t)
+	username := testutil.UniqueKey("dashboard-viewer")
+	form := userForm(username, "active")
+	form.Set("password", "secret-123")
+	if resp := admin.do("POST", "/users", form); resp.createdID() == 0 {
+		t.Fatalf("POST /users: %v", resp)
+	}
+	email := username + "@example.com"
+
+	if resp := admin.do("GET", "/dashboard", nil); !strings.Contains(resp.Body, email) {
+		t.Errorf("admin dashboard should list the new user %s", email)
+	}
+
+	// viewer 只有 products:read 和 orders:read，看不到最近用户和新用户趋势
+	viewer := login(t, username, "secret-123")
+	resp := viewer.do("GET", "/dashboard", nil)
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET /dashboard as viewer: %v", resp)
+	}
+	if strings.Contains(resp.Body, email) || strings.Contains(resp.Body, "总用户数") {
+		t.Error("viewer dashboard must not show users")
+	}
+	if !strings.Contains(resp.Body, "总订单数") {
+		t.Error("viewer dashboard should show orders")
+	}
+
+	resp = viewer.do("GET", "/dashboard/series?format=json", nil)
+	var body struct {
+		Data struct {
+			TotalNewUsers int64 `json:"total_new_users"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		t.Fatalf("GET /dashboard/series: %v, %v", resp, err)
+	}
+	if body.Data.TotalNewUsers != 0 {
+		t.Errorf("viewer series counts %d new users, want 0", body.Data.TotalNewUsers)
+	}
+}
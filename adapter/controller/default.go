@@ -25,10 +25,13 @@ type Default struct {
 
 // Get handles the GET: / route.
 func (c *Default) Get() freedom.Result {
-	// 返回主布局页面（SPA Shell）
+	// 返回主布局页面（SPA Shell），顶部栏显示当前登录用户
+	user, _ := infra.CurrentUser(c.Worker.IrisContext())
 	return &infra.ViewResponse{
 		Name: "layout.html",
-		Data: nil,
+		Data: map[string]interface{}{
+			"CurrentUser": user,
+		},
 	}
 }
 
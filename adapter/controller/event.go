@@ -0,0 +1,67 @@
+// Package controller 事件流控制器
+package controller
+
+import (
+	"errors"
+	"godash/domain/vo"
+	"godash/infra"
+	"strings"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		// 绑定事件流控制器到 /events 路由
+		initiator.BindController("/events", &EventController{})
+	})
+}
+
+// eventHeartbeat 事件流的心跳间隔，避免空闲连接被代理断开
+const eventHeartbeat = 15 * time.Second
+
+// eventModules 事件主题所属的模块，订阅需要该模块的读权限；不在其中的主题（如 notification）登录即可订阅
+var eventModules = map[string]string{
+	vo.EventOrderCreated: "orders",
+	vo.EventOrderUpdated: "orders",
+	vo.EventOrderStatus:  "orders",
+	vo.EventStockLow:     "products",
+	vo.EventUserCreated:  "users",
+}
+
+// EventController 事件流控制器
+type EventController struct {
+	BaseController
+	Events *infra.EventBus
+}
+
+// Get 以 Server-Sent Events 推送领域事件，topic 可以重复，不传时订阅全部主题；
+// 没有对应模块读权限的主题会被忽略
+// GET /events?topic=order-created&topic=order-status
+func (c *EventController) Get() freedom.Result {
+	var query vo.EventQuery
+	if err := c.Request.ReadQuery(&query, true); err != nil {
+		return &infra.JSONResponse{Code: 400, Error: errors.New(strings.Join(infra.ValidationMessages(err), "；"))}
+	}
+	requested := query.Topics
+	if len(requested) == 0 {
+		requested = vo.EventTopics
+	}
+
+	ctx := c.Worker.IrisContext()
+	var topics []string
+	for _, topic := range requested {
+		if module, ok := eventModules[topic]; !ok || allowed(ctx, module+":read") {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		return &infra.JSONResponse{Code: 403, Error: errors.New("没有订阅这些事件的权限")}
+	}
+
+	return &infra.EventStreamResponse{
+		Subscription: c.Events.Subscribe(topics),
+		Heartbeat:    eventHeartbeat,
+	}
+}
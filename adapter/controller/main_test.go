@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	_ "godash/adapter/repository"
+	"godash/config"
+	"godash/infra"
+	"godash/web/tmplfuncs"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/8treenet/freedom"
+	"github.com/8treenet/freedom/middleware"
+	"github.com/8treenet/iris/v12"
+	"github.com/8treenet/iris/v12/view"
+)
+
+// 测试启动时创建的管理员账号
+const (
+	testAdminUsername = "test-admin"
+	testAdminPassword = "test-admin-password"
+)
+
+// baseURL 测试服务器的地址
+var baseURL string
+
+// TestMain 以内存模式在随机端口启动完整的应用（中间件、控制器和模板），设置文件写到临时目录
+func TestMain(m *testing.M) {
+	os.Setenv(freedom.ProfileENV, "../../config")
+	dir, err := os.MkdirTemp("", "godash-test")
+	if err != nil {
+		panic(err)
+	}
+	conf := config.Get()
+	conf.DB.Driver = config.DriverMemory
+	conf.DB.SettingsFile = filepath.Join(dir, "settings.json")
+	conf.Auth.SessionStore = config.SessionStoreMemory
+	conf.Auth.AdminUsername, conf.Auth.AdminPassword = testAdminUsername, testAdminPassword
+	conf.App.Other["logger_level"] = "warn"
+	delete(conf.App.Other, "prometheus_listen_addr")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	// 用 localhost 访问，会话 cookie 的 Domain 不能是 IP
+	baseURL = fmt.Sprintf("http://localhost:%d", listener.Addr().(*net.TCPAddr).Port)
+	go runTestApp(listener, conf.App)
+	waitReady()
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// runTestApp 与 main 相同地安装模板和中间件，在 listener 上运行应用
+func runTestApp(listener net.Listener, conf freedom.Configuration) {
+	app := freedom.NewApplication()
+	viewEngine := view.HTML("../../web/views", ".html")
+	tmplfuncs.Register(viewEngine)
+	app.Iris().RegisterView(viewEngine)
+	app.InstallMiddleware(middleware.NewRecover())
+	redact, restore := infra.NewBodyRedactor("password")
+	app.InstallMiddleware(redact)
+	app.InstallMiddleware(middleware.NewRequestLogger("x-request-id"))
+	app.InstallMiddleware(restore)
+	app.InstallMiddleware(infra.NewAuthMiddleware(LoadSessionUser, infra.LoginPath, "/static", "/ping"))
+	app.InstallMiddleware(NewMaintenanceMiddleware(config.Get().Maintenance))
+	app.Iris().Get("/ping", func(ctx freedom.Context) {
+		ctx.WriteString("pong")
+	})
+	app.Run(iris.Listener(listener), conf)
+}
+
+func waitReady() {
+	for i := 0; i < 100; i++ {
+		if resp, err := http.Get(baseURL + "/ping"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == 200 {
+				return
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	panic("test server did not start")
+}
+
+// testClient 已登录的 HTMX 客户端，可以在多个 goroutine 中同时使用
+type testClient struct {
+	t    *testing.T
+	http *http.Client
+}
+
+// login 以 username 登录，返回带会话 cookie 的客户端
+func login(t *testing.T, username, password string) *testClient {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	client := &testClient{t: t, http: &http.Client{Jar: jar, Timeout: time.Minute}}
+	resp := client.do("POST", infra.LoginPath, url.Values{"username": {username}, "password": {password}})
+	if resp.Header.Get("HX-Redirect") != "/" {
+		t.Fatalf("login as %s failed: %d %s", username, resp.StatusCode, resp.Body)
+	}
+	return client
+}
+
+// loginAdmin 以测试管理员登录
+func loginAdmin(t *testing.T) *testClient {
+	t.Helper()
+	return login(t, testAdminUsername, testAdminPassword)
+}
+
+// testResponse 读取完的响应
+type testResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// do 发送 HTMX 请求，form 不为空时以表单提交
+func (c *testClient) do(method, path string, form url.Values) testResponse {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, baseURL+path, body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("HX-Request", "true")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.t.Errorf("%s %s: %v", method, path, err)
+		return testResponse{}
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Errorf("%s %s: %v", method, path, err)
+	}
+	return testResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: string(data)}
+}
+
+// toast 响应的提示类型和消息，没有提示时都为空
+func (r testResponse) toast() (toastType, message string) {
+	message, _ = url.QueryUnescape(r.Header.Get("X-Toast-Message"))
+	return r.Header.Get("X-Toast-Type"), message
+}
+
+// createdID 新增接口返回的 JSON 中的 ID，返回表单（失败）时为 0
+func (r testResponse) createdID() int64 {
+	var body struct {
+		Data struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") || json.Unmarshal([]byte(r.Body), &body) != nil {
+		return 0
+	}
+	return body.Data.ID
+}
+
+func (r testResponse) String() string {
+	toastType, message := r.toast()
+	return fmt.Sprintf("%d %s %s %.200s", r.StatusCode, toastType, message, r.Body)
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"errors"
+	"godash/config"
+	"godash/domain"
+	"godash/infra"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/8treenet/freedom"
+)
+
+// maintenanceMessage 维护期间返回给客户端的提示
+const maintenanceMessage = "系统维护中，请稍后再试"
+
+// NewMaintenanceMiddleware 维护模式中间件，需安装在登录认证之后。
+// 系统设置开启维护模式时，除管理员角色、allow_ips 中的客户端和 allow_paths 中的路径外，
+// 页面请求返回维护页面，HTMX 请求刷新整页以显示维护页面，JSON 客户端返回 503；都附带 Retry-After。
+// 开关每次请求从 domain.CurrentSettings() 读取，保存设置后立即生效
+func NewMaintenanceMiddleware(conf config.MaintenanceConf) freedom.Handler {
+	allowIPs := parseAllowIPs(conf.AllowIPs)
+	retryAfter := strconv.Itoa(conf.RetryAfter)
+	return func(ctx freedom.Context) {
+		settings := domain.CurrentSettings()
+		if !settings.MaintenanceMode || maintenanceAllowed(ctx, conf.AllowPaths, allowIPs) {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("Retry-After", retryAfter)
+		switch {
+		case ctx.GetHeader("HX-Request") == "true":
+			ctx.Header("HX-Refresh", "true")
+			ctx.StatusCode(503)
+		case strings.Contains(ctx.GetHeader("Accept"), "application/json") || ctx.URLParam("format") == "json":
+			infra.JSONResponse{Code: 503, Status: 503, Error: errors.New(maintenanceMessage)}.Dispatch(ctx)
+		default:
+			ctx.StatusCode(503)
+			ctx.ViewData("", map[string]interface{}{
+				"Settings":   settings,
+				"Message":    maintenanceMessage,
+				"RetryAfter": conf.RetryAfter / 60,
+			})
+			if err := ctx.View("maintenance.html"); err != nil {
+				ctx.WriteString(maintenanceMessage)
+			}
+		}
+		ctx.StopExecution()
+	}
+}
+
+// maintenanceAllowed 维护期间是否放行：管理员角色、白名单 IP 或白名单路径前缀
+func maintenanceAllowed(ctx freedom.Context, allowPaths []string, allowIPs []*net.IPNet) bool {
+	if user, ok := infra.CurrentUser(ctx); ok && user.Role == domain.AdminRole {
+		return true
+	}
+	path := ctx.Path()
+	for _, prefix := range allowPaths {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	if ip := net.ParseIP(ctx.RemoteAddr()); ip != nil {
+		for _, network := range allowIPs {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseAllowIPs 解析 IP 白名单，单个 IP 按 /32（IPv6 为 /128）处理，无效的配置记录日志后忽略
+func parseAllowIPs(list []string) []*net.IPNet {
+	result := make([]*net.IPNet, 0, len(list))
+	for _, item := range list {
+		if _, network, err := net.ParseCIDR(item); err == nil {
+			result = append(result, network)
+			continue
+		}
+		ip := net.ParseIP(item)
+		if ip == nil {
+			freedom.Logger().Errorf("维护模式 IP 白名单无效: %s", item)
+			continue
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		result = append(result, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return result
+}
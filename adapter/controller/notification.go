@@ -0,0 +1,118 @@
+// Package controller 站内通知控制器
+package controller
+
+import (
+	"godash/domain"
+	"godash/domain/vo"
+	"godash/infra"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		// 通知只属于当前登录用户，不需要模块权限
+		initiator.BindController("/notifications", &NotificationController{})
+	})
+}
+
+// notificationsChanged 已读状态变化后触发的前端事件，顶部栏据此刷新未读数
+const notificationsChanged = "notifications-changed"
+
+// NotificationController 站内通知控制器
+type NotificationController struct {
+	BaseController
+	NotificationSev *domain.NotificationService
+}
+
+// Get 当前用户的通知列表，status=unread 时只看未读
+// GET /notifications?status=unread
+func (c *NotificationController) Get() freedom.Result {
+	var params vo.SearchParams
+	if err := c.Request.ReadQuery(&params, false); err != nil {
+		params = vo.SearchParams{}
+	}
+	var filter vo.NotificationFilter
+	c.ReadFilter(&filter)
+
+	userID := c.userID()
+	query := c.ListQuery(vo.SearchParams{Page: params.Page, PageSize: params.PageSize})
+	page, err := c.NotificationSev.List(userID, query, filter)
+	if err != nil {
+		return c.HandleServiceError(err, "通知")
+	}
+	unread, err := c.NotificationSev.Unread(userID)
+	if err != nil {
+		return c.HandleServiceError(err, "通知")
+	}
+
+	return &infra.ViewResponse{
+		Name: "notifications/list.html",
+		Data: vo.NotificationListData{
+			Notifications: page.Items,
+			PageInfo:      page.PageInfo,
+			Filter:        filter,
+			Unread:        unread,
+		},
+	}
+}
+
+// GetMenu 顶部栏的通知下拉，页面加载、收到新通知或已读状态变化时刷新
+// GET /notifications/menu
+func (c *NotificationController) GetMenu() freedom.Result {
+	menu, err := c.NotificationSev.Menu(c.userID())
+	if err != nil {
+		return c.HandleServiceError(err, "通知")
+	}
+	return &infra.ViewResponse{
+		Name: "notifications/menu.html",
+		Data: menu,
+	}
+}
+
+// PostReadBy 把通知标记为已读；open=1 时打开通知对应的页面，否则返回更新后的通知行
+// POST /notifications/{id}/read
+func (c *NotificationController) PostReadBy(id int64) freedom.Result {
+	notification, err := c.NotificationSev.MarkRead(c.userID(), id)
+	if err != nil {
+		return c.HandleServiceError(err, "通知")
+	}
+	c.Worker.IrisContext().Header("HX-Trigger", notificationsChanged)
+
+	if c.Worker.IrisContext().FormValue("open") == "1" && notification.Link != "" {
+		return &infra.LocationResponse{Path: notification.Link, Target: "main"}
+	}
+	return &infra.ViewResponse{
+		Name: "notifications/row.html",
+		Data: notification,
+	}
+}
+
+// PostReadAll 把当前用户的全部未读通知标记为已读，返回刷新后的通知列表
+// POST /notifications/read-all
+func (c *NotificationController) PostReadAll() freedom.Result {
+	count, err := c.NotificationSev.MarkAllRead(c.userID())
+	if err != nil {
+		return c.HandleServiceError(err, "通知")
+	}
+	c.Worker.IrisContext().Header("HX-Trigger", notificationsChanged)
+	if count == 0 {
+		c.SetSuccessToast("没有未读通知")
+	} else {
+		c.SetSuccessToast("已将全部通知标记为已读")
+	}
+	return c.Get()
+}
+
+// userID 当前登录用户的 ID，认证中间件保证已登录
+func (c *NotificationController) userID() int64 {
+	user, _ := infra.CurrentUser(c.Worker.IrisContext())
+	return user.ID
+}
+
+// BeforeActivation 配置路由
+func (c *NotificationController) BeforeActivation(b freedom.BeforeActivation) {
+	b.Handle("GET", "/menu", "GetMenu")
+	b.Handle("POST", "/read-all", "PostReadAll")
+	b.Handle("POST", "/{id:int64}/read", "PostReadBy")
+}
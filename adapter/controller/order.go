@@ -2,11 +2,15 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"godash/domain"
+	"godash/domain/dependency"
 	"godash/domain/vo"
 	"godash/infra"
-	"math/rand"
-	"time"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/8treenet/freedom"
 )
@@ -14,60 +18,15 @@ import (
 func init() {
 	freedom.Prepare(func(initiator freedom.Initiator) {
 		// 绑定订单控制器到 /orders 路由
-		initiator.BindController("/orders", &OrderController{})
+		initiator.BindController("/orders", &OrderController{}, Policy("orders"))
 	})
 }
 
 // OrderController 订单管理控制器
 type OrderController struct {
 	BaseController
-}
-
-// mockOrders 模拟订单数据库
-var mockOrders = []vo.Order{}
-
-// init 初始化订单 mock 数据
-func init() {
-	customers := []string{"张三", "李四", "王五", "赵六", "孙七", "周八", "吴九", "郑十"}
-	statuses := []string{"pending", "paid", "shipped", "completed", "cancelled"}
-	payments := []string{"支付宝", "微信支付", "银行卡", "货到付款"}
-
-	for i := 0; i < 300; i++ {
-		// 生成订单项
-		itemCount := rand.Intn(3) + 1
-		items := make([]vo.OrderItem, itemCount)
-		totalAmount := 0.0
-
-		for j := 0; j < itemCount; j++ {
-			price := float64(rand.Intn(500)+50) + 0.99
-			quantity := rand.Intn(3) + 1
-			subtotal := price * float64(quantity)
-			totalAmount += subtotal
-
-			items[j] = vo.OrderItem{
-				ID:          int64(j + 1),
-				ProductName: fmt.Sprintf("商品-%d", j+1),
-				SKU:         fmt.Sprintf("SKU%05d", rand.Intn(1000)),
-				Quantity:    quantity,
-				Price:       price,
-				Subtotal:    subtotal,
-			}
-		}
-
-		order := vo.Order{
-			ID:            int64(i + 1),
-			OrderNo:       fmt.Sprintf("ORD%s%04d", time.Now().Format("20060102"), i+1),
-			CustomerName:  customers[i%len(customers)],
-			CustomerEmail: fmt.Sprintf("customer%d@example.com", i+1),
-			TotalAmount:   totalAmount,
-			Status:        statuses[i%len(statuses)],
-			PaymentMethod: payments[i%len(payments)],
-			Items:         items,
-			CreatedAt:     time.Now().Add(-time.Duration(i) * 24 * time.Hour),
-			UpdatedAt:     time.Now().Add(-time.Duration(i) * time.Hour),
-		}
-		mockOrders = append(mockOrders, order)
-	}
+	OrderSev  *domain.OrderService
+	RefundSev *domain.RefundService
 }
 
 // Get 获取订单列表
@@ -79,29 +38,23 @@ func (c *OrderController) Get() freedom.Result {
 		params = vo.SearchParams{}
 	}
 
-	// 使用基础控制器的搜索助手
-	params, pagination := c.SearchHelper(params)
-	filteredOrders := c.filterOrders(params)
-
-	// 转换为 interface{} 进行分页
-	orders := make([]interface{}, len(filteredOrders))
-	for i, order := range filteredOrders {
-		orders[i] = order
-	}
-
-	pagedOrders, pagination := c.Paginate(orders, pagination)
+	var filter vo.OrderFilter
+	c.ReadFilter(&filter)
 
-	// 转换回订单类型
-	result := make([]vo.Order, len(pagedOrders))
-	for i, order := range pagedOrders {
-		result[i] = order.(vo.Order)
+	// 筛选和分页由资源库完成，mode=cursor 时使用游标分页
+	query := c.ListQuery(params)
+	page, err := c.OrderSev.List(query, filter)
+	if err != nil {
+		return c.HandleServiceError(err, "订单")
 	}
 
 	data := vo.OrderListData{
-		Orders:   result,
-		PageInfo: c.CreatePageInfo(pagination),
+		Orders:   page.Items,
+		PageInfo: page.PageInfo,
 		Query:    params.Keyword,
-		Status:   params.Status,
+		Filter:   filter,
+		Payments: vo.PaymentMethods,
+		Sort:     query.Sort,
 	}
 
 	return &infra.ViewResponse{
@@ -113,27 +66,33 @@ func (c *OrderController) Get() freedom.Result {
 // GetBy 获取订单详情
 // GET /orders/{id}
 func (c *OrderController) GetBy(id int64) freedom.Result {
-	order := c.findOrderByID(id)
-	if order == nil {
-		return &infra.JSONResponse{
-			Code:  404,
-			Error: fmt.Errorf("订单不存在"),
-		}
+	order, err := c.OrderSev.Get(id)
+	if err != nil {
+		return c.loadError(err)
 	}
 
 	// 检查是否为模态框请求（通过检查请求头或查询参数）
-	hxTarget := c.Worker.IrisContext().GetHeader("HX-Target")
-	isModal := hxTarget == "order-modal-content" || hxTarget == "#order-modal-content" ||
-		c.Worker.IrisContext().URLParamExists("modal")
+	return c.detail(order, c.inModal() || c.Worker.IrisContext().URLParamExists("modal"))
+}
 
-	data := vo.OrderDetailData{
-		Order:   *order,
-		IsModal: isModal,
+// loadError 读取订单失败：订单不存在时返回 404，其余错误按领域服务错误处理
+func (c *OrderController) loadError(err error) freedom.Result {
+	if errors.Is(err, dependency.ErrNotFound) {
+		return &infra.JSONResponse{Code: 404, Error: fmt.Errorf("订单不存在")}
 	}
+	return c.HandleServiceError(err, "订单")
+}
 
+// GetRowBy 订单表格单行，列表页收到订单事件时重新加载
+// GET /orders/{id}/row
+func (c *OrderController) GetRowBy(id int64) freedom.Result {
+	order, err := c.OrderSev.Get(id)
+	if err != nil {
+		return c.loadError(err)
+	}
 	return &infra.ViewResponse{
-		Name: "orders/detail.html",
-		Data: data,
+		Name: "orders/row.html",
+		Data: order,
 	}
 }
 
@@ -150,28 +109,17 @@ func (c *OrderController) PutStatusBy(id int64) freedom.Result {
 		return &infra.JSONResponse{Error: err}
 	}
 
-	// 查找并更新订单状态
-	if !c.updateOrderStatus(id, statusData.Status) {
-		return c.HandleNotFoundError("订单")
+	// 按状态机更新订单状态
+	order, err := c.OrderSev.ChangeStatus(id, statusData.Status)
+	if err != nil {
+		return c.statusError(err)
 	}
 
 	c.SetSuccessToast("订单状态更新成功")
 
 	// 根据 return 参数决定返回订单行还是订单详情
-	order := c.findOrderByID(id)
 	if statusData.Return == "detail" {
-		// 检查是否为模态框请求
-		hxTarget := c.Worker.IrisContext().GetHeader("HX-Target")
-		isModal := hxTarget == "order-modal-content" || hxTarget == "#order-modal-content"
-
-		data := vo.OrderDetailData{
-			Order:   *order,
-			IsModal: isModal,
-		}
-		return &infra.ViewResponse{
-			Name: "orders/detail.html",
-			Data: data,
-		}
+		return c.detail(order, c.inModal())
 	}
 
 	// 默认返回订单行（用于列表页面）
@@ -184,70 +132,371 @@ func (c *OrderController) PutStatusBy(id int64) freedom.Result {
 // DeleteBy 取消订单
 // DELETE /orders/{id}
 func (c *OrderController) DeleteBy(id int64) freedom.Result {
-	if !c.updateOrderStatus(id, "cancelled") {
-		return c.HandleNotFoundError("订单")
+	order, err := c.OrderSev.ChangeStatus(id, vo.OrderCancelled)
+	if err != nil {
+		return c.statusError(err)
 	}
 
 	c.SetSuccessToast("订单已取消")
 
 	// 返回更新后的订单行
-	order := c.findOrderByID(id)
 	return &infra.ViewResponse{
 		Name: "orders/row.html",
 		Data: order,
 	}
 }
 
-// BeforeActivation 配置路由
-func (c *OrderController) BeforeActivation(b freedom.BeforeActivation) {
-	b.Handle("GET", "/{id:int64}", "GetBy")
-	b.Handle("PUT", "/{id:int64}/status", "PutStatusBy")
-	b.Handle("DELETE", "/{id:int64}", "DeleteBy")
+// detail 订单详情视图，附带状态变更和退款记录
+func (c *OrderController) detail(order *vo.Order, isModal bool) freedom.Result {
+	history, err := c.OrderSev.History(order.ID)
+	if err != nil {
+		return c.HandleServiceError(err, "订单")
+	}
+	refunds, err := c.RefundSev.List(order.ID)
+	if err != nil {
+		return c.HandleServiceError(err, "退款")
+	}
+	return &infra.ViewResponse{
+		Name: "orders/detail.html",
+		Data: vo.OrderDetailData{
+			Order:   *order,
+			History: history,
+			Refunds: refunds,
+			IsModal: isModal,
+		},
+	}
 }
 
-// filterOrders 过滤订单
-func (c *OrderController) filterOrders(params vo.SearchParams) []vo.Order {
-	filtered := []vo.Order{}
-
-	for _, order := range mockOrders {
-		// 使用基础控制器的过滤助手
-		if params.Keyword != "" {
-			if !c.FilterHelper(params.Keyword, order.OrderNo) &&
-				!c.FilterHelper(params.Keyword, order.CustomerName) &&
-				!c.FilterHelper(params.Keyword, order.CustomerEmail) {
-				continue
-			}
+// statusError 状态流转或退款被拒绝时返回 409 和错误提示，不替换页面内容
+func (c *OrderController) statusError(err error) freedom.Result {
+	for _, target := range []error{domain.ErrOrderTransition, domain.ErrOrderChanged, domain.ErrRefundNotAllowed,
+		domain.ErrRefundItems, domain.ErrRefundExceeded, domain.ErrRefundProcessed} {
+		if errors.Is(err, target) {
+			return c.reject(409, err.Error())
 		}
+	}
+	return c.HandleServiceError(err, "订单")
+}
+
+// reject 以指定状态码拒绝请求，HTMX 按响应头显示错误提示，不替换页面内容
+func (c *OrderController) reject(code int, message string) freedom.Result {
+	c.SetErrorToast(message)
+	c.Worker.IrisContext().StatusCode(code)
+	c.Worker.IrisContext().ContentType("text/html")
+	c.Worker.IrisContext().WriteString("")
+	return nil
+}
+
+// inModal 请求是否来自订单详情模态框
+func (c *OrderController) inModal() bool {
+	hxTarget := c.Worker.IrisContext().GetHeader("HX-Target")
+	return hxTarget == "order-modal-content" || hxTarget == "#order-modal-content"
+}
+
+// PostRefundsBy 申请退款，full 不为空时全额退款，否则按 item_id 和 quantity 部分退款
+// POST /orders/{id}/refunds
+func (c *OrderController) PostRefundsBy(id int64) freedom.Result {
+	var form vo.RefundFormData
+	if err := c.Request.ReadForm(&form, true); err != nil {
+		return c.reject(400, "退款申请无效: "+strings.Join(infra.ValidationMessages(err), "；"))
+	}
+	refund, err := c.RefundSev.Create(id, form)
+	if err != nil {
+		return c.statusError(err)
+	}
+	c.SetSuccessToast("已申请退款 " + domain.CurrentSettings().FormatMoney(refund.Amount) + "，等待确认")
+	return c.refundDetail(refund.OrderID)
+}
+
+// PutRefundCompleteBy 确认退款，退回库存并更新订单状态
+// PUT /orders/refunds/{id}/complete
+func (c *OrderController) PutRefundCompleteBy(id int64) freedom.Result {
+	refund, err := c.RefundSev.Complete(id)
+	if err != nil {
+		return c.statusError(err)
+	}
+	c.SetSuccessToast("退款 " + domain.CurrentSettings().FormatMoney(refund.Amount) + " 已确认")
+	return c.refundDetail(refund.OrderID)
+}
+
+// PutRefundRejectBy 拒绝退款
+// PUT /orders/refunds/{id}/reject
+func (c *OrderController) PutRefundRejectBy(id int64) freedom.Result {
+	refund, err := c.RefundSev.Reject(id)
+	if err != nil {
+		return c.statusError(err)
+	}
+	c.SetSuccessToast("退款申请已拒绝")
+	return c.refundDetail(refund.OrderID)
+}
+
+// refundDetail 退款操作后重新渲染订单详情
+func (c *OrderController) refundDetail(orderID int64) freedom.Result {
+	order, err := c.OrderSev.Get(orderID)
+	if err != nil {
+		return c.HandleServiceError(err, "订单")
+	}
+	return c.detail(order, c.inModal())
+}
+
+// GetNew 显示新增订单页面
+// GET /orders/new
+func (c *OrderController) GetNew() freedom.Result {
+	return c.form(nil, vo.OrderFormData{PaymentMethod: vo.PaymentMethods[0]}, "")
+}
+
+// GetEditBy 显示编辑订单页面，只有待处理的订单可以编辑
+// GET /orders/{id}/edit
+func (c *OrderController) GetEditBy(id int64) freedom.Result {
+	order, err := c.OrderSev.Get(id)
+	if err != nil {
+		return c.HandleServiceError(err, "订单")
+	}
+	if order.Status != vo.OrderPending {
+		c.SetErrorToast(domain.ErrOrderLocked.Error())
+		return c.detail(order, false)
+	}
+
+	form := vo.OrderFormData{PaymentMethod: order.PaymentMethod}
+	for _, item := range order.Items {
+		form.SKUs = append(form.SKUs, item.SKU)
+		form.Quantities = append(form.Quantities, item.Quantity)
+	}
+	return c.form(order, form, "")
+}
+
+// Post 创建订单，金额按商品当前价格重新计算
+// POST /orders
+func (c *OrderController) Post() freedom.Result {
+	var form vo.OrderFormData
+	if err := c.Request.ReadForm(&form, true); err != nil {
+		message := strings.Join(infra.ValidationMessages(err), "；")
+		c.SetErrorToast("表单验证失败: " + message)
+		return c.form(nil, form, message)
+	}
+
+	order, err := c.OrderSev.Create(form)
+	if err != nil {
+		return c.formError(nil, form, err)
+	}
+
+	c.NavigateTo(fmt.Sprintf("/orders/%d", order.ID))
+	c.SetSuccessToast("订单创建成功：" + order.OrderNo)
+	return c.detail(order, false)
+}
+
+// PutBy 修改待处理订单的客户、支付方式和订单项
+// PUT /orders/{id}
+func (c *OrderController) PutBy(id int64) freedom.Result {
+	order, err := c.OrderSev.Get(id)
+	if err != nil {
+		return c.HandleServiceError(err, "订单")
+	}
 
-		// 状态过滤
-		if params.Status != "" && order.Status != params.Status {
-			continue
+	var form vo.OrderFormData
+	if err := c.Request.ReadForm(&form, true); err != nil {
+		message := strings.Join(infra.ValidationMessages(err), "；")
+		c.SetErrorToast("表单验证失败: " + message)
+		return c.form(order, form, message)
+	}
+
+	updated, err := c.OrderSev.Update(id, form)
+	if errors.Is(err, domain.ErrOrderLocked) {
+		c.SetErrorToast(err.Error())
+		return c.detail(order, false)
+	}
+	if err != nil {
+		return c.formError(order, form, err)
+	}
+
+	c.NavigateTo(fmt.Sprintf("/orders/%d", id))
+	c.SetSuccessToast("订单更新成功")
+	return c.detail(updated, false)
+}
+
+// PostQuote 订单表单的实时报价：按商品当前价格重新计算每行小计和总金额，
+// add 为要加入的 SKU（已存在时数量加 1），remove 为要移除的 SKU
+// POST /orders/quote
+func (c *OrderController) PostQuote() freedom.Result {
+	ctx := c.Worker.IrisContext()
+	var form vo.OrderFormData
+	if err := c.Request.ReadForm(&form, false); err != nil {
+		return &infra.JSONResponse{Code: 400, Error: err}
+	}
+	if sku := ctx.FormValue("add"); sku != "" {
+		if i := slices.Index(form.SKUs, sku); i >= 0 && i < len(form.Quantities) {
+			form.Quantities[i]++
+		} else {
+			form.SKUs = append(form.SKUs, sku)
+			form.Quantities = append(form.Quantities, 1)
 		}
+	}
+	if i := slices.Index(form.SKUs, ctx.FormValue("remove")); i >= 0 {
+		form.SKUs = slices.Delete(form.SKUs, i, i+1)
+		if i < len(form.Quantities) {
+			form.Quantities = slices.Delete(form.Quantities, i, i+1)
+		}
+	}
 
-		filtered = append(filtered, order)
+	id, _ := strconv.ParseInt(ctx.FormValue("order_id"), 10, 64)
+	quote, err := c.OrderSev.Quote(id, form.Items())
+	if err != nil {
+		return c.HandleServiceError(err, "订单")
+	}
+	return &infra.ViewResponse{
+		Name: "orders/form_lines.html",
+		Data: vo.OrderFormPage{Quote: quote},
 	}
+}
 
-	return filtered
+// GetProducts 订单表单的商品选择器，按关键词搜索在售商品
+// GET /orders/products?keyword=耳机
+func (c *OrderController) GetProducts() freedom.Result {
+	products, err := c.OrderSev.SearchProducts(c.Worker.IrisContext().URLParamTrim("keyword"))
+	if err != nil {
+		return c.HandleServiceError(err, "商品")
+	}
+	return &infra.ViewResponse{
+		Name: "orders/product_picker.html",
+		Data: map[string]interface{}{
+			"Products": products,
+		},
+	}
 }
 
-// findOrderByID 根据 ID 查找订单
-func (c *OrderController) findOrderByID(id int64) *vo.Order {
-	for _, order := range mockOrders {
-		if order.ID == id {
-			return &order
+// form 订单新增/编辑页面，order 为 nil 时是新增；编辑时按邮箱选中原客户
+func (c *OrderController) form(order *vo.Order, form vo.OrderFormData, message string) freedom.Result {
+	users, err := c.OrderSev.Customers()
+	if err != nil {
+		return c.HandleServiceError(err, "用户")
+	}
+	var id int64
+	if order != nil {
+		id = order.ID
+		if form.CustomerID == 0 {
+			for _, user := range users {
+				if user.Email == order.CustomerEmail {
+					form.CustomerID = user.ID
+					break
+				}
+			}
 		}
 	}
-	return nil
+	quote, err := c.OrderSev.Quote(id, form.Items())
+	if err != nil {
+		return c.HandleServiceError(err, "订单")
+	}
+	return &infra.ViewResponse{
+		Name: "orders/form.html",
+		Data: vo.OrderFormPage{
+			Order:    order,
+			Form:     form,
+			Quote:    quote,
+			Users:    users,
+			Payments: vo.PaymentMethods,
+			Error:    message,
+		},
+	}
 }
 
-// updateOrderStatus 更新订单状态
-func (c *OrderController) updateOrderStatus(id int64, status string) bool {
-	for i, order := range mockOrders {
-		if order.ID == id {
-			mockOrders[i].Status = status
-			mockOrders[i].UpdatedAt = time.Now()
-			return true
+// formError 下单被拒绝（客户、支付方式、商品或库存无效，订单已被修改）时带错误信息返回表单
+func (c *OrderController) formError(order *vo.Order, form vo.OrderFormData, err error) freedom.Result {
+	for _, target := range []error{domain.ErrOrderItems, domain.ErrOrderCustomer, domain.ErrOrderPayment,
+		domain.ErrProductUnavailable, domain.ErrStockShort, domain.ErrOrderChanged, domain.ErrOrderNoTaken} {
+		if errors.Is(err, target) {
+			c.SetErrorToast(err.Error())
+			return c.form(order, form, err.Error())
 		}
 	}
-	return false
+	return c.HandleServiceError(err, "订单")
+}
+
+// GetExport 按当前搜索和筛选条件导出订单，format 为 csv 或 xlsx
+// GET /orders/export?format=xlsx&status=paid
+func (c *OrderController) GetExport() freedom.Result {
+	var filter vo.OrderFilter
+	query, err := c.ReadExportQuery(&filter)
+	if err != nil {
+		return &infra.JSONResponse{Code: 400, Error: err}
+	}
+
+	return &infra.ExportResponse{
+		Filename: c.ExportFilename("orders"),
+		Format:   c.Worker.IrisContext().URLParam("format"),
+		Header: []string{"id", "order_no", "customer_name", "customer_email", "total_amount",
+			"payment_method", "status", "item_count", "items", "created_at"},
+		Rows: func(write func(row ...interface{}) error) error {
+			return c.OrderSev.Export(query, filter, func(order vo.Order) error {
+				count := 0
+				items := make([]string, 0, len(order.Items))
+				for _, item := range order.Items {
+					count += item.Quantity
+					items = append(items, fmt.Sprintf("%s(%s) x%d", item.ProductName, item.SKU, item.Quantity))
+				}
+				return write(order.ID, order.OrderNo, order.CustomerName, order.CustomerEmail, order.TotalAmount,
+					order.PaymentMethod, order.Status, count, strings.Join(items, "; "), order.CreatedAt)
+			})
+		},
+	}
+}
+
+// GetInvoiceBy 订单发票 PDF，包含订单项小计、订单总额和已确认的退款
+// GET /orders/{id}/invoice.pdf
+func (c *OrderController) GetInvoiceBy(id int64) freedom.Result {
+	order, err := c.OrderSev.Get(id)
+	if err != nil {
+		return c.loadError(err)
+	}
+	refunds, err := c.RefundSev.List(id)
+	if err != nil {
+		return c.HandleServiceError(err, "退款")
+	}
+	return &infra.PDFResponse{
+		Filename: "invoice-" + order.OrderNo,
+		Document: invoicePDF(newPrintFormat(domain.CurrentSettings()), *order, refunds),
+	}
+}
+
+// GetPackingSlipBy 订单装箱单 PDF，只列商品和数量
+// GET /orders/{id}/packing-slip.pdf
+func (c *OrderController) GetPackingSlipBy(id int64) freedom.Result {
+	order, err := c.OrderSev.Get(id)
+	if err != nil {
+		return c.loadError(err)
+	}
+	return &infra.PDFResponse{
+		Filename: "packing-slip-" + order.OrderNo,
+		Document: packingSlipPDF(newPrintFormat(domain.CurrentSettings()), *order),
+	}
+}
+
+// PostBulk 批量更新订单状态，返回受影响行的带外替换
+// POST /orders/bulk  ids=1&ids=2&action=set_status&value=shipped
+func (c *OrderController) PostBulk() freedom.Result {
+	form, ok := c.ReadBulkForm("orders")
+	if !ok {
+		return nil
+	}
+	result, err := c.OrderSev.Bulk(form.IDs, form.Action, form.Value)
+	return bulkResponse(&c.BaseController, "orders/bulk.html", result, err, false)
+}
+
+// BeforeActivation 配置路由
+func (c *OrderController) BeforeActivation(b freedom.BeforeActivation) {
+	b.Handle("GET", "/export", "GetExport")
+	b.Handle("POST", "/bulk", "PostBulk")
+	b.Handle("GET", "/new", "GetNew")
+	b.Handle("POST", "/quote", "PostQuote")
+	b.Handle("GET", "/products", "GetProducts")
+	b.Handle("GET", "/{id:int64}", "GetBy")
+	b.Handle("GET", "/{id:int64}/edit", "GetEditBy")
+	b.Handle("GET", "/{id:int64}/row", "GetRowBy")
+	b.Handle("GET", "/{id:int64}/invoice.pdf", "GetInvoiceBy")
+	b.Handle("GET", "/{id:int64}/packing-slip.pdf", "GetPackingSlipBy")
+	b.Handle("PUT", "/{id:int64}", "PutBy")
+	b.Handle("PUT", "/{id:int64}/status", "PutStatusBy")
+	b.Handle("DELETE", "/{id:int64}", "DeleteBy")
+	b.Handle("POST", "/{id:int64}/refunds", "PostRefundsBy")
+	b.Handle("PUT", "/refunds/{id:int64}/complete", "PutRefundCompleteBy")
+	b.Handle("PUT", "/refunds/{id:int64}/reject", "PutRefundRejectBy")
 }
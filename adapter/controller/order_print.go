@@ -0,0 +1,229 @@
+// Package controller 订单发票和装箱单
+package controller
+
+import (
+	"fmt"
+	"godash/domain/vo"
+	"godash/infra"
+	"strings"
+	"time"
+)
+
+// 打印页面布局，单位为点
+const (
+	printLeft    = 50.0
+	printRight   = infra.PageWidth - 50
+	printBottom  = infra.PageHeight - 60 // 明细行不超过此位置，下方留给页脚
+	printRowStep = 20.0
+)
+
+// printFormat 打印使用的站点信息、时区、日期和金额格式，取自系统设置
+type printFormat struct {
+	settings vo.SettingsData
+}
+
+func newPrintFormat(settings vo.SettingsData) printFormat {
+	return printFormat{settings: settings}
+}
+
+// date 按设置的时区和日期格式输出日期
+func (f printFormat) date(t time.Time) string {
+	return f.settings.FormatDate(t)
+}
+
+// dateTime 日期加时分
+func (f printFormat) dateTime(t time.Time) string {
+	return f.settings.FormatDateTime(t)
+}
+
+// money 带货币符号的金额
+func (f printFormat) money(amount float64) string {
+	return f.settings.FormatMoney(amount)
+}
+
+// printColumn 明细表的一列，right 为 true 时在 x+width 处右对齐
+type printColumn struct {
+	title string
+	x     float64
+	width float64
+	right bool
+}
+
+// orderDocument 订单打印文档：每页有页眉和表头，明细行超过一页时自动分页，最后补上页码
+type orderDocument struct {
+	pdf     *infra.PDF
+	format  printFormat
+	title   string
+	order   vo.Order
+	columns []printColumn
+	y       float64
+}
+
+func newOrderDocument(format printFormat, title string, order vo.Order, columns []printColumn) *orderDocument {
+	doc := &orderDocument{pdf: infra.NewPDF(), format: format, title: title, order: order, columns: columns}
+	doc.newPage()
+	return doc
+}
+
+// newPage 新的一页：站点信息、标题、订单信息和表头，续页只保留订单号
+func (d *orderDocument) newPage() {
+	d.pdf.AddPage()
+	settings := d.format.settings
+	d.pdf.Text(printLeft, 60, 18, true, settings.SiteName)
+	d.pdf.TextRight(printRight, 60, 20, true, d.title)
+	contact := []string{}
+	if settings.ContactPhone != "" {
+		contact = append(contact, "电话 "+settings.ContactPhone)
+	}
+	if settings.ContactEmail != "" {
+		contact = append(contact, "邮箱 "+settings.ContactEmail)
+	}
+	d.pdf.Text(printLeft, 78, 9, false, settings.ContactAddress)
+	d.pdf.Text(printLeft, 90, 9, false, strings.Join(contact, "    "))
+	d.pdf.Line(printLeft, 100, printRight, 100, 1)
+
+	d.y = 122
+	if d.pdf.PageCount() == 1 {
+		d.field(printLeft, "订单号", d.order.OrderNo)
+		d.field(300, "客户", d.order.CustomerName)
+		d.y += 16
+		d.field(printLeft, "下单时间", d.format.dateTime(d.order.CreatedAt))
+		d.field(300, "邮箱", d.order.CustomerEmail)
+		d.y += 16
+		d.field(printLeft, "支付方式", d.order.PaymentMethod)
+		d.field(300, "订单状态", vo.OrderStatusText(d.order.Status))
+		d.y += 26
+	} else {
+		d.field(printLeft, "订单号", d.order.OrderNo+"（续）")
+		d.y += 22
+	}
+
+	d.pdf.Rect(printLeft, d.y-13, printRight-printLeft, 19, 0.9)
+	d.cells(true, columnTitles(d.columns)...)
+	d.y += printRowStep
+}
+
+// field 一组"标签：值"
+func (d *orderDocument) field(x float64, label, value string) {
+	d.pdf.Text(x, d.y, 10, false, label+"：")
+	d.pdf.Text(x+60, d.y, 10, true, fitValue(value))
+}
+
+// fitValue 订单信息的值最多占 180 点宽
+func fitValue(value string) string {
+	return infra.FitText(value, 10, 180, true)
+}
+
+// row 写一行明细，当前页放不下时换页
+func (d *orderDocument) row(values ...string) {
+	if d.y > printBottom {
+		d.newPage()
+	}
+	d.cells(false, values...)
+	d.pdf.Line(printLeft, d.y+6, printRight, d.y+6, 0.3)
+	d.y += printRowStep
+}
+
+// cells 按列写一行文本，超出列宽的部分截断
+func (d *orderDocument) cells(bold bool, values ...string) {
+	for i, column := range d.columns {
+		if i >= len(values) {
+			break
+		}
+		text := infra.FitText(values[i], 10, column.width, bold)
+		if column.right {
+			d.pdf.TextRight(column.x+column.width, d.y, 10, bold, text)
+		} else {
+			d.pdf.Text(column.x, d.y, 10, bold, text)
+		}
+	}
+}
+
+// total 表格下方右对齐的一行合计
+func (d *orderDocument) total(label, value string, bold bool) {
+	if d.y > printBottom {
+		d.newPage()
+	}
+	d.pdf.TextRight(printRight-110, d.y, 10, bold, label)
+	d.pdf.TextRight(printRight, d.y, 10, bold, value)
+	d.y += 18
+}
+
+// finish 为每页补上页脚和页码
+func (d *orderDocument) finish() *infra.PDF {
+	printed := "打印于 " + d.format.dateTime(time.Now())
+	for i := 0; i < d.pdf.PageCount(); i++ {
+		d.pdf.SetPage(i)
+		d.pdf.Line(printLeft, infra.PageHeight-45, printRight, infra.PageHeight-45, 0.3)
+		d.pdf.Text(printLeft, infra.PageHeight-32, 8, false, d.format.settings.SiteName+"  "+printed)
+		d.pdf.TextRight(printRight, infra.PageHeight-32, 8, false, fmt.Sprintf("第 %d / %d 页", i+1, d.pdf.PageCount()))
+	}
+	return d.pdf
+}
+
+// columnTitles 各列的标题
+func columnTitles(columns []printColumn) []string {
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.title
+	}
+	return titles
+}
+
+// invoicePDF 发票：订单项的单价、数量和小计，订单总额，已确认的退款和实收金额
+func invoicePDF(format printFormat, order vo.Order, refunds []vo.Refund) *infra.PDF {
+	doc := newOrderDocument(format, "发票", order, []printColumn{
+		{title: "#", x: printLeft + 4, width: 20},
+		{title: "商品", x: printLeft + 30, width: 170},
+		{title: "SKU", x: printLeft + 210, width: 90},
+		{title: "单价", x: printLeft + 300, width: 70, right: true},
+		{title: "数量", x: printLeft + 370, width: 45, right: true},
+		{title: "小计", x: printLeft + 415, width: 76, right: true},
+	})
+	for i, item := range order.Items {
+		doc.row(fmt.Sprint(i+1), item.ProductName, item.SKU, format.money(item.Price),
+			fmt.Sprint(item.Quantity), format.money(item.Subtotal))
+	}
+
+	doc.y += 6
+	refunded := 0.0
+	for _, refund := range refunds {
+		if refund.Status == vo.RefundCompleted {
+			refunded += refund.Amount
+		}
+	}
+	doc.total("订单总额", format.money(order.TotalAmount), true)
+	if refunded > 0 {
+		doc.total("已退款", "-"+format.money(refunded), false)
+		doc.total("实收金额", format.money(order.TotalAmount-refunded), true)
+	}
+	return doc.finish()
+}
+
+// packingSlipPDF 装箱单：只列商品和数量，不含价格，每行留出核对框，最后是签字栏
+func packingSlipPDF(format printFormat, order vo.Order) *infra.PDF {
+	doc := newOrderDocument(format, "装箱单", order, []printColumn{
+		{title: "#", x: printLeft + 4, width: 20},
+		{title: "商品", x: printLeft + 30, width: 240},
+		{title: "SKU", x: printLeft + 280, width: 110},
+		{title: "数量", x: printLeft + 390, width: 50, right: true},
+		{title: "核对", x: printLeft + 458, width: 30},
+	})
+	quantity := 0
+	for i, item := range order.Items {
+		doc.row(fmt.Sprint(i+1), item.ProductName, item.SKU, fmt.Sprint(item.Quantity))
+		doc.pdf.Rect(printLeft+462, doc.y-printRowStep-9, 10, 10, -1)
+		quantity += item.Quantity
+	}
+
+	doc.y += 6
+	doc.total("商品件数", fmt.Sprint(quantity), true)
+	if doc.y > printBottom-30 {
+		doc.newPage()
+	}
+	doc.y += 30
+	doc.pdf.Text(printLeft, doc.y, 10, false, "拣货人：____________")
+	doc.pdf.Text(printLeft+170, doc.y, 10, false, "复核人：____________")
+	doc.pdf.Text(printLeft+340, doc.y, 10, false, "日期：____________")
+	return doc.finish()
+}
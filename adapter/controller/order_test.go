@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderMissingReturnsNotFound(t *testing.T) {
+	client := loginAdmin(t)
+	for _, path := range []string{"/orders/999999999", "/orders/999999999/row", "/orders/999999999/invoice.pdf", "/orders/999999999/packing-slip.pdf"} {
+		resp := client.do("GET", path, nil)
+		var body struct {
+			Code int `json:"code"`
+		}
+		if err := json.Unmarshal([]byte(resp.Body), &body); err != nil || body.Code != 404 {
+			t.Errorf("GET %s: %v, want code 404", path, resp)
+		}
+	}
+}
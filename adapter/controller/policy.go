@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"godash/domain"
+	"godash/infra"
+
+	"github.com/8treenet/freedom"
+)
+
+// Policy 按请求方法检查模块权限：GET 需要 module:read，DELETE 需要 module:delete，其余需要 module:write
+func Policy(module string) freedom.Handler {
+	return func(ctx freedom.Context) {
+		action := "write"
+		switch ctx.Method() {
+		case "GET", "HEAD":
+			action = "read"
+		case "DELETE":
+			action = "delete"
+		}
+		authorize(ctx, module+":"+action)
+	}
+}
+
+// RequirePermission 要求指定权限，如 "products:write"
+func RequirePermission(permission string) freedom.Handler {
+	return func(ctx freedom.Context) {
+		authorize(ctx, permission)
+	}
+}
+
+// authorize 校验当前用户的角色，无权限时 HTMX 请求返回 403 和错误提示
+func authorize(ctx freedom.Context, permission string) {
+	if allowed(ctx, permission) {
+		ctx.Next()
+		return
+	}
+	forbid(ctx, permission)
+	ctx.StopExecution()
+}
+
+// allowed 当前登录用户的角色是否拥有指定权限
+func allowed(ctx freedom.Context, permission string) bool {
+	user, ok := infra.CurrentUser(ctx)
+	if !ok {
+		return false
+	}
+	result := false
+	err := freedom.ServiceLocator().Call(func(service *domain.RoleService) (e error) {
+		result, e = service.Allowed(user.Role, permission)
+		return
+	})
+	if err != nil {
+		freedom.ToWorker(ctx).Logger().Error("权限校验失败", freedom.LogFields{"permission": permission, "error": err.Error()})
+	}
+	return result
+}
+
+// forbid 写入 403 响应，HTMX 请求附带错误提示
+func forbid(ctx freedom.Context, permission string) {
+	user, _ := infra.CurrentUser(ctx)
+	worker := freedom.ToWorker(ctx)
+	worker.Logger().Warn("无权限访问", freedom.LogFields{"role": user.Role, "permission": permission, "path": ctx.Path()})
+	if ctx.GetHeader("HX-Request") == "true" {
+		base := BaseController{Worker: worker}
+		base.SetErrorToast("没有权限执行该操作（" + permission + "）")
+	}
+	ctx.StatusCode(403)
+	ctx.WriteString("forbidden: " + permission)
+}
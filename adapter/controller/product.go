@@ -2,10 +2,13 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"godash/domain"
+	"godash/domain/dependency"
 	"godash/domain/vo"
 	"godash/infra"
-	"time"
+	"strconv"
 
 	"github.com/8treenet/freedom"
 )
@@ -13,48 +16,14 @@ import (
 func init() {
 	freedom.Prepare(func(initiator freedom.Initiator) {
 		// 绑定商品控制器到 /products 路由
-		initiator.BindController("/products", &ProductController{})
+		initiator.BindController("/products", &ProductController{}, Policy("products"))
 	})
 }
 
 // ProductController 商品管理控制器
 type ProductController struct {
 	BaseController
-}
-
-// mockProducts 模拟商品数据库（使用 map 存储）
-var mockProducts = make(map[int64]vo.Product)
-var productIDCounter int64 = 30
-
-// init 初始化商品 mock 数据（30条）
-func init() {
-	names := []string{
-		"无线蓝牙耳机", "智能手环", "机械键盘", "高清摄像头", "笔记本电脑",
-		"显示器", "鼠标垫", "USB充电器", "移动硬盘", "路由器",
-		"智能音箱", "平板电脑", "游戏手柄", "麦克风", "电脑椅",
-		"台灯", "手机支架", "数据线", "蓝牙音箱", "投影仪",
-		"扫描仪", "打印机", "绘图板", "读卡器", "散热器",
-		"电源适配器", "网线", "HDMI线", "耳机架", "桌面支架",
-	}
-	categories := []string{"电子产品", "数码配件", "办公用品", "智能设备", "电脑配件"}
-	statuses := []string{"active", "inactive", "out_of_stock"}
-
-	for i := 0; i < 30; i++ {
-		product := vo.Product{
-			ID:          int64(i + 1),
-			Name:        names[i],
-			SKU:         fmt.Sprintf("SKU%05d", i+1),
-			Category:    categories[i%len(categories)],
-			Price:       float64((i+1)*50) + 99.99,
-			Stock:       (i+1)*10 - (i % 3 * 5),
-			Status:      statuses[i%len(statuses)],
-			Image:       fmt.Sprintf("https://via.placeholder.com/300x200?text=%s", names[i]),
-			Description: fmt.Sprintf("这是一款优质的%s，性能卓越，品质保证。", names[i]),
-			CreatedAt:   time.Now().Add(-time.Duration(i) * 24 * time.Hour),
-			UpdatedAt:   time.Now().Add(-time.Duration(i) * time.Hour),
-		}
-		mockProducts[product.ID] = product
-	}
+	ProductSev *domain.ProductService
 }
 
 // Get 获取商品列表
@@ -66,30 +35,25 @@ func (c *ProductController) Get() freedom.Result {
 		params = vo.SearchParams{}
 	}
 
-	// 使用基础控制器的搜索助手，设置商品默认页面大小
-	params.PageSize = 12 // 商品默认页面大小
-	params, pagination := c.SearchHelper(params)
-	filteredProducts := c.filterProducts(params)
-
-	// 转换为 interface{} 进行分页
-	products := make([]interface{}, len(filteredProducts))
-	for i, product := range filteredProducts {
-		products[i] = product
+	// 商品卡片一行 4 个，默认每页 12 个
+	if params.PageSize <= 0 {
+		params.PageSize = 12
 	}
+	var filter vo.ProductFilter
+	c.ReadFilter(&filter)
 
-	pagedProducts, pagination := c.Paginate(products, pagination)
-
-	// 转换回商品类型
-	result := make([]vo.Product, len(pagedProducts))
-	for i, product := range pagedProducts {
-		result[i] = product.(vo.Product)
+	query := c.ListQuery(params)
+	page, err := c.ProductSev.List(query, filter)
+	if err != nil {
+		return c.HandleServiceError(err, "商品")
 	}
 
 	data := vo.ProductListData{
-		Products: result,
-		PageInfo: c.CreatePageInfo(pagination),
+		Products: page.Items,
+		PageInfo: page.PageInfo,
 		Query:    params.Keyword,
-		Category: params.Status, // 这里复用 Status 字段作为分类筛选
+		Filter:   filter,
+		Sort:     query.Sort,
 	}
 
 	return &infra.ViewResponse{
@@ -110,8 +74,8 @@ func (c *ProductController) GetNew() freedom.Result {
 // GetBy 获取单个商品（用于编辑）
 // GET /products/{id}
 func (c *ProductController) GetBy(id int64) freedom.Result {
-	product := c.findProductByID(id)
-	if product == nil {
+	product, err := c.ProductSev.Get(id)
+	if err != nil {
 		c.SetErrorToast("商品不存在")
 		return c.Get()
 	}
@@ -134,22 +98,21 @@ func (c *ProductController) Post() freedom.Result {
 		})
 	}
 
-	// 检查 SKU 是否已存在
-	if c.isSKUExists(formData.SKU) {
-		c.SetErrorToast("SKU 已存在，请使用其他 SKU")
+	// 创建新商品（SKU 重复时返回表单）
+	newProduct, err := c.ProductSev.Create(formData)
+	if errors.Is(err, domain.ErrSKUExists) {
+		c.SetErrorToast(err.Error())
 		return &infra.ViewResponse{
 			Name: "products/new.html",
 			Data: map[string]interface{}{
 				"FormData": formData,
-				"Error":    "SKU 已存在，请使用其他 SKU",
+				"Error":    err.Error(),
 			},
 		}
 	}
-
-	// 创建新商品
-	newID := c.generateProductID()
-	newProduct := c.createProduct(formData, newID)
-	mockProducts[newID] = newProduct
+	if err != nil {
+		return c.HandleServiceError(err, "商品")
+	}
 
 	// 设置成功提示并导航
 	c.NavigateTo("/products")
@@ -158,7 +121,7 @@ func (c *ProductController) Post() freedom.Result {
 	return &infra.JSONResponse{
 		Object: map[string]interface{}{
 			"success": true,
-			"id":      newID,
+			"id":      newProduct.ID,
 		},
 	}
 }
@@ -168,7 +131,7 @@ func (c *ProductController) Post() freedom.Result {
 func (c *ProductController) PutBy(id int64) freedom.Result {
 	var formData vo.ProductFormData
 	if err := c.Request.ReadForm(&formData, true); err != nil {
-		product, _ := mockProducts[id]
+		product, _ := c.ProductSev.Get(id)
 		return c.HandleValidationError(err, "products/edit.html", map[string]interface{}{
 			"Product":  product,
 			"FormData": formData,
@@ -176,16 +139,13 @@ func (c *ProductController) PutBy(id int64) freedom.Result {
 	}
 
 	// 查找并更新商品
-	product, exists := mockProducts[id]
-	if !exists {
-		c.Worker.IrisContext().Header("HX-Redirect", "/products")
-		return c.HandleNotFoundError("商品")
+	if _, err := c.ProductSev.Update(id, formData); err != nil {
+		if errors.Is(err, dependency.ErrNotFound) {
+			c.Worker.IrisContext().Header("HX-Redirect", "/products")
+		}
+		return c.HandleServiceError(err, "商品")
 	}
 
-	// 更新商品信息
-	c.updateProduct(&product, formData)
-	mockProducts[id] = product
-
 	// 设置成功提示并导航
 	c.NavigateTo("/products")
 	c.SetSuccessToast("商品更新成功")
@@ -201,15 +161,14 @@ func (c *ProductController) PutBy(id int64) freedom.Result {
 // DeleteBy 删除商品
 // DELETE /products/{id}
 func (c *ProductController) DeleteBy(id int64) freedom.Result {
-	// 检查商品是否存在
-	if _, exists := mockProducts[id]; !exists {
-		c.Worker.IrisContext().StatusCode(404)
-		return c.HandleNotFoundError("商品")
+	// 删除商品（不存在时返回 404）
+	if err := c.ProductSev.Delete(id); err != nil {
+		if errors.Is(err, dependency.ErrNotFound) {
+			c.Worker.IrisContext().StatusCode(404)
+		}
+		return c.HandleServiceError(err, "商品")
 	}
 
-	// 删除商品
-	delete(mockProducts, id)
-
 	// 设置成功提示
 	c.SetSuccessToast("商品删除成功")
 	c.Worker.IrisContext().StatusCode(200)
@@ -221,101 +180,151 @@ func (c *ProductController) DeleteBy(id int64) freedom.Result {
 	return nil
 }
 
-// BeforeActivation 配置路由
-func (c *ProductController) BeforeActivation(b freedom.BeforeActivation) {
-	b.Handle("GET", "/new", "GetNew")
-	b.Handle("GET", "/{id:int64}", "GetBy")
-	b.Handle("PUT", "/{id:int64}", "PutBy")
-	b.Handle("DELETE", "/{id:int64}", "DeleteBy")
+// GetExport 按当前搜索和筛选条件导出商品，format 为 csv 或 xlsx；列名与商品表单字段一致
+// GET /products/export?format=csv&category=电子产品
+func (c *ProductController) GetExport() freedom.Result {
+	var filter vo.ProductFilter
+	query, err := c.ReadExportQuery(&filter)
+	if err != nil {
+		return &infra.JSONResponse{Code: 400, Error: err}
+	}
+
+	return &infra.ExportResponse{
+		Filename: c.ExportFilename("products"),
+		Format:   c.Worker.IrisContext().URLParam("format"),
+		Header:   []string{"id", "name", "sku", "category", "price", "stock", "status", "description", "created_at"},
+		Rows: func(write func(row ...interface{}) error) error {
+			return c.ProductSev.Export(query, filter, func(product vo.Product) error {
+				return write(product.ID, product.Name, product.SKU, product.Category, product.Price,
+					product.Stock, product.Status, product.Description, product.CreatedAt)
+			})
+		},
+	}
 }
 
-// filterProducts 过滤商品
-func (c *ProductController) filterProducts(params vo.SearchParams) []vo.Product {
-	filtered := []vo.Product{}
-
-	for _, product := range mockProducts {
-		// 使用基础控制器的过滤助手
-		if params.Keyword != "" {
-			if !c.FilterHelper(params.Keyword, product.Name) &&
-				!c.FilterHelper(params.Keyword, product.SKU) &&
-				!c.FilterHelper(params.Keyword, product.Description) {
-				continue
-			}
-		}
+// 商品导入限制
+const (
+	importMaxBytes = 5 << 20 // 上传文件最大 5MB
+	importMaxRows  = 5000    // 一次最多导入的行数
+)
 
-		// 分类过滤（复用 Status 字段）
-		if params.Status != "" && product.Category != params.Status {
-			continue
-		}
+// importColumns 导入 CSV 必须包含的列，与导出的列名一致，其余列忽略
+var importColumns = []string{"name", "sku", "category", "price", "stock", "status"}
 
-		filtered = append(filtered, product)
+// GetImport 商品导入页面
+// GET /products/import
+func (c *ProductController) GetImport() freedom.Result {
+	return &infra.ViewResponse{
+		Name: "products/import.html",
 	}
-
-	// 按 ID 降序排序（最新的在前面）
-	c.sortProductsByID(filtered)
-
-	return filtered
 }
 
-// findProductByID 根据 ID 查找商品
-func (c *ProductController) findProductByID(id int64) *vo.Product {
-	if product, ok := mockProducts[id]; ok {
-		return &product
+// PostImport 上传 CSV 批量导入商品，dry_run 不为空时只校验不写入
+// POST /products/import
+func (c *ProductController) PostImport() freedom.Result {
+	ctx := c.Worker.IrisContext()
+	ctx.SetMaxRequestBodySize(importMaxBytes)
+	file, _, err := ctx.FormFile("file")
+	if err != nil {
+		return c.importError("请选择不超过 5MB 的 CSV 文件")
 	}
-	return nil
-}
+	defer file.Close()
 
-// isSKUExists 检查 SKU 是否已存在
-func (c *ProductController) isSKUExists(sku string) bool {
-	for _, product := range mockProducts {
-		if product.SKU == sku {
-			return true
+	var rows []vo.ProductImportRow
+	err = infra.ReadCSV(file, importColumns, func(line int, record map[string]string) error {
+		if len(rows) >= importMaxRows {
+			return fmt.Errorf("一次最多导入 %d 行", importMaxRows)
 		}
+		rows = append(rows, c.importRow(line, record))
+		return nil
+	})
+	if err != nil {
+		return c.importError("CSV 解析失败: " + err.Error())
 	}
-	return false
-}
 
-// generateProductID 生成新的商品ID
-func (c *ProductController) generateProductID() int64 {
-	productIDCounter++
-	return productIDCounter
+	report, err := c.ProductSev.Import(rows, ctx.FormValue("dry_run") != "")
+	if errors.Is(err, domain.ErrSKUExists) {
+		return c.importError("导入失败: " + err.Error())
+	}
+	if err != nil {
+		return c.HandleServiceError(err, "商品")
+	}
+
+	switch {
+	case report.Failed > 0:
+		c.SetErrorToast(fmt.Sprintf("%d 行校验失败，未导入任何商品", report.Failed))
+	case report.DryRun:
+		c.SetSuccessToast(fmt.Sprintf("校验通过，共 %d 行可以导入", len(report.Rows)))
+	default:
+		c.SetSuccessToast(fmt.Sprintf("成功导入 %d 个商品", report.Imported))
+	}
+	return &infra.ViewResponse{
+		Name: "products/import_report.html",
+		Data: map[string]interface{}{
+			"Report": report,
+		},
+	}
 }
 
-// createProduct 创建商品对象
-func (c *ProductController) createProduct(formData vo.ProductFormData, id int64) vo.Product {
-	return vo.Product{
-		ID:          id,
-		Name:        formData.Name,
-		SKU:         formData.SKU,
-		Category:    formData.Category,
-		Price:       formData.Price,
-		Stock:       formData.Stock,
-		Status:      formData.Status,
-		Image:       fmt.Sprintf("https://via.placeholder.com/300x200?text=%s", formData.Name),
-		Description: formData.Description,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+// importRow 把 CSV 的一行转换为商品表单并按 validate 标签校验
+func (c *ProductController) importRow(line int, record map[string]string) vo.ProductImportRow {
+	row := vo.ProductImportRow{
+		Line: line,
+		Data: vo.ProductFormData{
+			Name:        record["name"],
+			SKU:         record["sku"],
+			Category:    record["category"],
+			Status:      record["status"],
+			Description: record["description"],
+		},
+	}
+	var err error
+	if value := record["price"]; value != "" {
+		if row.Data.Price, err = strconv.ParseFloat(value, 64); err != nil {
+			row.Errors = append(row.Errors, "price 不是有效的数字: "+value)
+		}
 	}
+	if value := record["stock"]; value != "" {
+		if row.Data.Stock, err = strconv.Atoi(value); err != nil {
+			row.Errors = append(row.Errors, "stock 不是有效的整数: "+value)
+		}
+	}
+	if err := c.Request.Validate(row.Data); err != nil {
+		row.Errors = append(row.Errors, infra.ValidationMessages(err)...)
+	}
+	return row
 }
 
-// updateProduct 更新商品信息
-func (c *ProductController) updateProduct(product *vo.Product, formData vo.ProductFormData) {
-	product.Name = formData.Name
-	product.Category = formData.Category
-	product.Price = formData.Price
-	product.Stock = formData.Stock
-	product.Status = formData.Status
-	product.Description = formData.Description
-	product.UpdatedAt = time.Now()
+// importError 文件级错误，报告区域只显示错误信息
+func (c *ProductController) importError(message string) freedom.Result {
+	c.SetErrorToast(message)
+	return &infra.ViewResponse{
+		Name: "products/import_report.html",
+		Data: map[string]interface{}{
+			"Error": message,
+		},
+	}
 }
 
-// sortProductsByID 按 ID 降序排序商品
-func (c *ProductController) sortProductsByID(products []vo.Product) {
-	for i := 0; i < len(products)-1; i++ {
-		for j := i + 1; j < len(products); j++ {
-			if products[i].ID < products[j].ID {
-				products[i], products[j] = products[j], products[i]
-			}
-		}
+// PostBulk 批量上架、下架或删除商品，返回受影响卡片的带外替换
+// POST /products/bulk  ids=1&ids=2&action=deactivate
+func (c *ProductController) PostBulk() freedom.Result {
+	form, ok := c.ReadBulkForm("products")
+	if !ok {
+		return nil
 	}
+	result, err := c.ProductSev.Bulk(form.IDs, form.Action)
+	return bulkResponse(&c.BaseController, "products/bulk.html", result, err, form.Action == domain.BulkDelete)
+}
+
+// BeforeActivation 配置路由
+func (c *ProductController) BeforeActivation(b freedom.BeforeActivation) {
+	b.Handle("GET", "/export", "GetExport")
+	b.Handle("POST", "/bulk", "PostBulk")
+	b.Handle("GET", "/import", "GetImport")
+	b.Handle("POST", "/import", "PostImport")
+	b.Handle("GET", "/new", "GetNew")
+	b.Handle("GET", "/{id:int64}", "GetBy")
+	b.Handle("PUT", "/{id:int64}", "PutBy")
+	b.Handle("DELETE", "/{id:int64}", "DeleteBy")
 }
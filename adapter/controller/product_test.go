@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"godash/adapter/repository"
+	"godash/domain/dependency"
+	"godash/internal/testutil"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// productForm 新增或编辑商品的表单
+func productForm(sku, name string, stock int) url.Values {
+	return url.Values{
+		"name":     {name},
+		"sku":      {sku},
+		"category": {"电子产品"},
+		"price":    {"19.99"},
+		"stock":    {strconv.Itoa(stock)},
+		"status":   {"active"},
+	}
+}
+
+func TestProductHandlersConcurrently(t *testing.T) {
+	client := loginAdmin(t)
+	prefix := testutil.UniqueKey("HANDLER")
+
+	// 40 个请求同时新增 8 个 SKU，每个 SKU 只能创建一次
+	var mu sync.Mutex
+	created := map[string]int64{}
+	testutil.Parallel(40, func(i int) {
+		sku := fmt.Sprintf("%s-%d", prefix, i%8)
+		resp := client.do("POST", "/products", productForm(sku, "并发商品", 5))
+		checkNoServerError(t, "POST", "/products", resp)
+		id := resp.createdID()
+		if id == 0 {
+			if _, message := resp.toast(); message == "" {
+				t.Errorf("POST /products %s: %v", sku, resp)
+			}
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := created[sku]; ok {
+			t.Errorf("product %s created twice", sku)
+		}
+		created[sku] = id
+	})
+	if len(created) != 8 {
+		t.Fatalf("created %d products, want 8", len(created))
+	}
+
+	// 同时编辑、删除（SKU 末位为偶数的商品）和查看这些商品
+	skus := make([]string, 0, len(created))
+	for sku := range created {
+		skus = append(skus, sku)
+	}
+	deleted := func(sku string) bool {
+		return (sku[len(sku)-1]-'0')%2 == 0
+	}
+	testutil.Parallel(72, func(i int) {
+		sku := skus[i%len(skus)]
+		path := fmt.Sprintf("/products/%d", created[sku])
+		switch i / len(skus) % 3 {
+		case 0:
+			checkNoServerError(t, "PUT", path, client.do("PUT", path, productForm(sku, "已修改", 7)))
+		case 1:
+			if deleted(sku) {
+				checkNoServerError(t, "DELETE", path, client.do("DELETE", path, nil))
+			}
+		default:
+			checkNoServerError(t, "GET", path, client.do("GET", path, nil))
+			checkNoServerError(t, "GET", "/products", client.do("GET", "/products?keyword="+prefix, nil))
+		}
+	})
+
+	repo := &repository.ProductMemoryRepository{}
+	for sku, id := range created {
+		product, err := repo.Get(id)
+		if deleted(sku) {
+			if !errors.Is(err, dependency.ErrNotFound) {
+				t.Errorf("product %s should be deleted, got %v", sku, err)
+			}
+			continue
+		}
+		if err != nil || product.Name != "已修改" || product.Stock != 7 {
+			t.Errorf("product %s: %+v, %v; want the edited name and stock", sku, product, err)
+		}
+	}
+}
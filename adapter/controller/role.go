@@ -0,0 +1,197 @@
+// Package controller 角色与权限控制器
+package controller
+
+import (
+	"errors"
+	"godash/domain"
+	"godash/domain/vo"
+	"godash/infra"
+	"strconv"
+	"strings"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		// 角色管理和权限矩阵都需要 roles 模块权限
+		initiator.BindController("/users/roles", &RoleController{}, Policy("roles"))
+		initiator.BindController("/users/permissions", &PermissionController{}, Policy("roles"))
+	})
+}
+
+// RoleController 角色管理控制器
+type RoleController struct {
+	BaseController
+	RoleSev *domain.RoleService
+}
+
+// Get 角色列表
+// GET /users/roles
+func (c *RoleController) Get() freedom.Result {
+	roles, err := c.RoleSev.List()
+	if err != nil {
+		return c.HandleServiceError(err, "角色")
+	}
+	return &infra.ViewResponse{
+		Name: "users/roles.html",
+		Data: vo.RoleListData{Roles: roles},
+	}
+}
+
+// GetNew 新增角色页面
+// GET /users/roles/new
+func (c *RoleController) GetNew() freedom.Result {
+	return c.formView(&vo.Role{}, false)
+}
+
+// GetBy 编辑角色页面
+// GET /users/roles/{id}
+func (c *RoleController) GetBy(id int64) freedom.Result {
+	role, err := c.RoleSev.Get(id)
+	if err != nil {
+		c.SetErrorToast("角色不存在")
+		return c.Get()
+	}
+	return c.formView(role, true)
+}
+
+// Post 创建角色
+// POST /users/roles
+func (c *RoleController) Post() freedom.Result {
+	var formData vo.RoleFormData
+	if err := c.Request.ReadForm(&formData, true); err != nil {
+		c.SetErrorToast("表单验证失败: " + err.Error())
+		return c.formView(formRole(formData), false)
+	}
+
+	if _, err := c.RoleSev.Create(formData); err != nil {
+		if errors.Is(err, domain.ErrRoleCodeExists) {
+			c.SetErrorToast(err.Error())
+			return c.formView(formRole(formData), false)
+		}
+		return c.HandleServiceError(err, "角色")
+	}
+
+	c.NavigateTo("/users/roles")
+	c.SetSuccessToast("角色创建成功")
+	return c.Get()
+}
+
+// PutBy 更新角色
+// PUT /users/roles/{id}
+func (c *RoleController) PutBy(id int64) freedom.Result {
+	var formData vo.RoleFormData
+	if err := c.Request.ReadForm(&formData, true); err != nil {
+		formData.ID = id
+		c.SetErrorToast("表单验证失败: " + err.Error())
+		return c.formView(formRole(formData), true)
+	}
+
+	if _, err := c.RoleSev.Update(id, formData); err != nil {
+		return c.HandleServiceError(err, "角色")
+	}
+
+	c.NavigateTo("/users/roles")
+	c.SetSuccessToast("角色更新成功")
+	return c.Get()
+}
+
+// DeleteBy 删除角色
+// DELETE /users/roles/{id}
+func (c *RoleController) DeleteBy(id int64) freedom.Result {
+	err := c.RoleSev.Delete(id)
+	if errors.Is(err, domain.ErrSystemRole) || errors.Is(err, domain.ErrRoleInUse) {
+		// 409 不会触发 HTMX 替换，行保持不变，由 responseError 显示 Toast
+		c.SetErrorToast(err.Error())
+		c.Worker.IrisContext().StatusCode(409)
+		c.Worker.IrisContext().ContentType("text/html")
+		c.Worker.IrisContext().WriteString("")
+		return nil
+	}
+	if err != nil {
+		return c.HandleServiceError(err, "角色")
+	}
+
+	c.SetSuccessToast("角色删除成功")
+	c.Worker.IrisContext().ContentType("text/html")
+	c.Worker.IrisContext().WriteString("")
+	return nil
+}
+
+// formView 角色表单页面
+func (c *RoleController) formView(role *vo.Role, isEdit bool) freedom.Result {
+	return &infra.ViewResponse{
+		Name: "users/role_form.html",
+		Data: map[string]interface{}{
+			"Role":    role,
+			"IsEdit":  isEdit,
+			"Modules": c.RoleSev.Modules(),
+			"Actions": c.RoleSev.Actions(),
+		},
+	}
+}
+
+// formRole 用提交的表单回填页面
+func formRole(formData vo.RoleFormData) *vo.Role {
+	return &vo.Role{
+		ID:          formData.ID,
+		Code:        formData.Code,
+		Name:        formData.Name,
+		Description: formData.Description,
+		Permissions: formData.Permissions,
+	}
+}
+
+// BeforeActivation 配置路由
+func (c *RoleController) BeforeActivation(b freedom.BeforeActivation) {
+	b.Handle("GET", "/new", "GetNew")
+	b.Handle("GET", "/{id:int64}", "GetBy")
+	b.Handle("PUT", "/{id:int64}", "PutBy")
+	b.Handle("DELETE", "/{id:int64}", "DeleteBy")
+}
+
+// PermissionController 权限矩阵控制器
+type PermissionController struct {
+	BaseController
+	RoleSev *domain.RoleService
+}
+
+// Get 权限矩阵页面
+// GET /users/permissions
+func (c *PermissionController) Get() freedom.Result {
+	roles, err := c.RoleSev.List()
+	if err != nil {
+		return c.HandleServiceError(err, "角色")
+	}
+	return &infra.ViewResponse{
+		Name: "users/permissions.html",
+		Data: vo.PermissionMatrixData{
+			Roles:   roles,
+			Modules: c.RoleSev.Modules(),
+			Actions: c.RoleSev.Actions(),
+		},
+	}
+}
+
+// Put 保存权限矩阵，表单字段为 perm_{角色ID}，值为权限标识
+// PUT /users/permissions
+func (c *PermissionController) Put() freedom.Result {
+	matrix := map[int64][]string{}
+	for key, values := range c.Worker.IrisContext().FormValues() {
+		if !strings.HasPrefix(key, "perm_") {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimPrefix(key, "perm_"), 10, 64)
+		if err != nil {
+			continue
+		}
+		matrix[id] = values
+	}
+
+	if err := c.RoleSev.SavePermissions(matrix); err != nil {
+		return c.HandleServiceError(err, "角色")
+	}
+	c.SetSuccessToast("权限已保存，立即生效")
+	return c.Get()
+}
@@ -2,9 +2,13 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"godash/domain"
+	"godash/domain/dependency"
 	"godash/domain/vo"
 	"godash/infra"
-	"net/url"
+	"strconv"
 
 	"github.com/8treenet/freedom"
 )
@@ -12,56 +16,124 @@ import (
 func init() {
 	freedom.Prepare(func(initiator freedom.Initiator) {
 		// 绑定设置控制器到 /settings 路由
-		initiator.BindController("/settings", &SettingController{})
+		initiator.BindController("/settings", &SettingController{}, Policy("settings"))
 	})
 }
 
+// 设置变化后通知页面刷新的 HX-Trigger 事件：保存后刷新历史版本，回滚后还要刷新设置表单
+const (
+	settingsSaved      = "settings-saved"
+	settingsRolledBack = "settings-rolled-back"
+)
+
 // SettingController 系统设置控制器
 type SettingController struct {
-	Worker  freedom.Worker
-	Request *infra.Request
-}
-
-// mockSettings 模拟系统设置数据（实际项目中应该从数据库读取）
-var mockSettings = vo.SettingsData{
-	SiteName:        "HTMX 管理后台",
-	SiteDescription: "基于 HTMX、Bulma 和 Alpine.js 的现代化管理后台系统",
-	ContactEmail:    "admin@example.com",
-	ContactPhone:    "400-123-4567",
-	Currency:        "CNY",
-	Timezone:        "Asia/Shanghai",
-	Language:        "zh-CN",
+	BaseController
+	SettingSev *domain.SettingService
 }
 
 // Get 获取系统设置
 // GET /settings
 func (c *SettingController) Get() freedom.Result {
+	page, err := c.SettingSev.Page()
+	if err != nil {
+		return c.HandleServiceError(err, "系统设置")
+	}
 	return &infra.ViewResponse{
 		Name: "settings/form.html",
-		Data: mockSettings,
+		Data: page,
 	}
 }
 
-// Post 保存系统设置
+// Post 保存系统设置为新版本，保存后各模块立即使用新设置
 // POST /settings
 func (c *SettingController) Post() freedom.Result {
+	// 没有提交版本号的旧页面按冲突处理，重新加载最新设置
+	baseVersion, err := strconv.Atoi(c.Worker.IrisContext().FormValue("base_version"))
+	if err != nil {
+		baseVersion = -1
+	}
 	var formData vo.SettingsData
 	if err := c.Request.ReadForm(&formData, true); err != nil {
-		c.Worker.IrisContext().Header("X-Toast-Message", url.QueryEscape("表单验证失败: "+err.Error()))
-		c.Worker.IrisContext().Header("X-Toast-Type", "error")
+		page, pageErr := c.SettingSev.Page()
+		if pageErr != nil {
+			return c.HandleServiceError(pageErr, "系统设置")
+		}
+		page.SettingsData, page.Version = formData, baseVersion
+		return c.HandleValidationError(err, "settings/form.html", page)
+	}
+
+	if _, err := c.SettingSev.Save(formData, baseVersion); err != nil {
+		if !errors.Is(err, dependency.ErrConflict) {
+			return c.HandleServiceError(err, "系统设置")
+		}
+		c.SetErrorToast("设置已被其他人修改，已加载最新设置，请确认后重新保存")
 		return c.Get()
 	}
 
-	// 更新设置
-	mockSettings = formData
+	c.Worker.IrisContext().Header("HX-Trigger", settingsSaved)
+	c.SetSuccessToast("设置保存成功")
+	return c.Get()
+}
 
-	// 设置成功提示
-	c.Worker.IrisContext().Header("X-Toast-Message", url.QueryEscape("设置保存成功"))
-	c.Worker.IrisContext().Header("X-Toast-Type", "success")
+// GetHistory 设置历史版本列表，最新的在前
+// GET /settings/history?page=2
+func (c *SettingController) GetHistory() freedom.Result {
+	var params vo.SearchParams
+	if err := c.Request.ReadQuery(&params, false); err != nil {
+		params = vo.SearchParams{}
+	}
+	history, err := c.SettingSev.History(c.ListQuery(vo.SearchParams{Page: params.Page, PageSize: params.PageSize}))
+	if err != nil {
+		return c.HandleServiceError(err, "设置版本")
+	}
+	return &infra.ViewResponse{
+		Name: "settings/history.html",
+		Data: history,
+	}
+}
 
-	// 返回更新后的表单
+// GetDiff 比较两个设置版本的字段差异，版本 0 为默认设置
+// GET /settings/diff?from=1&to=3
+func (c *SettingController) GetDiff() freedom.Result {
+	var query vo.SettingsDiffQuery
+	if err := c.Request.ReadQuery(&query, true); err != nil {
+		c.SetErrorToast("版本号无效: " + err.Error())
+		return &infra.ViewResponse{Name: "settings/diff.html"}
+	}
+	diff, err := c.SettingSev.Diff(query.From, query.To)
+	if errors.Is(err, dependency.ErrNotFound) {
+		c.SetErrorToast("设置版本不存在")
+		return &infra.ViewResponse{Name: "settings/diff.html"}
+	}
+	if err != nil {
+		return c.HandleServiceError(err, "设置版本")
+	}
 	return &infra.ViewResponse{
-		Name: "settings/form.html",
-		Data: mockSettings,
+		Name: "settings/diff.html",
+		Data: diff,
 	}
 }
+
+// PostRollbackBy 把指定版本恢复为一个新版本，页面按 HX-Trigger 刷新设置表单和历史版本
+// POST /settings/versions/{version}/rollback
+func (c *SettingController) PostRollbackBy(version int) freedom.Result {
+	restored, err := c.SettingSev.Rollback(version)
+	if errors.Is(err, dependency.ErrConflict) {
+		c.SetErrorToast("设置已被其他人修改，请刷新后重试")
+		return nil
+	}
+	if err != nil {
+		return c.HandleServiceError(err, "设置版本")
+	}
+	c.Worker.IrisContext().Header("HX-Trigger", settingsSaved+", "+settingsRolledBack)
+	c.SetSuccessToast(fmt.Sprintf("已将 v%d 的设置恢复为 v%d", version, restored.Version))
+	return nil
+}
+
+// BeforeActivation 配置路由
+func (c *SettingController) BeforeActivation(b freedom.BeforeActivation) {
+	b.Handle("GET", "/history", "GetHistory")
+	b.Handle("GET", "/diff", "GetDiff")
+	b.Handle("POST", "/versions/{version:int}/rollback", "PostRollbackBy")
+}
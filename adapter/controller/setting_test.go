@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// baseVersionPattern 设置表单中加载时的版本号
+var baseVersionPattern = regexp.MustCompile(`name="base_version" value="(-?\d+)"`)
+
+// settingsForm 保存设置的表单，baseVersion 为编辑时加载的版本
+func settingsForm(siteName, timezone, baseVersion string) url.Values {
+	return url.Values{
+		"site_name":           {siteName},
+		"contact_email":       {"admin@example.com"},
+		"currency":            {"CNY"},
+		"timezone":            {timezone},
+		"language":            {"zh-CN"},
+		"date_format":         {"YYYY-MM-DD"},
+		"items_per_page":      {"10"},
+		"low_stock_threshold": {"10"},
+		"base_version":        {baseVersion},
+	}
+}
+
+// loadedVersion 设置页面中的 base_version
+func loadedVersion(t *testing.T, resp testResponse) string {
+	t.Helper()
+	match := baseVersionPattern.FindStringSubmatch(resp.Body)
+	if match == nil {
+		t.Fatalf("settings form without base_version: %v", resp)
+	}
+	return match[1]
+}
+
+func TestSettingSaveRejectsStaleVersion(t *testing.T) {
+	client := loginAdmin(t)
+	base := loadedVersion(t, client.do("GET", "/settings", nil))
+
+	resp := client.do("POST", "/settings", settingsForm("HTMX 管理后台", "Mars/Olympus", base))
+	if toastType, message := resp.toast(); toastType != "error" || !strings.Contains(message, "timezone") {
+		t.Errorf("save with an unknown timezone: %v, want a validation error", resp)
+	}
+	if got := loadedVersion(t, resp); got != base {
+		t.Errorf("form after a validation error has base_version %s, want the posted %s", got, base)
+	}
+
+	resp = client.do("POST", "/settings", settingsForm("HTMX 管理后台", "Asia/Shanghai", base))
+	if toastType, _ := resp.toast(); toastType != "success" {
+		t.Fatalf("save: %v", resp)
+	}
+	saved := loadedVersion(t, resp)
+
+	// 另一个编辑者仍在旧版本上修改，保存被拒绝并加载最新设置
+	resp = client.do("POST", "/settings", settingsForm("过期的修改", "Asia/Shanghai", base))
+	if _, message := resp.toast(); !strings.Contains(message, "已被其他人修改") {
+		t.Errorf("save on a stale version: %v, want a conflict", resp)
+	}
+	if got := loadedVersion(t, resp); got != saved || strings.Contains(resp.Body, "过期的修改") {
+		t.Errorf("form after a conflict has base_version %s, want the latest %s", got, saved)
+	}
+}
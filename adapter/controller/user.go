@@ -2,10 +2,11 @@
 package controller
 
 import (
-	"fmt"
+	"errors"
+	"godash/domain"
+	"godash/domain/dependency"
 	"godash/domain/vo"
 	"godash/infra"
-	"time"
 
 	"github.com/8treenet/freedom"
 )
@@ -13,42 +14,15 @@ import (
 func init() {
 	freedom.Prepare(func(initiator freedom.Initiator) {
 		// 绑定用户控制器到 /users 路由
-		initiator.BindController("/users", &UserController{})
+		initiator.BindController("/users", &UserController{}, Policy("users"))
 	})
 }
 
 // UserController 用户管理控制器
 type UserController struct {
 	BaseController
-}
-
-// mockUsers 模拟用户数据库（使用 map 存储）
-var mockUsers = make(map[int64]vo.User)
-var userIDCounter int64 = 30
-
-// init 初始化 mock 数据（30条）
-func init() {
-	names := []string{"张伟", "王芳", "李娜", "刘洋", "陈静", "杨军", "赵敏", "孙涛", "周杰", "吴彦祖",
-		"郑爽", "黄晓明", "林志玲", "范冰冰", "李冰冰", "章子怡", "周润发", "刘德华", "张国荣", "梁朝伟",
-		"赵本山", "小沈阳", "宋丹丹", "蔡明", "潘长江", "郭德纲", "于谦", "岳云鹏", "孙越", "张云雷"}
-	roles := []string{"admin", "editor", "viewer"}
-	statuses := []string{"active", "inactive"}
-
-	for i := 0; i < 30; i++ {
-		user := vo.User{
-			ID:        int64(i + 1),
-			Username:  fmt.Sprintf("user%d", i+1),
-			Email:     fmt.Sprintf("user%d@example.com", i+1),
-			RealName:  names[i],
-			Phone:     fmt.Sprintf("138%08d", i+1),
-			Role:      roles[i%len(roles)],
-			Status:    statuses[i%len(statuses)],
-			Avatar:    "/static/images/zxg.jpg",
-			CreatedAt: time.Now().Add(-time.Duration(i) * 24 * time.Hour),
-			UpdatedAt: time.Now().Add(-time.Duration(i) * time.Hour),
-		}
-		mockUsers[user.ID] = user
-	}
+	UserSev *domain.UserService
+	RoleSev *domain.RoleService
 }
 
 // Get 获取用户列表
@@ -60,29 +34,27 @@ func (c *UserController) Get() freedom.Result {
 		params = vo.SearchParams{}
 	}
 
-	// 使用基础控制器的搜索助手
-	params, pagination := c.SearchHelper(params)
-	filteredUsers := c.filterUsers(params)
+	var filter vo.UserFilter
+	c.ReadFilter(&filter)
 
-	// 转换为 interface{} 进行分页
-	users := make([]interface{}, len(filteredUsers))
-	for i, user := range filteredUsers {
-		users[i] = user
+	// 筛选和分页由资源库完成
+	query := c.ListQuery(params)
+	page, err := c.UserSev.List(query, filter)
+	if err != nil {
+		return c.HandleServiceError(err, "用户")
 	}
-
-	pagedUsers, pagination := c.Paginate(users, pagination)
-
-	// 转换回用户类型
-	result := make([]vo.User, len(pagedUsers))
-	for i, user := range pagedUsers {
-		result[i] = user.(vo.User)
+	roles, err := c.RoleSev.List()
+	if err != nil {
+		return c.HandleServiceError(err, "角色")
 	}
 
 	data := vo.UserListData{
-		Users:    result,
-		PageInfo: c.CreatePageInfo(pagination),
+		Users:    page.Items,
+		PageInfo: page.PageInfo,
 		Query:    params.Keyword,
-		Status:   params.Status,
+		Filter:   filter,
+		Roles:    roles,
+		Sort:     query.Sort,
 	}
 
 	return &infra.ViewResponse{
@@ -103,8 +75,8 @@ func (c *UserController) GetNew() freedom.Result {
 // GetBy 获取单个用户（用于编辑）
 // GET /users/{id}
 func (c *UserController) GetBy(id int64) freedom.Result {
-	user := c.findUserByID(id)
-	if user == nil {
+	user, err := c.UserSev.Get(id)
+	if err != nil {
 		c.SetErrorToast("用户不存在")
 		return c.Get()
 	}
@@ -125,19 +97,19 @@ func (c *UserController) Post() freedom.Result {
 		return c.HandleValidationError(err, "users/new.html", formData)
 	}
 
-	// 检查用户名是否已存在
-	if c.isUsernameExists(formData.Username) {
-		c.SetErrorToast("用户名已存在")
+	// 创建新用户（用户名重复、未设置密码或无权分配该角色时返回表单）
+	newUser, err := c.UserSev.Create(formData)
+	if errors.Is(err, domain.ErrUsernameExists) || errors.Is(err, domain.ErrPasswordRequired) ||
+		errors.Is(err, domain.ErrRoleNotFound) || errors.Is(err, domain.ErrRoleForbidden) {
+		c.SetErrorToast(err.Error())
 		return &infra.ViewResponse{
 			Name: "users/new.html",
 			Data: formData,
 		}
 	}
-
-	// 创建新用户
-	newID := c.generateUserID()
-	newUser := c.createUser(formData, newID)
-	mockUsers[newID] = newUser
+	if err != nil {
+		return c.HandleServiceError(err, "用户")
+	}
 
 	// 设置成功提示并导航
 	c.NavigateTo("/users")
@@ -146,7 +118,7 @@ func (c *UserController) Post() freedom.Result {
 	return &infra.JSONResponse{
 		Object: map[string]interface{}{
 			"success": true,
-			"id":      newID,
+			"id":      newUser.ID,
 		},
 	}
 }
@@ -156,7 +128,7 @@ func (c *UserController) Post() freedom.Result {
 func (c *UserController) PutBy(id int64) freedom.Result {
 	var formData vo.UserFormData
 	if err := c.Request.ReadForm(&formData, true); err != nil {
-		user, _ := mockUsers[id]
+		user, _ := c.UserSev.Get(id)
 		return c.HandleValidationError(err, "users/edit.html", map[string]interface{}{
 			"User":     user,
 			"FormData": formData,
@@ -164,16 +136,16 @@ func (c *UserController) PutBy(id int64) freedom.Result {
 	}
 
 	// 查找并更新用户
-	user, exists := mockUsers[id]
-	if !exists {
-		c.Worker.IrisContext().Header("HX-Redirect", "/users")
-		return c.HandleNotFoundError("用户")
+	if _, err := c.UserSev.Update(id, formData); err != nil {
+		if errors.Is(err, dependency.ErrNotFound) {
+			c.Worker.IrisContext().Header("HX-Redirect", "/users")
+		}
+		if errors.Is(err, domain.ErrRoleNotFound) || errors.Is(err, domain.ErrRoleForbidden) {
+			return c.rejectUser(err)
+		}
+		return c.HandleServiceError(err, "用户")
 	}
 
-	// 更新用户信息
-	c.updateUser(&user, formData)
-	mockUsers[id] = user
-
 	// 设置成功提示并返回用户列表页面
 	c.SetSuccessToast("用户更新成功")
 	c.NavigateTo("/users")
@@ -189,15 +161,17 @@ func (c *UserController) PutBy(id int64) freedom.Result {
 // DeleteBy 删除用户
 // DELETE /users/{id}
 func (c *UserController) DeleteBy(id int64) freedom.Result {
-	// 检查用户是否存在
-	if _, exists := mockUsers[id]; !exists {
-		c.Worker.IrisContext().StatusCode(404)
-		return c.HandleNotFoundError("用户")
+	// 删除用户（不存在时返回 404）
+	if err := c.UserSev.Delete(id); err != nil {
+		if errors.Is(err, domain.ErrRoleForbidden) {
+			return c.rejectUser(err)
+		}
+		if errors.Is(err, dependency.ErrNotFound) {
+			c.Worker.IrisContext().StatusCode(404)
+		}
+		return c.HandleServiceError(err, "用户")
 	}
 
-	// 删除用户
-	delete(mockUsers, id)
-
 	// 设置成功提示
 	c.SetSuccessToast("用户删除成功")
 	c.Worker.IrisContext().StatusCode(200)
@@ -209,152 +183,94 @@ func (c *UserController) DeleteBy(id int64) freedom.Result {
 	return nil
 }
 
-// GetRoles 角色管理页面
-// GET /users/roles
-func (c *UserController) GetRoles() freedom.Result {
-	return &infra.ViewResponse{
-		Name: "users/roles.html",
-		Data: nil,
+// rejectUser 角色不存在时以 400、无权分配角色或修改管理员账户时以 403 拒绝，
+// HTMX 不替换页面，只显示错误提示
+func (c *UserController) rejectUser(err error) freedom.Result {
+	status := 403
+	if errors.Is(err, domain.ErrRoleNotFound) {
+		status = 400
 	}
+	c.SetErrorToast(err.Error())
+	c.Worker.IrisContext().StatusCode(status)
+	c.Worker.IrisContext().WriteString(err.Error())
+	return nil
 }
 
-// GetPermissions 权限管理页面
-// GET /users/permissions
-func (c *UserController) GetPermissions() freedom.Result {
+// GetRoleOptions 用户表单中的角色下拉选项
+// GET /users/role-options?selected=editor
+func (c *UserController) GetRoleOptions() freedom.Result {
+	roles, err := c.RoleSev.List()
+	if err != nil {
+		return c.HandleServiceError(err, "角色")
+	}
 	return &infra.ViewResponse{
-		Name: "users/permissions.html",
-		Data: nil,
+		Name: "users/role_options.html",
+		Data: map[string]interface{}{
+			"Roles":    roles,
+			"Selected": c.Worker.IrisContext().URLParam("selected"),
+		},
 	}
 }
 
 // getUserListData 获取用户列表数据（辅助方法）
 func (c *UserController) getUserListData() vo.UserListData {
 	// 使用默认搜索参数
-	params := vo.SearchParams{
-		Page:     1,
-		PageSize: 10,
+	page, err := c.UserSev.List(c.ListQuery(vo.SearchParams{}), vo.UserFilter{})
+	if err != nil {
+		c.Worker.Logger().Error("查询用户失败", freedom.LogFields{"error": err.Error()})
 	}
-
-	_, pagination := c.SearchHelper(params)
-	filteredUsers := c.filterUsers(params)
-
-	// 转换为 interface{} 进行分页
-	users := make([]interface{}, len(filteredUsers))
-	for i, user := range filteredUsers {
-		users[i] = user
-	}
-
-	pagedUsers, pagination := c.Paginate(users, pagination)
-
-	// 转换回用户类型
-	result := make([]vo.User, len(pagedUsers))
-	for i, user := range pagedUsers {
-		result[i] = user.(vo.User)
+	roles, err := c.RoleSev.List()
+	if err != nil {
+		c.Worker.Logger().Error("查询角色失败", freedom.LogFields{"error": err.Error()})
 	}
 
 	return vo.UserListData{
-		Users:    result,
-		PageInfo: c.CreatePageInfo(pagination),
+		Users:    page.Items,
+		PageInfo: page.PageInfo,
 		Query:    "",
-		Status:   "",
+		Roles:    roles,
 	}
 }
 
-// BeforeActivation 配置路由
-func (c *UserController) BeforeActivation(b freedom.BeforeActivation) {
-	b.Handle("GET", "/new", "GetNew")
-	b.Handle("GET", "/roles", "GetRoles")
-	b.Handle("GET", "/permissions", "GetPermissions")
-	b.Handle("GET", "/{id:int64}", "GetBy")
-	b.Handle("PUT", "/{id:int64}", "PutBy")
-	b.Handle("DELETE", "/{id:int64}", "DeleteBy")
-}
-
-// filterUsers 过滤用户
-func (c *UserController) filterUsers(params vo.SearchParams) []vo.User {
-	filtered := []vo.User{}
-
-	for _, user := range mockUsers {
-		// 使用基础控制器的过滤助手
-		if params.Keyword != "" {
-			if !c.FilterHelper(params.Keyword, user.Username) &&
-				!c.FilterHelper(params.Keyword, user.Email) &&
-				!c.FilterHelper(params.Keyword, user.RealName) {
-				continue
-			}
-		}
-
-		// 状态过滤
-		if params.Status != "" && user.Status != params.Status {
-			continue
-		}
-
-		filtered = append(filtered, user)
+// GetExport 按当前搜索和筛选条件导出用户，format 为 csv 或 xlsx
+// GET /users/export?format=csv&role=editor
+func (c *UserController) GetExport() freedom.Result {
+	var filter vo.UserFilter
+	query, err := c.ReadExportQuery(&filter)
+	if err != nil {
+		return &infra.JSONResponse{Code: 400, Error: err}
 	}
 
-	// 按 ID 降序排序（最新的在前面）
-	c.sortUsersByID(filtered)
-
-	return filtered
-}
-
-// findUserByID 根据 ID 查找用户
-func (c *UserController) findUserByID(id int64) *vo.User {
-	if user, ok := mockUsers[id]; ok {
-		return &user
-	}
-	return nil
-}
-
-// isUsernameExists 检查用户名是否已存在
-func (c *UserController) isUsernameExists(username string) bool {
-	for _, user := range mockUsers {
-		if user.Username == username {
-			return true
-		}
+	return &infra.ExportResponse{
+		Filename: c.ExportFilename("users"),
+		Format:   c.Worker.IrisContext().URLParam("format"),
+		Header:   []string{"id", "username", "email", "real_name", "phone", "role", "status", "created_at"},
+		Rows: func(write func(row ...interface{}) error) error {
+			return c.UserSev.Export(query, filter, func(user vo.User) error {
+				return write(user.ID, user.Username, user.Email, user.RealName, user.Phone, user.Role, user.Status, user.CreatedAt)
+			})
+		},
 	}
-	return false
-}
-
-// generateUserID 生成新的用户ID
-func (c *UserController) generateUserID() int64 {
-	userIDCounter++
-	return userIDCounter
 }
 
-// createUser 创建用户对象
-func (c *UserController) createUser(formData vo.UserFormData, id int64) vo.User {
-	return vo.User{
-		ID:        id,
-		Username:  formData.Username,
-		Email:     formData.Email,
-		RealName:  formData.RealName,
-		Phone:     formData.Phone,
-		Role:      formData.Role,
-		Status:    formData.Status,
-		Avatar:    fmt.Sprintf("https://i.pravatar.cc/150?img=%d", (id%70)+1),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+// PostBulk 批量删除、启用、封禁用户或设置角色，返回受影响行的带外替换
+// POST /users/bulk  ids=1&ids=2&action=set_role&value=editor
+func (c *UserController) PostBulk() freedom.Result {
+	form, ok := c.ReadBulkForm("users")
+	if !ok {
+		return nil
 	}
+	result, err := c.UserSev.Bulk(form.IDs, form.Action, form.Value)
+	return bulkResponse(&c.BaseController, "users/bulk.html", result, err, form.Action == domain.BulkDelete)
 }
 
-// updateUser 更新用户信息
-func (c *UserController) updateUser(user *vo.User, formData vo.UserFormData) {
-	user.Email = formData.Email
-	user.RealName = formData.RealName
-	user.Phone = formData.Phone
-	user.Role = formData.Role
-	user.Status = formData.Status
-	user.UpdatedAt = time.Now()
-}
-
-// sortUsersByID 按 ID 降序排序用户
-func (c *UserController) sortUsersByID(users []vo.User) {
-	for i := 0; i < len(users)-1; i++ {
-		for j := i + 1; j < len(users); j++ {
-			if users[i].ID < users[j].ID {
-				users[i], users[j] = users[j], users[i]
-			}
-		}
-	}
+// BeforeActivation 配置路由
+func (c *UserController) BeforeActivation(b freedom.BeforeActivation) {
+	b.Handle("GET", "/export", "GetExport")
+	b.Handle("POST", "/bulk", "PostBulk")
+	b.Handle("GET", "/new", "GetNew")
+	b.Handle("GET", "/role-options", "GetRoleOptions")
+	b.Handle("GET", "/{id:int64}", "GetBy")
+	b.Handle("PUT", "/{id:int64}", "PutBy")
+	b.Handle("DELETE", "/{id:int64}", "DeleteBy")
 }
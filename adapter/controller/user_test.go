@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"godash/adapter/repository"
+	"godash/domain/dependency"
+	"godash/infra"
+	"godash/internal/testutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// userForm 编辑用户的表单，新增时再加上密码
+func userForm(username, status string) url.Values {
+	return url.Values{
+		"username":  {username},
+		"email":     {username + "@example.com"},
+		"real_name": {"并发用户"},
+		"role":      {"viewer"},
+		"status":    {status},
+	}
+}
+
+// checkNoServerError 并发请求不能出现 5xx 或“操作失败”
+func checkNoServerError(t *testing.T, method, path string, resp testResponse) {
+	t.Helper()
+	if _, message := resp.toast(); resp.StatusCode >= 500 || strings.HasPrefix(message, "操作失败") {
+		t.Errorf("%s %s: %v", method, path, resp)
+	}
+}
+
+func TestUserHandlersConcurrently(t *testing.T) {
+	client := loginAdmin(t)
+	prefix := testutil.UniqueKey("handler-user")
+
+	// 12 个请求同时新增 4 个用户名，每个用户名只能创建一次（密码哈希较慢，请求数不宜过多）
+	var mu sync.Mutex
+	created := map[string]int64{}
+	testutil.Parallel(12, func(i int) {
+		username := fmt.Sprintf("%s-%d", prefix, i%4)
+		form := userForm(username, "active")
+		form.Set("password", "secret-123")
+		resp := client.do("POST", "/users", form)
+		checkNoServerError(t, "POST", "/users", resp)
+		id := resp.createdID()
+		if id == 0 {
+			if _, message := resp.toast(); message != "用户名已存在" {
+				t.Errorf("POST /users %s: %v", username, resp)
+			}
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := created[username]; ok {
+			t.Errorf("user %s created twice", username)
+		}
+		created[username] = id
+	})
+	if len(created) != 4 {
+		t.Fatalf("created %d users, want 4", len(created))
+	}
+
+	// 同时编辑、删除（用户名末位为偶数的用户）和查看这些用户
+	usernames := make([]string, 0, len(created))
+	for username := range created {
+		usernames = append(usernames, username)
+	}
+	deleted := func(username string) bool {
+		return (username[len(username)-1]-'0')%2 == 0
+	}
+	testutil.Parallel(36, func(i int) {
+		username := usernames[i%len(usernames)]
+		path := fmt.Sprintf("/users/%d", created[username])
+		switch i / len(usernames) % 3 {
+		case 0:
+			checkNoServerError(t, "PUT", path, client.do("PUT", path, userForm(username, "banned")))
+		case 1:
+			if deleted(username) {
+				checkNoServerError(t, "DELETE", path, client.do("DELETE", path, nil))
+			}
+		default:
+			checkNoServerError(t, "GET", path, client.do("GET", path, nil))
+			checkNoServerError(t, "GET", "/users", client.do("GET", "/users?keyword="+prefix, nil))
+		}
+	})
+
+	repo := &repository.UserMemoryRepository{}
+	for username, id := range created {
+		user, err := repo.Get(id)
+		if deleted(username) {
+			if !errors.Is(err, dependency.ErrNotFound) {
+				t.Errorf("user %s should be deleted, got %v", username, err)
+			}
+			continue
+		}
+		if err != nil || user.Status != "banned" {
+			t.Errorf("user %s: %+v, %v; want banned", username, user, err)
+		}
+	}
+}
+
+func TestLoginRejectsOversizedForm(t *testing.T) {
+	form := url.Values{"username": {testAdminUsername}, "password": {strings.Repeat("x", 2<<20)}}
+	resp, err := http.PostForm(baseURL+infra.LoginPath, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST %s with a 2MB form: %d, want 413", infra.LoginPath, resp.StatusCode)
+	}
+}
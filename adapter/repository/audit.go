@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *AuditRepository {
+			return &AuditRepository{}
+		})
+	})
+}
+
+var _ dependency.AuditRepo = (*AuditRepository)(nil)
+
+// AuditRepository 审计日志资源库（GORM）
+type AuditRepository struct {
+	freedom.Repository
+}
+
+// New 追加审计日志，回写自增 ID
+func (repo *AuditRepository) New(log *vo.AuditLog) error {
+	obj := po.NewAuditLog(*log)
+	if err := repo.db().Create(obj).Error; err != nil {
+		return err
+	}
+	log.ID = obj.ID
+	return nil
+}
+
+// Finds 按实体和操作人分页查询，最新的在前
+func (repo *AuditRepository) Finds(query vo.ListQuery, filter vo.AuditFilter) (vo.Page[vo.AuditLog], error) {
+	db := repo.db()
+	if filter.Entity != "" {
+		db = db.Where("entity = ?", filter.Entity)
+	}
+	if filter.Actor != "" {
+		db = db.Where("actor = ?", filter.Actor)
+	}
+	return findPage(db, query, auditLogPage, (*po.AuditLog).ToVO)
+}
+
+// db .
+func (repo *AuditRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *AuditMemoryRepository {
+			return &AuditMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.AuditRepo = (*AuditMemoryRepository)(nil)
+
+// memAuditLogs 内存审计日志
+var memAuditLogs = newMemoryTable(cloneAuditLog)
+
+// cloneAuditLog 复制审计日志，变更切片不与表内数据共享
+func cloneAuditLog(log vo.AuditLog) vo.AuditLog {
+	log.Changes = append([]vo.AuditChange(nil), log.Changes...)
+	return log
+}
+
+// AuditMemoryRepository 审计日志资源库（内存）
+type AuditMemoryRepository struct {
+	freedom.Repository
+}
+
+// New 追加审计日志，分配 ID
+func (repo *AuditMemoryRepository) New(log *vo.AuditLog) error {
+	row, _ := memAuditLogs.insert(func(id int64) vo.AuditLog {
+		log.ID = id
+		return *log
+	}, nil)
+	*log = row
+	return nil
+}
+
+// Finds 按实体和操作人分页查询，最新的在前
+func (repo *AuditMemoryRepository) Finds(query vo.ListQuery, filter vo.AuditFilter) (vo.Page[vo.AuditLog], error) {
+	list := memAuditLogs.filter(func(log vo.AuditLog) bool {
+		return (filter.Entity == "" || log.Entity == filter.Entity) && (filter.Actor == "" || log.Actor == filter.Actor)
+	})
+	return auditLogPage.pageRows(list, query), nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// init 生成内存模式的 mock 数据，订单项引用商品，所以先生成商品
+func init() {
+	seedProducts()
+	seedOrders()
+}
+
+// memoryTable 并发安全的内存表
+// 读写均通过 clone 复制数据，调用方拿到的对象与表内数据互不影响。
+type memoryTable[T any] struct {
+	mu     sync.RWMutex
+	rows   map[int64]T
+	lastID atomic.Int64
+	clone  func(T) T
+}
+
+// newMemoryTable 创建内存表，clone 为 nil 时按值复制
+func newMemoryTable[T any](clone func(T) T) *memoryTable[T] {
+	if clone == nil {
+		clone = func(row T) T { return row }
+	}
+	return &memoryTable[T]{
+		rows:  make(map[int64]T),
+		clone: clone,
+	}
+}
+
+// nextID 原子地生成下一个 ID
+func (t *memoryTable[T]) nextID() int64 {
+	return t.lastID.Add(1)
+}
+
+// seed 写入初始数据，并保证后续生成的 ID 大于 id
+func (t *memoryTable[T]) seed(id int64, row T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows[id] = t.clone(row)
+	for {
+		last := t.lastID.Load()
+		if last >= id || t.lastID.CompareAndSwap(last, id) {
+			return
+		}
+	}
+}
+
+// get 根据 ID 读取
+func (t *memoryTable[T]) get(id int64) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	row, ok := t.rows[id]
+	if !ok {
+		return row, false
+	}
+	return t.clone(row), true
+}
+
+// find 返回第一条满足条件的数据
+func (t *memoryTable[T]) find(match func(T) bool) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, row := range t.rows {
+		if match(row) {
+			return t.clone(row), true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// filter 返回所有满足条件的数据，按 ID 升序
+func (t *memoryTable[T]) filter(match func(T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ids := make([]int64, 0, len(t.rows))
+	for id, row := range t.rows {
+		if match == nil || match(row) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	result := make([]T, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, t.clone(t.rows[id]))
+	}
+	return result
+}
+
+// insert 分配 ID 并写入，conflict 命中任一已有数据时放弃写入并返回 false
+func (t *memoryTable[T]) insert(build func(id int64) T, conflict func(T) bool) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if conflict != nil {
+		for _, row := range t.rows {
+			if conflict(row) {
+				var zero T
+				return zero, false
+			}
+		}
+	}
+	id := t.nextID()
+	row := build(id)
+	t.rows[id] = t.clone(row)
+	return row, true
+}
+
+// insertAll 在一次加锁内为 n 条数据分配 ID 并写入，
+// conflict 对任一条新数据命中已有数据时全部放弃并返回 false
+func (t *memoryTable[T]) insertAll(n int, build func(i int, id int64) T, conflict func(i int, existing T) bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if conflict != nil {
+		for _, row := range t.rows {
+			for i := 0; i < n; i++ {
+				if conflict(i, row) {
+					return false
+				}
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		id := t.nextID()
+		t.rows[id] = t.clone(build(i, id))
+	}
+	return true
+}
+
+// update 覆盖已有数据，数据不存在时返回 false
+func (t *memoryTable[T]) update(id int64, row T) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.rows[id]; !ok {
+		return false
+	}
+	t.rows[id] = t.clone(row)
+	return true
+}
+
+// updateIf 当前数据满足 match 时覆盖，返回数据是否存在以及是否已写入
+func (t *memoryTable[T]) updateIf(id int64, row T, match func(current T) bool) (found, updated bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current, ok := t.rows[id]
+	if !ok {
+		return false, false
+	}
+	if !match(current) {
+		return true, false
+	}
+	t.rows[id] = t.clone(row)
+	return true, true
+}
+
+// mutate 在一次加锁内把满足 match 的数据副本交给 fn 修改，fn 返回 nil 时写回全部副本，
+// 返回错误时表内数据不变
+func (t *memoryTable[T]) mutate(match func(T) bool, fn func(rows map[int64]*T) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rows := make(map[int64]*T)
+	for id, row := range t.rows {
+		if match(row) {
+			copied := t.clone(row)
+			rows[id] = &copied
+		}
+	}
+	if err := fn(rows); err != nil {
+		return err
+	}
+	for id, row := range rows {
+		t.rows[id] = t.clone(*row)
+	}
+	return nil
+}
+
+// remove 删除数据，数据不存在时返回 false
+func (t *memoryTable[T]) remove(id int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.rows[id]; !ok {
+		return false
+	}
+	delete(t.rows, id)
+	return true
+}
+
+// matchKeyword 判断任一字段是否包含关键词（忽略大小写），关键词为空时总是匹配
+func matchKeyword(keyword string, fields ...string) bool {
+	if keyword == "" {
+		return true
+	}
+	lowerKeyword := strings.ToLower(keyword)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), lowerKeyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange 判断数值是否在 [min, max] 内，边界为 0 表示不限
+func inRange(value, min, max float64) bool {
+	return (min <= 0 || value >= min) && (max <= 0 || value <= max)
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"godash/internal/testutil"
+	"sync"
+	"testing"
+)
+
+var errTestRollback = errors.New("rollback")
+
+type testRow struct {
+	Key   string
+	Count int
+	Tags  []string
+}
+
+func newTestTable() *memoryTable[testRow] {
+	return newMemoryTable(func(row testRow) testRow {
+		row.Tags = append([]string(nil), row.Tags...)
+		return row
+	})
+}
+
+func TestMemoryTableInsertConflict(t *testing.T) {
+	table := newTestTable()
+	keys := []string{"a", "b", "c"}
+	var mu sync.Mutex
+	inserted := map[string]int{}
+	testutil.Parallel(60, func(i int) {
+		key := keys[i%len(keys)]
+		_, ok := table.insert(func(id int64) testRow {
+			return testRow{Key: key}
+		}, func(existing testRow) bool {
+			return existing.Key == key
+		})
+		if ok {
+			mu.Lock()
+			inserted[key]++
+			mu.Unlock()
+		}
+	})
+
+	for _, key := range keys {
+		if inserted[key] != 1 {
+			t.Errorf("key %s inserted %d times, want 1", key, inserted[key])
+		}
+	}
+	if rows := table.filter(nil); len(rows) != len(keys) {
+		t.Errorf("table has %d rows, want %d", len(rows), len(keys))
+	}
+}
+
+func TestMemoryTableInsertIDs(t *testing.T) {
+	table := newTestTable()
+	table.seed(10, testRow{Key: "seed"})
+	var mu sync.Mutex
+	ids := map[int64]bool{}
+	testutil.Parallel(50, func(i int) {
+		row, _ := table.insert(func(id int64) testRow { return testRow{Count: int(id)} }, nil)
+		mu.Lock()
+		ids[int64(row.Count)] = true
+		mu.Unlock()
+	})
+
+	if len(ids) != 50 {
+		t.Fatalf("got %d distinct ids, want 50", len(ids))
+	}
+	for id := range ids {
+		if id <= 10 {
+			t.Errorf("id %d not greater than seeded id", id)
+		}
+	}
+}
+
+func TestMemoryTableUpdateIf(t *testing.T) {
+	table := newTestTable()
+	table.insert(func(id int64) testRow { return testRow{Key: "counter"} }, nil)
+	id := int64(1)
+
+	// 每个 goroutine 读出当前值后按比较并交换加一，失败时重读重试，最终不丢失任何一次加一
+	testutil.Parallel(50, func(int) {
+		for {
+			current, _ := table.get(id)
+			next := current
+			next.Count++
+			if _, updated := table.updateIf(id, next, func(r testRow) bool { return r.Count == current.Count }); updated {
+				return
+			}
+		}
+	})
+
+	got, _ := table.get(id)
+	if got.Count != 50 {
+		t.Errorf("count = %d, want 50", got.Count)
+	}
+	if found, _ := table.updateIf(99, testRow{}, func(testRow) bool { return true }); found {
+		t.Errorf("updateIf found a missing row")
+	}
+}
+
+func TestMemoryTableMutate(t *testing.T) {
+	table := newTestTable()
+	for i := 0; i < 5; i++ {
+		table.insert(func(id int64) testRow { return testRow{Key: "row"} }, nil)
+	}
+	testutil.Parallel(40, func(i int) {
+		err := table.mutate(func(r testRow) bool { return r.Key == "row" }, func(rows map[int64]*testRow) error {
+			for _, row := range rows {
+				row.Count++
+			}
+			if i%4 == 0 {
+				return errTestRollback
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errTestRollback) {
+			t.Errorf("mutate: %v", err)
+		}
+	})
+
+	for _, row := range table.filter(nil) {
+		if row.Count != 30 {
+			t.Errorf("count = %d, want 30 (failed mutations must not be written)", row.Count)
+		}
+	}
+}
+
+func TestMemoryTableClone(t *testing.T) {
+	table := newTestTable()
+	row, _ := table.insert(func(id int64) testRow { return testRow{Tags: []string{"a"}} }, nil)
+	row.Tags[0] = "changed"
+
+	got, _ := table.get(1)
+	got.Tags[0] = "changed again"
+	if again, _ := table.get(1); again.Tags[0] != "a" {
+		t.Errorf("table data shared with caller: %v", again.Tags)
+	}
+}
+
+func TestMemoryTableConcurrentReadWrite(t *testing.T) {
+	table := newTestTable()
+	for i := 0; i < 10; i++ {
+		table.insert(func(id int64) testRow { return testRow{Tags: []string{"x"}} }, nil)
+	}
+	testutil.Parallel(60, func(i int) {
+		id := int64(i%10 + 1)
+		switch i % 6 {
+		case 0:
+			table.update(id, testRow{Count: i, Tags: []string{"y"}})
+		case 1:
+			table.remove(id)
+		case 2:
+			table.insert(func(id int64) testRow { return testRow{Count: i} }, nil)
+		case 3:
+			table.filter(func(r testRow) bool { return r.Count%2 == 0 })
+		case 4:
+			if row, ok := table.get(id); ok && len(row.Tags) > 0 {
+				row.Tags[0] = "local"
+			}
+		default:
+			table.find(func(r testRow) bool { return r.Count == i })
+		}
+	})
+}
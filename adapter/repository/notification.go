@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+	"time"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *NotificationRepository {
+			return &NotificationRepository{}
+		})
+	})
+}
+
+var _ dependency.NotificationRepo = (*NotificationRepository)(nil)
+
+// NotificationRepository 站内通知资源库（GORM）
+type NotificationRepository struct {
+	freedom.Repository
+}
+
+// NewBatch 一次写入多条通知，回写自增 ID
+func (repo *NotificationRepository) NewBatch(notifications []*vo.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	objs := make([]*po.Notification, len(notifications))
+	for i, notification := range notifications {
+		objs[i] = po.NewNotification(*notification)
+	}
+	if err := repo.db().Create(&objs).Error; err != nil {
+		return err
+	}
+	for i, obj := range objs {
+		notifications[i].ID = obj.ID
+	}
+	return nil
+}
+
+// Finds 分页查询接收人的通知，最新的在前
+func (repo *NotificationRepository) Finds(userID int64, query vo.ListQuery, filter vo.NotificationFilter) (vo.Page[vo.Notification], error) {
+	db := repo.db().Where("user_id = ?", userID)
+	if filter.Status == vo.NotificationUnread {
+		db = db.Where("is_read = ?", false)
+	}
+	return findPage(db, query, notificationPage, (*po.Notification).ToVO)
+}
+
+// CountUnread 接收人的未读通知数
+func (repo *NotificationRepository) CountUnread(userID int64) (int, error) {
+	var count int64
+	err := repo.db().Model(&po.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&count).Error
+	return int(count), err
+}
+
+// MarkRead 把接收人的一条通知标记为已读，返回标记后的通知
+func (repo *NotificationRepository) MarkRead(userID, id int64, at time.Time) (*vo.Notification, error) {
+	var obj po.Notification
+	if err := repo.db().Where("id = ? AND user_id = ?", id, userID).First(&obj).Error; err != nil {
+		return nil, convertError(err)
+	}
+	if !obj.Read {
+		err := repo.db().Model(&po.Notification{}).Where("id = ? AND is_read = ?", id, false).
+			Updates(map[string]interface{}{"is_read": true, "read_at": at}).Error
+		if err != nil {
+			return nil, err
+		}
+		obj.Read, obj.ReadAt = true, &at
+	}
+	notification := obj.ToVO()
+	return &notification, nil
+}
+
+// MarkAllRead 把接收人的全部未读通知标记为已读
+func (repo *NotificationRepository) MarkAllRead(userID int64, at time.Time) (int, error) {
+	result := repo.db().Model(&po.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": at})
+	return int(result.RowsAffected), result.Error
+}
+
+// db .
+func (repo *NotificationRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *NotificationMemoryRepository {
+			return &NotificationMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.NotificationRepo = (*NotificationMemoryRepository)(nil)
+
+// memNotifications 内存站内通知
+var memNotifications = newMemoryTable[vo.Notification](nil)
+
+// NotificationMemoryRepository 站内通知资源库（内存）
+type NotificationMemoryRepository struct {
+	freedom.Repository
+}
+
+// NewBatch 一次写入多条通知，分配 ID
+func (repo *NotificationMemoryRepository) NewBatch(notifications []*vo.Notification) error {
+	memNotifications.insertAll(len(notifications), func(i int, id int64) vo.Notification {
+		notifications[i].ID = id
+		return *notifications[i]
+	}, nil)
+	return nil
+}
+
+// Finds 分页查询接收人的通知，最新的在前
+func (repo *NotificationMemoryRepository) Finds(userID int64, query vo.ListQuery, filter vo.NotificationFilter) (vo.Page[vo.Notification], error) {
+	list := memNotifications.filter(func(notification vo.Notification) bool {
+		return notification.UserID == userID && (filter.Status != vo.NotificationUnread || !notification.Read)
+	})
+	return notificationPage.pageRows(list, query), nil
+}
+
+// CountUnread 接收人的未读通知数
+func (repo *NotificationMemoryRepository) CountUnread(userID int64) (int, error) {
+	return len(memNotifications.filter(func(notification vo.Notification) bool {
+		return notification.UserID == userID && !notification.Read
+	})), nil
+}
+
+// MarkRead 把接收人的一条通知标记为已读，返回标记后的通知
+func (repo *NotificationMemoryRepository) MarkRead(userID, id int64, at time.Time) (*vo.Notification, error) {
+	var result *vo.Notification
+	err := memNotifications.mutate(func(notification vo.Notification) bool {
+		return notification.ID == id && notification.UserID == userID
+	}, func(rows map[int64]*vo.Notification) error {
+		for _, notification := range rows {
+			if !notification.Read {
+				notification.Read = true
+				notification.ReadAt = at
+			}
+			copied := *notification
+			result = &copied
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, dependency.ErrNotFound
+	}
+	return result, nil
+}
+
+// MarkAllRead 把接收人的全部未读通知标记为已读
+func (repo *NotificationMemoryRepository) MarkAllRead(userID int64, at time.Time) (int, error) {
+	count := 0
+	err := memNotifications.mutate(func(notification vo.Notification) bool {
+		return notification.UserID == userID && !notification.Read
+	}, func(rows map[int64]*vo.Notification) error {
+		for _, notification := range rows {
+			notification.Read = true
+			notification.ReadAt = at
+		}
+		count = len(rows)
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+	"slices"
+	"time"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *OrderRepository {
+			return &OrderRepository{}
+		})
+	})
+}
+
+var _ dependency.OrderRepo = (*OrderRepository)(nil)
+
+// OrderRepository 订单资源库（GORM）
+type OrderRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取订单及订单项
+func (repo *OrderRepository) Get(id int64) (*vo.Order, error) {
+	var order po.Order
+	if err := repo.db().Preload("Items").Where("id = ?", id).Take(&order).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := order.ToVO()
+	return &result, nil
+}
+
+// Finds 按关键词、状态、支付方式、下单日期和金额分页查询订单，默认按 ID 升序
+func (repo *OrderRepository) Finds(query vo.ListQuery, filter vo.OrderFilter) (vo.Page[vo.Order], error) {
+	db := repo.db()
+	if query.Keyword != "" {
+		like := "%" + query.Keyword + "%"
+		db = db.Where("order_no LIKE ? OR customer_name LIKE ? OR customer_email LIKE ?", like, like, like)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.PaymentMethod != "" {
+		db = db.Where("payment_method = ?", filter.PaymentMethod)
+	}
+	from, to := filter.CreatedRange()
+	if !from.IsZero() {
+		db = db.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		db = db.Where("created_at < ?", to)
+	}
+	if filter.MinAmount > 0 {
+		db = db.Where("total_amount >= ?", filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		db = db.Where("total_amount <= ?", filter.MaxAmount)
+	}
+	return findPage(db, query, orderPage, (*po.Order).ToVO, "Items")
+}
+
+// New 创建订单及订单项，回写 ID
+func (repo *OrderRepository) New(order *vo.Order) error {
+	obj := po.NewOrder(*order)
+	if err := repo.db().Create(obj).Error; err != nil {
+		return convertError(err)
+	}
+	*order = obj.ToVO()
+	return nil
+}
+
+// Save 保存订单主信息（不含订单项）
+func (repo *OrderRepository) Save(order *vo.Order) error {
+	return repo.db().Omit("Items").Save(po.NewOrder(*order)).Error
+}
+
+// SaveWithItems 在一个事务中按状态条件更新订单主信息，并删除旧订单项、写入新订单项
+func (repo *OrderRepository) SaveWithItems(order *vo.Order, status string) error {
+	return repo.db().Transaction(func(tx *gorm.DB) error {
+		obj := po.NewOrder(*order)
+		result := tx.Model(&po.Order{}).Where("id = ? AND status = ?", order.ID, status).
+			Updates(map[string]interface{}{
+				"customer_name":  obj.CustomerName,
+				"customer_email": obj.CustomerEmail,
+				"total_amount":   obj.TotalAmount,
+				"payment_method": obj.PaymentMethod,
+				"updated_at":     obj.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return dependency.ErrConflict
+		}
+		if err := tx.Where("order_id = ?", order.ID).Delete(&po.OrderItem{}).Error; err != nil {
+			return err
+		}
+		for i := range obj.Items {
+			obj.Items[i].ID = 0
+		}
+		if len(obj.Items) > 0 {
+			if err := tx.Create(&obj.Items).Error; err != nil {
+				return err
+			}
+		}
+		*order = obj.ToVO()
+		return nil
+	})
+}
+
+// SaveStatus 在一个事务中按原状态条件更新订单状态并追加变更记录
+func (repo *OrderRepository) SaveStatus(order *vo.Order, change *vo.OrderStatusChange) error {
+	return repo.db().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&po.Order{}).Where("id = ? AND status = ?", order.ID, change.From).
+			Updates(map[string]interface{}{"status": order.Status, "updated_at": order.UpdatedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return dependency.ErrConflict
+		}
+		obj := po.NewOrderStatusChange(*change)
+		if err := tx.Create(obj).Error; err != nil {
+			return err
+		}
+		change.ID = obj.ID
+		return nil
+	})
+}
+
+// FindStatusChanges 订单的状态变更记录，按时间先后排列
+func (repo *OrderRepository) FindStatusChanges(orderID int64) ([]vo.OrderStatusChange, error) {
+	var rows []po.OrderStatusChange
+	if err := repo.db().Where("order_id = ?", orderID).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make([]vo.OrderStatusChange, 0, len(rows))
+	for i := range rows {
+		result = append(result, rows[i].ToVO())
+	}
+	return result, nil
+}
+
+// Stats 订单数、待处理订单数和收入，since 之后下单的另计为今日
+func (repo *OrderRepository) Stats(since time.Time) (vo.OrderStats, error) {
+	var stats vo.OrderStats
+	err := repo.db().Model(&po.Order{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS pending, "+
+			"COALESCE(SUM(CASE WHEN created_at >= ? THEN 1 ELSE 0 END), 0) AS today", vo.OrderPending, since).
+		Scan(&stats).Error
+	if err != nil {
+		return stats, err
+	}
+
+	paid := func() *gorm.DB {
+		return repo.db().Model(&po.Order{}).Where("status IN ?", vo.OrderPaidStatuses)
+	}
+	if stats.Revenue, err = repo.revenue(paid); err != nil {
+		return stats, err
+	}
+	stats.TodayRevenue, err = repo.revenue(func() *gorm.DB {
+		return paid().Where("created_at >= ?", since)
+	})
+	return stats, err
+}
+
+// revenue orders 查询出的订单金额合计减去这些订单已确认的退款
+func (repo *OrderRepository) revenue(orders func() *gorm.DB) (float64, error) {
+	var paid, refunded float64
+	if err := orders().Select("COALESCE(SUM(total_amount), 0)").Row().Scan(&paid); err != nil {
+		return 0, err
+	}
+	err := repo.db().Model(&po.Refund{}).Select("COALESCE(SUM(amount), 0)").
+		Where("status = ? AND order_id IN (?)", vo.RefundCompleted, orders().Select("id")).
+		Row().Scan(&refunded)
+	return paid - refunded, err
+}
+
+// TopProducts 按 SKU 汇总已售出订单的订单项，按件数降序取前 limit 个
+func (repo *OrderRepository) TopProducts(limit int) ([]vo.ProductSales, error) {
+	var rows []vo.ProductSales
+	sold := repo.db().Model(&po.Order{}).Select("id").Where("status IN ?", vo.OrderSoldStatuses)
+	err := repo.db().Model(&po.OrderItem{}).
+		Select("sku, MAX(product_name) AS product_name, SUM(quantity) AS quantity, SUM(subtotal) AS amount").
+		Where("order_id IN (?)", sold).
+		Group("sku").Order("quantity DESC, amount DESC, sku").Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FindPoints 在 [from, to) 内下单的订单的下单时间和计入收入的金额
+func (repo *OrderRepository) FindPoints(from, to time.Time) ([]vo.OrderPoint, error) {
+	created := func() *gorm.DB {
+		return repo.db().Model(&po.Order{}).Where("created_at >= ? AND created_at < ?", from, to)
+	}
+	var orders []struct {
+		ID          int64
+		CreatedAt   time.Time
+		Status      string
+		TotalAmount float64
+	}
+	if err := created().Select("id, created_at, status, total_amount").Scan(&orders).Error; err != nil {
+		return nil, err
+	}
+	var refunds []struct {
+		OrderID int64
+		Amount  float64
+	}
+	err := repo.db().Model(&po.Refund{}).Select("order_id, SUM(amount) AS amount").
+		Where("status = ? AND order_id IN (?)", vo.RefundCompleted, created().Select("id")).
+		Group("order_id").Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	refunded := make(map[int64]float64, len(refunds))
+	for _, refund := range refunds {
+		refunded[refund.OrderID] = refund.Amount
+	}
+
+	points := make([]vo.OrderPoint, 0, len(orders))
+	for _, order := range orders {
+		point := vo.OrderPoint{CreatedAt: order.CreatedAt}
+		if slices.Contains(vo.OrderPaidStatuses, order.Status) {
+			point.Revenue = order.TotalAmount - refunded[order.ID]
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// db .
+func (repo *OrderRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
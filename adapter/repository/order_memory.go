@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *OrderMemoryRepository {
+			return &OrderMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.OrderRepo = (*OrderMemoryRepository)(nil)
+
+// memOrders 内存订单数据，订单项切片在读写时深拷贝
+var memOrders = newMemoryTable(cloneOrder)
+
+// memOrderStatusChanges 内存订单状态变更记录
+var memOrderStatusChanges = newMemoryTable[vo.OrderStatusChange](nil)
+
+// seedOrders 初始化订单 mock 数据，订单项引用已有商品。待处理订单为商品预留库存，
+// 已支付及之后的订单视为已经扣减过库存
+func seedOrders() {
+	customers := []string{"张三", "李四", "王五", "赵六", "孙七", "周八", "吴九", "郑十"}
+	payments := vo.PaymentMethods
+	products := memProducts.filter(nil)
+
+	for i := 0; i < 300; i++ {
+		status := vo.OrderStatuses[i%len(vo.OrderStatuses)]
+
+		// 生成订单项，同一订单内商品不重复
+		itemCount := rand.Intn(3) + 1
+		items := make([]vo.OrderItem, 0, itemCount)
+		totalAmount := 0.0
+		for _, k := range rand.Perm(len(products))[:itemCount] {
+			product := &products[k]
+			quantity := rand.Intn(3) + 1
+			if status == vo.OrderPending {
+				quantity = min(quantity, product.Available())
+				if quantity <= 0 {
+					continue
+				}
+				product.Reserved += quantity
+			}
+			subtotal := math.Round(product.Price*float64(quantity)*100) / 100
+			totalAmount += subtotal
+
+			items = append(items, vo.OrderItem{
+				ID:          int64(len(items) + 1),
+				ProductName: product.Name,
+				SKU:         product.SKU,
+				Quantity:    quantity,
+				Price:       product.Price,
+				Subtotal:    subtotal,
+			})
+		}
+		if len(items) == 0 {
+			status = vo.OrderCancelled
+		}
+
+		order := vo.Order{
+			ID:            int64(i + 1),
+			OrderNo:       fmt.Sprintf("ORD%s%04d", time.Now().Format("20060102"), i+1),
+			CustomerName:  customers[i%len(customers)],
+			CustomerEmail: fmt.Sprintf("customer%d@example.com", i+1),
+			TotalAmount:   math.Round(totalAmount*100) / 100,
+			Status:        status,
+			PaymentMethod: payments[i%len(payments)],
+			Items:         items,
+			CreatedAt:     time.Now().Add(-time.Duration(i) * 24 * time.Hour),
+			UpdatedAt:     time.Now().Add(-time.Duration(i) * time.Hour),
+		}
+		memOrders.seed(order.ID, order)
+	}
+
+	for _, product := range products {
+		memProducts.update(product.ID, product)
+	}
+}
+
+// OrderMemoryRepository 订单资源库（内存）
+type OrderMemoryRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取订单
+func (repo *OrderMemoryRepository) Get(id int64) (*vo.Order, error) {
+	order, ok := memOrders.get(id)
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &order, nil
+}
+
+// Finds 按关键词、状态、支付方式、下单日期和金额分页查询订单，默认按 ID 升序
+func (repo *OrderMemoryRepository) Finds(query vo.ListQuery, filter vo.OrderFilter) (vo.Page[vo.Order], error) {
+	from, to := filter.CreatedRange()
+	result := memOrders.filter(func(order vo.Order) bool {
+		if !matchKeyword(query.Keyword, order.OrderNo, order.CustomerName, order.CustomerEmail) {
+			return false
+		}
+		if filter.Status != "" && order.Status != filter.Status {
+			return false
+		}
+		if filter.PaymentMethod != "" && order.PaymentMethod != filter.PaymentMethod {
+			return false
+		}
+		if (!from.IsZero() && order.CreatedAt.Before(from)) || (!to.IsZero() && !order.CreatedAt.Before(to)) {
+			return false
+		}
+		return inRange(order.TotalAmount, filter.MinAmount, filter.MaxAmount)
+	})
+	return orderPage.pageRows(result, query), nil
+}
+
+// New 创建订单，分配订单和订单项 ID，订单号重复时返回 ErrDuplicate
+func (repo *OrderMemoryRepository) New(order *vo.Order) error {
+	row, ok := memOrders.insert(func(id int64) vo.Order {
+		order.ID = id
+		for i := range order.Items {
+			order.Items[i].ID = int64(i + 1)
+		}
+		return *order
+	}, func(existing vo.Order) bool {
+		return existing.OrderNo == order.OrderNo
+	})
+	if !ok {
+		return dependency.ErrDuplicate
+	}
+	*order = row
+	return nil
+}
+
+// Save 保存订单
+func (repo *OrderMemoryRepository) Save(order *vo.Order) error {
+	if !memOrders.update(order.ID, *order) {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// SaveWithItems 订单状态仍为 status 时保存订单并重新分配订单项 ID
+func (repo *OrderMemoryRepository) SaveWithItems(order *vo.Order, status string) error {
+	for i := range order.Items {
+		order.Items[i].ID = int64(i + 1)
+	}
+	found, updated := memOrders.updateIf(order.ID, *order, func(current vo.Order) bool {
+		return current.Status == status
+	})
+	if !found {
+		return dependency.ErrNotFound
+	}
+	if !updated {
+		return dependency.ErrConflict
+	}
+	return nil
+}
+
+// SaveStatus 订单状态仍为 change.From 时更新并追加变更记录
+func (repo *OrderMemoryRepository) SaveStatus(order *vo.Order, change *vo.OrderStatusChange) error {
+	found, updated := memOrders.updateIf(order.ID, *order, func(current vo.Order) bool {
+		return current.Status == change.From
+	})
+	if !found {
+		return dependency.ErrNotFound
+	}
+	if !updated {
+		return dependency.ErrConflict
+	}
+	memOrderStatusChanges.insert(func(id int64) vo.OrderStatusChange {
+		change.ID = id
+		return *change
+	}, nil)
+	return nil
+}
+
+// FindStatusChanges 订单的状态变更记录，按时间先后排列
+func (repo *OrderMemoryRepository) FindStatusChanges(orderID int64) ([]vo.OrderStatusChange, error) {
+	return memOrderStatusChanges.filter(func(change vo.OrderStatusChange) bool {
+		return change.OrderID == orderID
+	}), nil
+}
+
+// Stats 订单数、待处理订单数和收入，since 之后下单的另计为今日
+func (repo *OrderMemoryRepository) Stats(since time.Time) (vo.OrderStats, error) {
+	refunded := map[int64]float64{}
+	for _, refund := range memRefunds.filter(func(refund vo.Refund) bool { return refund.Status == vo.RefundCompleted }) {
+		refunded[refund.OrderID] += refund.Amount
+	}
+
+	var stats vo.OrderStats
+	for _, order := range memOrders.filter(nil) {
+		today := !order.CreatedAt.Before(since)
+		stats.Total++
+		if order.Status == vo.OrderPending {
+			stats.Pending++
+		}
+		if today {
+			stats.Today++
+		}
+		if slices.Contains(vo.OrderPaidStatuses, order.Status) {
+			revenue := order.TotalAmount - refunded[order.ID]
+			stats.Revenue += revenue
+			if today {
+				stats.TodayRevenue += revenue
+			}
+		}
+	}
+	return stats, nil
+}
+
+// TopProducts 按 SKU 汇总已售出订单的订单项，按件数降序取前 limit 个
+func (repo *OrderMemoryRepository) TopProducts(limit int) ([]vo.ProductSales, error) {
+	sales := map[string]*vo.ProductSales{}
+	for _, order := range memOrders.filter(func(order vo.Order) bool { return slices.Contains(vo.OrderSoldStatuses, order.Status) }) {
+		for _, item := range order.Items {
+			row, ok := sales[item.SKU]
+			if !ok {
+				row = &vo.ProductSales{SKU: item.SKU, ProductName: item.ProductName}
+				sales[item.SKU] = row
+			}
+			row.Quantity += int64(item.Quantity)
+			row.Amount += item.Subtotal
+		}
+	}
+
+	result := make([]vo.ProductSales, 0, len(sales))
+	for _, row := range sales {
+		result = append(result, *row)
+	}
+	slices.SortFunc(result, func(a, b vo.ProductSales) int {
+		return cmp.Or(cmp.Compare(b.Quantity, a.Quantity), cmp.Compare(b.Amount, a.Amount), strings.Compare(a.SKU, b.SKU))
+	})
+	return result[:min(limit, len(result))], nil
+}
+
+// FindPoints 在 [from, to) 内下单的订单的下单时间和计入收入的金额
+func (repo *OrderMemoryRepository) FindPoints(from, to time.Time) ([]vo.OrderPoint, error) {
+	refunded := map[int64]float64{}
+	for _, refund := range memRefunds.filter(func(refund vo.Refund) bool { return refund.Status == vo.RefundCompleted }) {
+		refunded[refund.OrderID] += refund.Amount
+	}
+
+	var points []vo.OrderPoint
+	for _, order := range memOrders.filter(nil) {
+		if order.CreatedAt.Before(from) || !order.CreatedAt.Before(to) {
+			continue
+		}
+		point := vo.OrderPoint{CreatedAt: order.CreatedAt}
+		if slices.Contains(vo.OrderPaidStatuses, order.Status) {
+			point.Revenue = order.TotalAmount - refunded[order.ID]
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// cloneOrder 深拷贝订单
+func cloneOrder(order vo.Order) vo.Order {
+	if order.Items != nil {
+		order.Items = append([]vo.OrderItem(nil), order.Items...)
+	}
+	return order
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/internal/testutil"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestOrder(t *testing.T, repo *OrderMemoryRepository, orderNo string) *vo.Order {
+	t.Helper()
+	order := &vo.Order{
+		OrderNo: orderNo,
+		Status:  vo.OrderPending,
+		Items:   []vo.OrderItem{{SKU: "RACE-ITEM", Quantity: 1, Price: 10, Subtotal: 10}},
+	}
+	if err := repo.New(order); err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestOrderMemoryConcurrentNew(t *testing.T) {
+	repo := &OrderMemoryRepository{}
+	prefix := testutil.UniqueKey("RACE-NO")
+	var created atomic.Int32
+	testutil.Parallel(30, func(i int) {
+		err := repo.New(&vo.Order{OrderNo: fmt.Sprintf("%s-%d", prefix, i%5), Status: vo.OrderPending})
+		if err == nil {
+			created.Add(1)
+		} else if !errors.Is(err, dependency.ErrDuplicate) {
+			t.Errorf("New: %v", err)
+		}
+	})
+
+	if created.Load() != 5 {
+		t.Errorf("created %d orders, want 5", created.Load())
+	}
+}
+
+func TestOrderMemoryConcurrentSaveStatus(t *testing.T) {
+	repo := &OrderMemoryRepository{}
+	order := newTestOrder(t, repo, testutil.UniqueKey("RACE-STATUS"))
+
+	// 并发地把同一个待处理订单改为已支付或已取消，只能有一个请求成功，且只记录一次状态变更
+	var won atomic.Int32
+	testutil.Parallel(20, func(i int) {
+		next := *order
+		next.Status = vo.OrderPaid
+		if i%2 == 1 {
+			next.Status = vo.OrderCancelled
+		}
+		change := &vo.OrderStatusChange{OrderID: order.ID, From: vo.OrderPending, To: next.Status, CreatedAt: time.Now()}
+		err := repo.SaveStatus(&next, change)
+		if err == nil {
+			won.Add(1)
+		} else if !errors.Is(err, dependency.ErrConflict) {
+			t.Errorf("SaveStatus: %v", err)
+		}
+	})
+
+	changes, err := repo.FindStatusChanges(order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := repo.Get(order.ID)
+	if won.Load() != 1 || len(changes) != 1 || changes[0].To != got.Status {
+		t.Errorf("won %d, changes %v, status %s; want a single transition", won.Load(), changes, got.Status)
+	}
+}
+
+func TestOrderMemoryConcurrentSaveWithItems(t *testing.T) {
+	repo := &OrderMemoryRepository{}
+	order := newTestOrder(t, repo, testutil.UniqueKey("RACE-ITEMS"))
+
+	testutil.Parallel(30, func(i int) {
+		switch i % 3 {
+		case 0:
+			next := *order
+			next.Items = []vo.OrderItem{{SKU: "RACE-ITEM", Quantity: i + 1}, {SKU: "RACE-OTHER", Quantity: 1}}
+			if err := repo.SaveWithItems(&next, vo.OrderPending); err != nil && !errors.Is(err, dependency.ErrConflict) {
+				t.Errorf("SaveWithItems: %v", err)
+			}
+		case 1:
+			if i == 1 {
+				paid := *order
+				paid.Status = vo.OrderPaid
+				change := &vo.OrderStatusChange{OrderID: order.ID, From: vo.OrderPending, To: vo.OrderPaid}
+				if err := repo.SaveStatus(&paid, change); err != nil {
+					t.Errorf("SaveStatus: %v", err)
+				}
+			}
+		default:
+			if got, err := repo.Get(order.ID); err != nil {
+				t.Errorf("Get: %v", err)
+			} else if len(got.Items) > 0 {
+				got.Items[0].Quantity = -1
+			}
+		}
+	})
+
+	got, _ := repo.Get(order.ID)
+	for _, item := range got.Items {
+		if item.Quantity <= 0 {
+			t.Errorf("order item modified through a copy: %+v", item)
+		}
+	}
+	// 支付之后的编辑必须失败
+	got.Items = nil
+	if err := repo.SaveWithItems(got, vo.OrderPending); !errors.Is(err, dependency.ErrConflict) {
+		t.Errorf("SaveWithItems on a paid order: %v, want ErrConflict", err)
+	}
+}
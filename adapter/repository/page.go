@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"cmp"
+	"godash/domain/vo"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// pageSpec 实体的分页和排序规格，GORM 和内存资源库共用
+type pageSpec[T any] struct {
+	desc  bool                        // 默认按 ID 降序
+	id    func(T) int64               // 读取 ID，用于游标和兜底排序
+	sorts map[string]func(a, b T) int // 可排序字段白名单，键同时是数据库列名
+}
+
+// 各列表的分页规格
+var (
+	userPage = pageSpec[vo.User]{
+		desc: true,
+		id:   func(user vo.User) int64 { return user.ID },
+		sorts: map[string]func(a, b vo.User) int{
+			"id":         func(a, b vo.User) int { return cmp.Compare(a.ID, b.ID) },
+			"username":   func(a, b vo.User) int { return strings.Compare(a.Username, b.Username) },
+			"real_name":  func(a, b vo.User) int { return strings.Compare(a.RealName, b.RealName) },
+			"role":       func(a, b vo.User) int { return strings.Compare(a.Role, b.Role) },
+			"status":     func(a, b vo.User) int { return strings.Compare(a.Status, b.Status) },
+			"created_at": func(a, b vo.User) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		},
+	}
+	productPage = pageSpec[vo.Product]{
+		desc: true,
+		id:   func(product vo.Product) int64 { return product.ID },
+		sorts: map[string]func(a, b vo.Product) int{
+			"id":         func(a, b vo.Product) int { return cmp.Compare(a.ID, b.ID) },
+			"name":       func(a, b vo.Product) int { return strings.Compare(a.Name, b.Name) },
+			"category":   func(a, b vo.Product) int { return strings.Compare(a.Category, b.Category) },
+			"price":      func(a, b vo.Product) int { return cmp.Compare(a.Price, b.Price) },
+			"stock":      func(a, b vo.Product) int { return cmp.Compare(a.Stock, b.Stock) },
+			"status":     func(a, b vo.Product) int { return strings.Compare(a.Status, b.Status) },
+			"created_at": func(a, b vo.Product) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		},
+	}
+	orderPage = pageSpec[vo.Order]{
+		id: func(order vo.Order) int64 { return order.ID },
+		sorts: map[string]func(a, b vo.Order) int{
+			"id":             func(a, b vo.Order) int { return cmp.Compare(a.ID, b.ID) },
+			"order_no":       func(a, b vo.Order) int { return strings.Compare(a.OrderNo, b.OrderNo) },
+			"customer_name":  func(a, b vo.Order) int { return strings.Compare(a.CustomerName, b.CustomerName) },
+			"total_amount":   func(a, b vo.Order) int { return cmp.Compare(a.TotalAmount, b.TotalAmount) },
+			"status":         func(a, b vo.Order) int { return strings.Compare(a.Status, b.Status) },
+			"payment_method": func(a, b vo.Order) int { return strings.Compare(a.PaymentMethod, b.PaymentMethod) },
+			"created_at":     func(a, b vo.Order) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		},
+	}
+	auditLogPage = pageSpec[vo.AuditLog]{
+		desc: true,
+		id:   func(log vo.AuditLog) int64 { return log.ID },
+	}
+	notificationPage = pageSpec[vo.Notification]{
+		desc: true,
+		id:   func(notification vo.Notification) int64 { return notification.ID },
+	}
+)
+
+// sortFields 白名单内的排序字段；游标模式只能按 ID 翻页，忽略排序
+func (spec pageSpec[T]) sortFields(query vo.ListQuery) vo.SortState {
+	if query.CursorMode() {
+		return nil
+	}
+	var fields vo.SortState
+	for _, field := range query.Sort {
+		if _, ok := spec.sorts[field.Field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// orderClause SQL 排序子句，最后按 ID 兜底保证翻页稳定
+func (spec pageSpec[T]) orderClause(query vo.ListQuery) string {
+	clauses := []string{}
+	for _, field := range spec.sortFields(query) {
+		clauses = append(clauses, field.Field+" "+direction(field.Desc))
+	}
+	return strings.Join(append(clauses, "id "+direction(spec.desc)), ", ")
+}
+
+// sortRows 在内存中按排序字段排序，rows 须已按 ID 升序
+func (spec pageSpec[T]) sortRows(rows []T, query vo.ListQuery) {
+	fields := spec.sortFields(query)
+	if len(fields) == 0 && !spec.desc {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, field := range fields {
+			c := spec.sorts[field.Field](rows[i], rows[j])
+			if field.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		if spec.desc {
+			return spec.id(rows[i]) > spec.id(rows[j])
+		}
+		return spec.id(rows[i]) < spec.id(rows[j])
+	})
+}
+
+// direction .
+func direction(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// cursorID 解析游标，游标为空或无效时从第一条开始
+func cursorID(query vo.ListQuery) int64 {
+	id, err := strconv.ParseInt(query.Cursor, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// findPage 把排序和分页下推到 SQL：页码模式先 COUNT 再 LIMIT/OFFSET；
+// 游标模式按 ID 键集分页，多取一条判断是否还有下一页，不统计总数。
+// db 只包含筛选条件，preloads 只作用于数据查询。
+func findPage[P any, T any](db *gorm.DB, query vo.ListQuery, spec pageSpec[T], toVO func(*P) T, preloads ...string) (vo.Page[T], error) {
+	var total int64
+	if !query.CursorMode() {
+		if err := db.Session(&gorm.Session{}).Model(new(P)).Count(&total).Error; err != nil {
+			return vo.Page[T]{}, err
+		}
+	}
+
+	find := db.Session(&gorm.Session{}).Order(spec.orderClause(query))
+	for _, preload := range preloads {
+		find = find.Preload(preload)
+	}
+	limit := query.PageSize
+	if query.CursorMode() {
+		if id := cursorID(query); id > 0 {
+			if spec.desc {
+				find = find.Where("id < ?", id)
+			} else {
+				find = find.Where("id > ?", id)
+			}
+		}
+		limit++
+	} else {
+		find = find.Offset(query.Offset())
+	}
+
+	var list []P
+	if err := find.Limit(limit).Find(&list).Error; err != nil {
+		return vo.Page[T]{}, err
+	}
+	items := make([]T, 0, len(list))
+	for i := range list {
+		items = append(items, toVO(&list[i]))
+	}
+
+	if !query.CursorMode() {
+		return vo.NewPage(items, total, query), nil
+	}
+	return spec.cursorPage(items, query), nil
+}
+
+// cursorPage 截掉多取的一条，并生成下一页游标
+func (spec pageSpec[T]) cursorPage(items []T, query vo.ListQuery) vo.Page[T] {
+	next := ""
+	if len(items) > query.PageSize {
+		items = items[:query.PageSize]
+		next = strconv.FormatInt(spec.id(items[len(items)-1]), 10)
+	}
+	return vo.NewCursorPage(items, query, next)
+}
+
+// pageRows 在内存中排序并分页，rows 须已按 ID 升序
+func (spec pageSpec[T]) pageRows(rows []T, query vo.ListQuery) vo.Page[T] {
+	spec.sortRows(rows, query)
+	if query.CursorMode() {
+		start := 0
+		if id := cursorID(query); id > 0 {
+			for start < len(rows) {
+				rowID := spec.id(rows[start])
+				if (spec.desc && rowID < id) || (!spec.desc && rowID > id) {
+					break
+				}
+				start++
+			}
+		}
+		end := start + query.PageSize + 1
+		if end > len(rows) {
+			end = len(rows)
+		}
+		return spec.cursorPage(rows[start:end], query)
+	}
+
+	start := query.Offset()
+	if start > len(rows) {
+		start = len(rows)
+	}
+	end := start + query.PageSize
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return vo.NewPage(rows[start:end], int64(len(rows)), query)
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *ProductRepository {
+			return &ProductRepository{}
+		})
+	})
+}
+
+var _ dependency.ProductRepo = (*ProductRepository)(nil)
+
+// ProductRepository 商品资源库（GORM）
+type ProductRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取商品
+func (repo *ProductRepository) Get(id int64) (*vo.Product, error) {
+	var product po.Product
+	if err := repo.db().Where("id = ?", id).Take(&product).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := product.ToVO()
+	return &result, nil
+}
+
+// FindBySKU 根据 SKU 获取商品
+func (repo *ProductRepository) FindBySKU(sku string) (*vo.Product, error) {
+	var product po.Product
+	if err := repo.db().Where("sku = ?", sku).Take(&product).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := product.ToVO()
+	return &result, nil
+}
+
+// Finds 按关键词、分类、状态和价格区间分页查询商品，默认按 ID 降序
+func (repo *ProductRepository) Finds(query vo.ListQuery, filter vo.ProductFilter) (vo.Page[vo.Product], error) {
+	db := repo.db()
+	if query.Keyword != "" {
+		like := "%" + query.Keyword + "%"
+		db = db.Where("name LIKE ? OR sku LIKE ? OR description LIKE ?", like, like, like)
+	}
+	if filter.Category != "" {
+		db = db.Where("category = ?", filter.Category)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.MinPrice > 0 {
+		db = db.Where("price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		db = db.Where("price <= ?", filter.MaxPrice)
+	}
+	return findPage(db, query, productPage, (*po.Product).ToVO)
+}
+
+// New 创建商品，回写自增 ID
+func (repo *ProductRepository) New(product *vo.Product) error {
+	obj := po.NewProduct(*product)
+	if err := repo.db().Create(obj).Error; err != nil {
+		return convertError(err)
+	}
+	product.ID = obj.ID
+	return nil
+}
+
+// NewBatch 在一个事务中批量创建商品并回写 ID，任一失败时全部回滚
+func (repo *ProductRepository) NewBatch(products []*vo.Product) error {
+	objs := make([]*po.Product, len(products))
+	for i, product := range products {
+		objs[i] = po.NewProduct(*product)
+	}
+	err := repo.db().Transaction(func(tx *gorm.DB) error {
+		for _, obj := range objs {
+			if err := tx.Create(obj).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return convertError(err)
+	}
+	for i, obj := range objs {
+		products[i].ID = obj.ID
+	}
+	return nil
+}
+
+// Save 保存商品，预留库存只由 UpdateStock 修改
+func (repo *ProductRepository) Save(product *vo.Product) error {
+	return repo.db().Omit("reserved").Save(po.NewProduct(*product)).Error
+}
+
+// UpdateStock 在一个事务内锁定 SKU 对应的商品交给 fn 修改，fn 返回 nil 时保存库存、预留和状态
+func (repo *ProductRepository) UpdateStock(skus []string, fn func(products map[string]*vo.Product) error) error {
+	return repo.db().Transaction(func(tx *gorm.DB) error {
+		var rows []po.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("sku IN ?", skus).Find(&rows).Error; err != nil {
+			return err
+		}
+		products := make(map[string]*vo.Product, len(rows))
+		for i := range rows {
+			product := rows[i].ToVO()
+			products[product.SKU] = &product
+		}
+		if err := fn(products); err != nil {
+			return err
+		}
+		for _, product := range products {
+			err := tx.Model(&po.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+				"stock":      product.Stock,
+				"reserved":   product.Reserved,
+				"status":     product.Status,
+				"updated_at": product.UpdatedAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Stats 商品总数和低库存商品数，低库存指未下架且库存减预留不超过 lowStock
+func (repo *ProductRepository) Stats(lowStock int) (vo.ProductStats, error) {
+	var stats vo.ProductStats
+	err := repo.db().Model(&po.Product{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN status <> ? AND stock - reserved <= ? THEN 1 ELSE 0 END), 0) AS low_stock",
+			"inactive", lowStock).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// Delete 删除商品
+func (repo *ProductRepository) Delete(id int64) error {
+	result := repo.db().Where("id = ?", id).Delete(&po.Product{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// db .
+func (repo *ProductRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
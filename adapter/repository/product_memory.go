@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"fmt"
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"slices"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *ProductMemoryRepository {
+			return &ProductMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.ProductRepo = (*ProductMemoryRepository)(nil)
+
+// memProducts 内存商品数据
+var memProducts = newMemoryTable[vo.Product](nil)
+
+// seedProducts 初始化商品 mock 数据（30条）
+func seedProducts() {
+	names := []string{
+		"无线蓝牙耳机", "智能手环", "机械键盘", "高清摄像头", "笔记本电脑",
+		"显示器", "鼠标垫", "USB充电器", "移动硬盘", "路由器",
+		"智能音箱", "平板电脑", "游戏手柄", "麦克风", "电脑椅",
+		"台灯", "手机支架", "数据线", "蓝牙音箱", "投影仪",
+		"扫描仪", "打印机", "绘图板", "读卡器", "散热器",
+		"电源适配器", "网线", "HDMI线", "耳机架", "桌面支架",
+	}
+	categories := []string{"电子产品", "数码配件", "办公用品", "智能设备", "电脑配件"}
+	statuses := []string{"active", "inactive", "out_of_stock"}
+
+	for i := 0; i < 30; i++ {
+		product := vo.Product{
+			ID:          int64(i + 1),
+			Name:        names[i],
+			SKU:         fmt.Sprintf("SKU%05d", i+1),
+			Category:    categories[i%len(categories)],
+			Price:       float64((i+1)*50) + 99.99,
+			Stock:       (i+1)*10 - (i % 3 * 5),
+			Status:      statuses[i%len(statuses)],
+			Image:       fmt.Sprintf("https://via.placeholder.com/300x200?text=%s", names[i]),
+			Description: fmt.Sprintf("这是一款优质的%s，性能卓越，品质保证。", names[i]),
+			CreatedAt:   time.Now().Add(-time.Duration(i) * 24 * time.Hour),
+			UpdatedAt:   time.Now().Add(-time.Duration(i) * time.Hour),
+		}
+		memProducts.seed(product.ID, product)
+	}
+}
+
+// ProductMemoryRepository 商品资源库（内存）
+type ProductMemoryRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取商品
+func (repo *ProductMemoryRepository) Get(id int64) (*vo.Product, error) {
+	product, ok := memProducts.get(id)
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &product, nil
+}
+
+// FindBySKU 根据 SKU 获取商品
+func (repo *ProductMemoryRepository) FindBySKU(sku string) (*vo.Product, error) {
+	product, ok := memProducts.find(func(product vo.Product) bool {
+		return product.SKU == sku
+	})
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &product, nil
+}
+
+// Finds 按关键词、分类、状态和价格区间分页查询商品，默认按 ID 降序
+func (repo *ProductMemoryRepository) Finds(query vo.ListQuery, filter vo.ProductFilter) (vo.Page[vo.Product], error) {
+	result := memProducts.filter(func(product vo.Product) bool {
+		if !matchKeyword(query.Keyword, product.Name, product.SKU, product.Description) {
+			return false
+		}
+		if filter.Category != "" && product.Category != filter.Category {
+			return false
+		}
+		if filter.Status != "" && product.Status != filter.Status {
+			return false
+		}
+		return inRange(product.Price, filter.MinPrice, filter.MaxPrice)
+	})
+	return productPage.pageRows(result, query), nil
+}
+
+// New 创建商品，分配 ID，SKU 重复时返回 ErrDuplicate
+func (repo *ProductMemoryRepository) New(product *vo.Product) error {
+	row, ok := memProducts.insert(func(id int64) vo.Product {
+		product.ID = id
+		return *product
+	}, func(existing vo.Product) bool {
+		return existing.SKU == product.SKU
+	})
+	if !ok {
+		return dependency.ErrDuplicate
+	}
+	*product = row
+	return nil
+}
+
+// NewBatch 批量创建商品并分配 ID，任一 SKU 已存在时全部不写入
+func (repo *ProductMemoryRepository) NewBatch(products []*vo.Product) error {
+	ok := memProducts.insertAll(len(products), func(i int, id int64) vo.Product {
+		products[i].ID = id
+		return *products[i]
+	}, func(i int, existing vo.Product) bool {
+		return existing.SKU == products[i].SKU
+	})
+	if !ok {
+		return dependency.ErrDuplicate
+	}
+	return nil
+}
+
+// Save 保存商品
+func (repo *ProductMemoryRepository) Save(product *vo.Product) error {
+	if !memProducts.update(product.ID, *product) {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateStock 在一次加锁内把 SKU 对应的商品交给 fn 修改，fn 返回 nil 时写回
+func (repo *ProductMemoryRepository) UpdateStock(skus []string, fn func(products map[string]*vo.Product) error) error {
+	return memProducts.mutate(func(product vo.Product) bool {
+		return slices.Contains(skus, product.SKU)
+	}, func(rows map[int64]*vo.Product) error {
+		products := make(map[string]*vo.Product, len(rows))
+		for _, product := range rows {
+			products[product.SKU] = product
+		}
+		return fn(products)
+	})
+}
+
+// Stats 商品总数和低库存商品数，低库存指未下架且库存减预留不超过 lowStock
+func (repo *ProductMemoryRepository) Stats(lowStock int) (vo.ProductStats, error) {
+	products := memProducts.filter(nil)
+	stats := vo.ProductStats{Total: int64(len(products))}
+	for _, product := range products {
+		if product.Status != "inactive" && product.Available() <= lowStock {
+			stats.LowStock++
+		}
+	}
+	return stats, nil
+}
+
+// Delete 删除商品
+func (repo *ProductMemoryRepository) Delete(id int64) error {
+	if !memProducts.remove(id) {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
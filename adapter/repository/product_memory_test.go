@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/internal/testutil"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProductMemoryConcurrentNew(t *testing.T) {
+	repo := &ProductMemoryRepository{}
+	prefix := testutil.UniqueKey("RACE-NEW")
+	var created, duplicate atomic.Int32
+	testutil.Parallel(40, func(i int) {
+		err := repo.New(&vo.Product{Name: "并发商品", SKU: fmt.Sprintf("%s-%d", prefix, i%4), Stock: 1, Status: "active"})
+		switch {
+		case err == nil:
+			created.Add(1)
+		case errors.Is(err, dependency.ErrDuplicate):
+			duplicate.Add(1)
+		default:
+			t.Errorf("New: %v", err)
+		}
+	})
+
+	if created.Load() != 4 || duplicate.Load() != 36 {
+		t.Errorf("created %d, duplicate %d; want 4 and 36", created.Load(), duplicate.Load())
+	}
+}
+
+func TestProductMemoryConcurrentSaveDelete(t *testing.T) {
+	repo := &ProductMemoryRepository{}
+	prefix := testutil.UniqueKey("RACE-SAVE")
+	products := make([]*vo.Product, 10)
+	for i := range products {
+		products[i] = &vo.Product{Name: "待修改", SKU: fmt.Sprintf("%s-%d", prefix, i), Stock: 5, Status: "active"}
+		if err := repo.New(products[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testutil.Parallel(60, func(i int) {
+		product := *products[i%len(products)]
+		switch i % 3 {
+		case 0:
+			product.Name = fmt.Sprintf("修改 %d", i)
+			if err := repo.Save(&product); err != nil && !errors.Is(err, dependency.ErrNotFound) {
+				t.Errorf("Save: %v", err)
+			}
+		case 1:
+			if product.ID%2 == 0 {
+				if err := repo.Delete(product.ID); err != nil && !errors.Is(err, dependency.ErrNotFound) {
+					t.Errorf("Delete: %v", err)
+				}
+			}
+		default:
+			if _, err := repo.Get(product.ID); err != nil && !errors.Is(err, dependency.ErrNotFound) {
+				t.Errorf("Get: %v", err)
+			}
+			if _, err := repo.Finds(vo.ListQuery{Keyword: prefix, Page: 1, PageSize: 20}, vo.ProductFilter{}); err != nil {
+				t.Errorf("Finds: %v", err)
+			}
+		}
+	})
+
+	for _, product := range products {
+		_, err := repo.Get(product.ID)
+		if deleted := product.ID%2 == 0; deleted != errors.Is(err, dependency.ErrNotFound) {
+			t.Errorf("product %d: deleted %v, Get error %v", product.ID, deleted, err)
+		}
+	}
+}
+
+func TestProductMemoryConcurrentUpdateStock(t *testing.T) {
+	repo := &ProductMemoryRepository{}
+	product := &vo.Product{Name: "库存", SKU: testutil.UniqueKey("RACE-STOCK"), Stock: 100, Status: "active"}
+	if err := repo.New(product); err != nil {
+		t.Fatal(err)
+	}
+
+	// 120 个请求各预留一件，库存只有 100 件，多出的请求必须失败且不能超卖
+	var reserved atomic.Int32
+	testutil.Parallel(120, func(int) {
+		err := repo.UpdateStock([]string{product.SKU}, func(products map[string]*vo.Product) error {
+			p := products[product.SKU]
+			if p.Available() < 1 {
+				return errors.New("库存不足")
+			}
+			p.Reserved++
+			p.SyncStockStatus()
+			return nil
+		})
+		if err == nil {
+			reserved.Add(1)
+		}
+	})
+
+	got, err := repo.Get(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reserved.Load() != 100 || got.Reserved != 100 || got.Status != "out_of_stock" {
+		t.Errorf("reserved %d, product reserved %d status %s; want 100, 100, out_of_stock",
+			reserved.Load(), got.Reserved, got.Status)
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *RefundRepository {
+			return &RefundRepository{}
+		})
+	})
+}
+
+var _ dependency.RefundRepo = (*RefundRepository)(nil)
+
+// RefundRepository 退款资源库（GORM）
+type RefundRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取退款及退款项
+func (repo *RefundRepository) Get(id int64) (*vo.Refund, error) {
+	var refund po.Refund
+	if err := repo.db().Preload("Items").Where("id = ?", id).Take(&refund).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := refund.ToVO()
+	return &result, nil
+}
+
+// FindByOrder 订单的退款记录，按申请时间先后排列
+func (repo *RefundRepository) FindByOrder(orderID int64) ([]vo.Refund, error) {
+	var rows []po.Refund
+	if err := repo.db().Preload("Items").Where("order_id = ?", orderID).Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make([]vo.Refund, 0, len(rows))
+	for i := range rows {
+		result = append(result, rows[i].ToVO())
+	}
+	return result, nil
+}
+
+// New 创建退款及退款项，回写 ID
+func (repo *RefundRepository) New(refund *vo.Refund) error {
+	obj := po.NewRefund(*refund)
+	if err := repo.db().Create(obj).Error; err != nil {
+		return convertError(err)
+	}
+	*refund = obj.ToVO()
+	return nil
+}
+
+// SaveStatus 按原状态条件更新退款状态
+func (repo *RefundRepository) SaveStatus(refund *vo.Refund, from string) error {
+	result := repo.db().Model(&po.Refund{}).Where("id = ? AND status = ?", refund.ID, from).
+		Updates(map[string]interface{}{"status": refund.Status, "updated_at": refund.UpdatedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dependency.ErrConflict
+	}
+	return nil
+}
+
+// UpdateByOrder 在一个事务内锁定订单的全部退款交给 fn 修改，fn 返回 nil 时保存状态
+func (repo *RefundRepository) UpdateByOrder(orderID int64, fn func(refunds map[int64]*vo.Refund) error) error {
+	return repo.db().Transaction(func(tx *gorm.DB) error {
+		var rows []po.Refund
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").Where("order_id = ?", orderID).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		refunds := make(map[int64]*vo.Refund, len(rows))
+		for i := range rows {
+			refund := rows[i].ToVO()
+			refunds[refund.ID] = &refund
+		}
+		if err := fn(refunds); err != nil {
+			return err
+		}
+		for _, refund := range refunds {
+			err := tx.Model(&po.Refund{}).Where("id = ?", refund.ID).
+				Updates(map[string]interface{}{"status": refund.Status, "updated_at": refund.UpdatedAt}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// db .
+func (repo *RefundRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
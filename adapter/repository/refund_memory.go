@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *RefundMemoryRepository {
+			return &RefundMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.RefundRepo = (*RefundMemoryRepository)(nil)
+
+// memRefunds 内存退款数据，退款项切片在读写时深拷贝
+var memRefunds = newMemoryTable(cloneRefund)
+
+// cloneRefund 深拷贝退款
+func cloneRefund(refund vo.Refund) vo.Refund {
+	if refund.Items != nil {
+		refund.Items = append([]vo.RefundItem(nil), refund.Items...)
+	}
+	return refund
+}
+
+// RefundMemoryRepository 退款资源库（内存）
+type RefundMemoryRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取退款
+func (repo *RefundMemoryRepository) Get(id int64) (*vo.Refund, error) {
+	refund, ok := memRefunds.get(id)
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &refund, nil
+}
+
+// FindByOrder 订单的退款记录，按申请时间先后排列
+func (repo *RefundMemoryRepository) FindByOrder(orderID int64) ([]vo.Refund, error) {
+	return memRefunds.filter(func(refund vo.Refund) bool {
+		return refund.OrderID == orderID
+	}), nil
+}
+
+// New 创建退款，分配退款和退款项 ID
+func (repo *RefundMemoryRepository) New(refund *vo.Refund) error {
+	row, _ := memRefunds.insert(func(id int64) vo.Refund {
+		refund.ID = id
+		for i := range refund.Items {
+			refund.Items[i].ID = int64(i + 1)
+		}
+		return *refund
+	}, nil)
+	*refund = row
+	return nil
+}
+
+// SaveStatus 退款状态仍为 from 时保存
+func (repo *RefundMemoryRepository) SaveStatus(refund *vo.Refund, from string) error {
+	found, updated := memRefunds.updateIf(refund.ID, *refund, func(current vo.Refund) bool {
+		return current.Status == from
+	})
+	if !found {
+		return dependency.ErrNotFound
+	}
+	if !updated {
+		return dependency.ErrConflict
+	}
+	return nil
+}
+
+// UpdateByOrder 在一次加锁内把订单的全部退款交给 fn 修改
+func (repo *RefundMemoryRepository) UpdateByOrder(orderID int64, fn func(refunds map[int64]*vo.Refund) error) error {
+	return memRefunds.mutate(func(refund vo.Refund) bool {
+		return refund.OrderID == orderID
+	}, fn)
+}
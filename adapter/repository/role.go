@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *RoleRepository {
+			return &RoleRepository{}
+		})
+	})
+}
+
+var _ dependency.RoleRepo = (*RoleRepository)(nil)
+
+// RoleRepository 角色资源库（GORM）
+type RoleRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取角色
+func (repo *RoleRepository) Get(id int64) (*vo.Role, error) {
+	var role po.Role
+	if err := repo.db().Where("id = ?", id).Take(&role).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := role.ToVO()
+	return &result, nil
+}
+
+// FindByCode 根据角色标识获取角色
+func (repo *RoleRepository) FindByCode(code string) (*vo.Role, error) {
+	var role po.Role
+	if err := repo.db().Where("code = ?", code).Take(&role).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := role.ToVO()
+	return &result, nil
+}
+
+// Finds 查询全部角色，按 ID 升序
+func (repo *RoleRepository) Finds() ([]vo.Role, error) {
+	var list []po.Role
+	if err := repo.db().Order("id ASC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	result := make([]vo.Role, 0, len(list))
+	for i := range list {
+		result = append(result, list[i].ToVO())
+	}
+	return result, nil
+}
+
+// New 创建角色，回写自增 ID
+func (repo *RoleRepository) New(role *vo.Role) error {
+	obj := po.NewRole(*role)
+	if err := repo.db().Create(obj).Error; err != nil {
+		return convertError(err)
+	}
+	role.ID = obj.ID
+	return nil
+}
+
+// Save 保存角色
+func (repo *RoleRepository) Save(role *vo.Role) error {
+	return repo.db().Save(po.NewRole(*role)).Error
+}
+
+// Delete 删除角色
+func (repo *RoleRepository) Delete(id int64) error {
+	result := repo.db().Where("id = ?", id).Delete(&po.Role{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// db .
+func (repo *RoleRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
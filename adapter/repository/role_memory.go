@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *RoleMemoryRepository {
+			return &RoleMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.RoleRepo = (*RoleMemoryRepository)(nil)
+
+// memRoles 内存角色数据，内置角色由领域服务在启动时创建
+var memRoles = newMemoryTable(cloneRole)
+
+// cloneRole 复制角色，权限切片不与表内数据共享
+func cloneRole(role vo.Role) vo.Role {
+	role.Permissions = append([]string(nil), role.Permissions...)
+	return role
+}
+
+// RoleMemoryRepository 角色资源库（内存）
+type RoleMemoryRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取角色
+func (repo *RoleMemoryRepository) Get(id int64) (*vo.Role, error) {
+	role, ok := memRoles.get(id)
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &role, nil
+}
+
+// FindByCode 根据角色标识获取角色
+func (repo *RoleMemoryRepository) FindByCode(code string) (*vo.Role, error) {
+	role, ok := memRoles.find(func(role vo.Role) bool {
+		return role.Code == code
+	})
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &role, nil
+}
+
+// Finds 查询全部角色，按 ID 升序
+func (repo *RoleMemoryRepository) Finds() ([]vo.Role, error) {
+	return memRoles.filter(nil), nil
+}
+
+// New 创建角色，分配 ID，角色标识重复时返回 ErrDuplicate
+func (repo *RoleMemoryRepository) New(role *vo.Role) error {
+	row, ok := memRoles.insert(func(id int64) vo.Role {
+		role.ID = id
+		return *role
+	}, func(existing vo.Role) bool {
+		return existing.Code == role.Code
+	})
+	if !ok {
+		return dependency.ErrDuplicate
+	}
+	*role = row
+	return nil
+}
+
+// Save 保存角色
+func (repo *RoleMemoryRepository) Save(role *vo.Role) error {
+	if !memRoles.update(role.ID, *role) {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// Delete 删除角色
+func (repo *RoleMemoryRepository) Delete(id int64) error {
+	if !memRoles.remove(id) {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
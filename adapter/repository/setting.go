@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *SettingRepository {
+			return &SettingRepository{}
+		})
+	})
+}
+
+var _ dependency.SettingRepo = (*SettingRepository)(nil)
+
+// SettingRepository 系统设置资源库（GORM）
+type SettingRepository struct {
+	freedom.Repository
+}
+
+// Latest 最新的设置版本
+func (repo *SettingRepository) Latest() (*vo.SettingsVersion, error) {
+	var obj po.SettingVersion
+	if err := repo.db().Order("version DESC").First(&obj).Error; err != nil {
+		return nil, convertError(err)
+	}
+	version, err := obj.ToVO()
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// Get 读取指定版本
+func (repo *SettingRepository) Get(version int) (*vo.SettingsVersion, error) {
+	var obj po.SettingVersion
+	if err := repo.db().Where("version = ?", version).First(&obj).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result, err := obj.ToVO()
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Finds 按版本号降序分页；表的主键是版本号而不是 id，不走通用的 findPage
+func (repo *SettingRepository) Finds(query vo.ListQuery) (vo.Page[vo.SettingsVersion], error) {
+	var total int64
+	if err := repo.db().Model(&po.SettingVersion{}).Count(&total).Error; err != nil {
+		return vo.Page[vo.SettingsVersion]{}, err
+	}
+	var list []po.SettingVersion
+	err := repo.db().Order("version DESC").Offset(query.Offset()).Limit(query.PageSize).Find(&list).Error
+	if err != nil {
+		return vo.Page[vo.SettingsVersion]{}, err
+	}
+	items := make([]vo.SettingsVersion, 0, len(list))
+	for i := range list {
+		version, err := list[i].ToVO()
+		if err != nil {
+			return vo.Page[vo.SettingsVersion]{}, err
+		}
+		items = append(items, version)
+	}
+	return vo.NewPage(items, total, query), nil
+}
+
+// New 追加设置版本，版本号是主键，并发保存同一版本时只有一个成功
+func (repo *SettingRepository) New(version *vo.SettingsVersion) error {
+	obj, err := po.NewSettingVersion(*version)
+	if err != nil {
+		return err
+	}
+	return convertError(repo.db().Create(obj).Error)
+}
+
+// db .
+func (repo *SettingRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
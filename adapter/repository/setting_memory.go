@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *SettingMemoryRepository {
+			return &SettingMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.SettingRepo = (*SettingMemoryRepository)(nil)
+
+// memSettings 内存模式的设置版本，配置了 settings_file 时同步写入该 JSON 文件，重启后从文件恢复
+var memSettings settingFile
+
+// settingFile 保存在 JSON 文件中的设置版本，首次访问时加载
+type settingFile struct {
+	mu       sync.Mutex
+	loaded   bool
+	versions []vo.SettingsVersion
+}
+
+// load 首次访问时读取文件，文件不存在视为从未保存；调用方持有锁
+func (f *settingFile) load() error {
+	if f.loaded {
+		return nil
+	}
+	if path := config.Get().DB.SettingsFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &f.versions); err != nil {
+				return err
+			}
+		}
+	}
+	f.loaded = true
+	return nil
+}
+
+// write 先写临时文件再重命名，避免写到一半时进程退出损坏已有设置；调用方持有锁
+func (f *settingFile) write(versions []vo.SettingsVersion) error {
+	path := config.Get().DB.SettingsFile
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SettingMemoryRepository 系统设置资源库（内存，可选落盘到 JSON 文件）
+type SettingMemoryRepository struct {
+	freedom.Repository
+}
+
+// Latest 最新的设置版本
+func (repo *SettingMemoryRepository) Latest() (*vo.SettingsVersion, error) {
+	memSettings.mu.Lock()
+	defer memSettings.mu.Unlock()
+	if err := memSettings.load(); err != nil {
+		return nil, err
+	}
+	if len(memSettings.versions) == 0 {
+		return nil, dependency.ErrNotFound
+	}
+	latest := memSettings.versions[len(memSettings.versions)-1]
+	return &latest, nil
+}
+
+// Get 读取指定版本
+func (repo *SettingMemoryRepository) Get(version int) (*vo.SettingsVersion, error) {
+	memSettings.mu.Lock()
+	defer memSettings.mu.Unlock()
+	if err := memSettings.load(); err != nil {
+		return nil, err
+	}
+	for _, item := range memSettings.versions {
+		if item.Version == version {
+			return &item, nil
+		}
+	}
+	return nil, dependency.ErrNotFound
+}
+
+// Finds 按版本号降序分页
+func (repo *SettingMemoryRepository) Finds(query vo.ListQuery) (vo.Page[vo.SettingsVersion], error) {
+	memSettings.mu.Lock()
+	defer memSettings.mu.Unlock()
+	if err := memSettings.load(); err != nil {
+		return vo.Page[vo.SettingsVersion]{}, err
+	}
+	total := len(memSettings.versions)
+	items := []vo.SettingsVersion{}
+	for i := total - 1 - query.Offset(); i >= 0 && len(items) < query.PageSize; i-- {
+		items = append(items, memSettings.versions[i])
+	}
+	return vo.NewPage(items, int64(total), query), nil
+}
+
+// New 追加设置版本，写文件失败时不修改内存中的版本
+func (repo *SettingMemoryRepository) New(version *vo.SettingsVersion) error {
+	memSettings.mu.Lock()
+	defer memSettings.mu.Unlock()
+	if err := memSettings.load(); err != nil {
+		return err
+	}
+	if n := len(memSettings.versions); n > 0 && memSettings.versions[n-1].Version >= version.Version {
+		return dependency.ErrDuplicate
+	}
+	versions := append(memSettings.versions[:len(memSettings.versions):len(memSettings.versions)], *version)
+	if err := memSettings.write(versions); err != nil {
+		return err
+	}
+	memSettings.versions = versions
+	return nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"errors"
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/po"
+	"godash/domain/vo"
+	"time"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *UserRepository {
+			return &UserRepository{}
+		})
+	})
+}
+
+var _ dependency.UserRepo = (*UserRepository)(nil)
+
+// UserRepository 用户资源库（GORM）
+type UserRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取用户
+func (repo *UserRepository) Get(id int64) (*vo.User, error) {
+	var user po.User
+	if err := repo.db().Where("id = ?", id).Take(&user).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := user.ToVO()
+	return &result, nil
+}
+
+// FindByUsername 根据用户名获取用户
+func (repo *UserRepository) FindByUsername(username string) (*vo.User, error) {
+	var user po.User
+	if err := repo.db().Where("username = ?", username).Take(&user).Error; err != nil {
+		return nil, convertError(err)
+	}
+	result := user.ToVO()
+	return &result, nil
+}
+
+// Finds 按关键词、角色和状态分页查询用户，默认按 ID 降序
+func (repo *UserRepository) Finds(query vo.ListQuery, filter vo.UserFilter) (vo.Page[vo.User], error) {
+	db := repo.db()
+	if query.Keyword != "" {
+		like := "%" + query.Keyword + "%"
+		db = db.Where("username LIKE ? OR email LIKE ? OR real_name LIKE ?", like, like, like)
+	}
+	if filter.Role != "" {
+		db = db.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	return findPage(db, query, userPage, (*po.User).ToVO)
+}
+
+// CountByRole 统计每个角色的用户数
+func (repo *UserRepository) CountByRole() (map[string]int, error) {
+	var rows []struct {
+		Role  string
+		Count int
+	}
+	if err := repo.db().Model(&po.User{}).Select("role, COUNT(*) AS count").Group("role").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Role] = row.Count
+	}
+	return counts, nil
+}
+
+// CountByStatus 统计每个状态的用户数
+func (repo *UserRepository) CountByStatus() (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	if err := repo.db().Model(&po.User{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// FindCreatedTimes 在 [from, to) 内注册的用户的注册时间
+func (repo *UserRepository) FindCreatedTimes(from, to time.Time) ([]time.Time, error) {
+	var times []time.Time
+	err := repo.db().Model(&po.User{}).Where("created_at >= ? AND created_at < ?", from, to).Pluck("created_at", &times).Error
+	return times, err
+}
+
+// FindActiveIDs 角色在 roles 中且状态正常的用户 ID
+func (repo *UserRepository) FindActiveIDs(roles []string) ([]int64, error) {
+	var ids []int64
+	if len(roles) == 0 {
+		return ids, nil
+	}
+	err := repo.db().Model(&po.User{}).Where("role IN ? AND status = ?", roles, "active").Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// New 创建用户，回写自增 ID
+func (repo *UserRepository) New(user *vo.User) error {
+	obj := po.NewUser(*user)
+	if err := repo.db().Create(obj).Error; err != nil {
+		return convertError(err)
+	}
+	user.ID = obj.ID
+	return nil
+}
+
+// Save 保存用户
+func (repo *UserRepository) Save(user *vo.User) error {
+	return repo.db().Save(po.NewUser(*user)).Error
+}
+
+// Delete 删除用户
+func (repo *UserRepository) Delete(id int64) error {
+	result := repo.db().Where("id = ?", id).Delete(&po.User{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// db .
+func (repo *UserRepository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// convertError 将 GORM 的错误转换为领域错误
+func convertError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dependency.ErrNotFound
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return dependency.ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"fmt"
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"slices"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver != config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *UserMemoryRepository {
+			return &UserMemoryRepository{}
+		})
+	})
+}
+
+var _ dependency.UserRepo = (*UserMemoryRepository)(nil)
+
+// memUsers 内存用户数据
+var memUsers = newMemoryTable[vo.User](nil)
+
+// init 初始化用户 mock 数据（30条）
+func init() {
+	names := []string{"张伟", "王芳", "李娜", "刘洋", "陈静", "杨军", "赵敏", "孙涛", "周杰", "吴彦祖",
+		"郑爽", "黄晓明", "林志玲", "范冰冰", "李冰冰", "章子怡", "周润发", "刘德华", "张国荣", "梁朝伟",
+		"赵本山", "小沈阳", "宋丹丹", "蔡明", "潘长江", "郭德纲", "于谦", "岳云鹏", "孙越", "张云雷"}
+	roles := []string{"admin", "editor", "viewer"}
+	statuses := []string{"active", "inactive"}
+
+	for i := 0; i < 30; i++ {
+		user := vo.User{
+			ID:        int64(i + 1),
+			Username:  fmt.Sprintf("user%d", i+1),
+			Email:     fmt.Sprintf("user%d@example.com", i+1),
+			RealName:  names[i],
+			Phone:     fmt.Sprintf("138%08d", i+1),
+			Role:      roles[i%len(roles)],
+			Status:    statuses[i%len(statuses)],
+			Avatar:    "/static/images/zxg.jpg",
+			CreatedAt: time.Now().Add(-time.Duration(i) * 24 * time.Hour),
+			UpdatedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+		}
+		memUsers.seed(user.ID, user)
+	}
+}
+
+// UserMemoryRepository 用户资源库（内存）
+type UserMemoryRepository struct {
+	freedom.Repository
+}
+
+// Get 根据 ID 获取用户
+func (repo *UserMemoryRepository) Get(id int64) (*vo.User, error) {
+	user, ok := memUsers.get(id)
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &user, nil
+}
+
+// FindByUsername 根据用户名获取用户
+func (repo *UserMemoryRepository) FindByUsername(username string) (*vo.User, error) {
+	user, ok := memUsers.find(func(user vo.User) bool {
+		return user.Username == username
+	})
+	if !ok {
+		return nil, dependency.ErrNotFound
+	}
+	return &user, nil
+}
+
+// Finds 按关键词、角色和状态分页查询用户，默认按 ID 降序
+func (repo *UserMemoryRepository) Finds(query vo.ListQuery, filter vo.UserFilter) (vo.Page[vo.User], error) {
+	result := memUsers.filter(func(user vo.User) bool {
+		if !matchKeyword(query.Keyword, user.Username, user.Email, user.RealName) {
+			return false
+		}
+		return (filter.Role == "" || user.Role == filter.Role) &&
+			(filter.Status == "" || user.Status == filter.Status)
+	})
+	return userPage.pageRows(result, query), nil
+}
+
+// CountByRole 统计每个角色的用户数
+func (repo *UserMemoryRepository) CountByRole() (map[string]int, error) {
+	counts := map[string]int{}
+	for _, user := range memUsers.filter(nil) {
+		counts[user.Role]++
+	}
+	return counts, nil
+}
+
+// CountByStatus 统计每个状态的用户数
+func (repo *UserMemoryRepository) CountByStatus() (map[string]int, error) {
+	counts := map[string]int{}
+	for _, user := range memUsers.filter(nil) {
+		counts[user.Status]++
+	}
+	return counts, nil
+}
+
+// FindCreatedTimes 在 [from, to) 内注册的用户的注册时间
+func (repo *UserMemoryRepository) FindCreatedTimes(from, to time.Time) ([]time.Time, error) {
+	var times []time.Time
+	for _, user := range memUsers.filter(nil) {
+		if !user.CreatedAt.Before(from) && user.CreatedAt.Before(to) {
+			times = append(times, user.CreatedAt)
+		}
+	}
+	return times, nil
+}
+
+// FindActiveIDs 角色在 roles 中且状态正常的用户 ID
+func (repo *UserMemoryRepository) FindActiveIDs(roles []string) ([]int64, error) {
+	var ids []int64
+	for _, user := range memUsers.filter(nil) {
+		if user.Status == "active" && slices.Contains(roles, user.Role) {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+// New 创建用户，分配 ID，用户名重复时返回 ErrDuplicate
+func (repo *UserMemoryRepository) New(user *vo.User) error {
+	row, ok := memUsers.insert(func(id int64) vo.User {
+		user.ID = id
+		return *user
+	}, func(existing vo.User) bool {
+		return existing.Username == user.Username
+	})
+	if !ok {
+		return dependency.ErrDuplicate
+	}
+	*user = row
+	return nil
+}
+
+// Save 保存用户
+func (repo *UserMemoryRepository) Save(user *vo.User) error {
+	if !memUsers.update(user.ID, *user) {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// Delete 删除用户
+func (repo *UserMemoryRepository) Delete(id int64) error {
+	if !memUsers.remove(id) {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
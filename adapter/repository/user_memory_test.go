@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/internal/testutil"
+	"sync/atomic"
+	"testing"
+)
+
+func TestUserMemoryConcurrentNew(t *testing.T) {
+	repo := &UserMemoryRepository{}
+	prefix := testutil.UniqueKey("race-user")
+	var created atomic.Int32
+	testutil.Parallel(30, func(i int) {
+		err := repo.New(&vo.User{Username: fmt.Sprintf("%s-%d", prefix, i%3), Role: "viewer", Status: "active"})
+		if err == nil {
+			created.Add(1)
+		} else if !errors.Is(err, dependency.ErrDuplicate) {
+			t.Errorf("New: %v", err)
+		}
+	})
+
+	if created.Load() != 3 {
+		t.Errorf("created %d users, want 3", created.Load())
+	}
+}
+
+func TestUserMemoryConcurrentSaveDelete(t *testing.T) {
+	repo := &UserMemoryRepository{}
+	prefix := testutil.UniqueKey("race-save")
+	users := make([]*vo.User, 6)
+	for i := range users {
+		users[i] = &vo.User{Username: fmt.Sprintf("%s-%d", prefix, i), Role: "viewer", Status: "active"}
+		if err := repo.New(users[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 每个用户都会被并发地保存、删除（前 3 个）和查询
+	testutil.Parallel(60, func(i int) {
+		user := *users[i%len(users)]
+		switch i / len(users) % 4 {
+		case 0:
+			user.Status = "banned"
+			if err := repo.Save(&user); err != nil && !errors.Is(err, dependency.ErrNotFound) {
+				t.Errorf("Save: %v", err)
+			}
+		case 1:
+			if i%len(users) < 3 {
+				if err := repo.Delete(user.ID); err != nil && !errors.Is(err, dependency.ErrNotFound) {
+					t.Errorf("Delete: %v", err)
+				}
+			}
+		case 2:
+			if _, err := repo.FindByUsername(user.Username); err != nil && !errors.Is(err, dependency.ErrNotFound) {
+				t.Errorf("FindByUsername: %v", err)
+			}
+		default:
+			if _, err := repo.CountByStatus(); err != nil {
+				t.Errorf("CountByStatus: %v", err)
+			}
+			if _, err := repo.FindActiveIDs([]string{"viewer"}); err != nil {
+				t.Errorf("FindActiveIDs: %v", err)
+			}
+		}
+	})
+
+	for i, user := range users {
+		got, err := repo.Get(user.ID)
+		if i < 3 {
+			if !errors.Is(err, dependency.ErrNotFound) {
+				t.Errorf("user %d should be deleted, got %v", user.ID, err)
+			}
+			continue
+		}
+		if err != nil || got.Status != "banned" {
+			t.Errorf("user %d: status %v, err %v; want banned", user.ID, got, err)
+		}
+	}
+}
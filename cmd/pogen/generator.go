@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	genSuffix = "_gen.go"
+	genHeader = "// Code generated by pogen from schema.json. DO NOT EDIT."
+)
+
+type generator struct {
+	poDir    string
+	repoDir  string
+	poImport string
+}
+
+// run 清理上一次生成的文件后重新生成，schema 中删除的表不会遗留旧文件
+func (g *generator) run(tables []Table) error {
+	for _, dir := range []string{g.poDir, g.repoDir} {
+		if err := removeGenerated(dir); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range tables {
+		name := strings.ToLower(table.Name) + genSuffix
+		if err := g.write(filepath.Join(g.poDir, name), poTemplate, table); err != nil {
+			return err
+		}
+		if err := g.write(filepath.Join(g.repoDir, name), repoTemplate, table); err != nil {
+			return err
+		}
+	}
+	return g.write(filepath.Join(g.poDir, "models"+genSuffix), modelsTemplate, tables)
+}
+
+func (g *generator) write(file string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteString(genHeader + "\n\n")
+	if err := tmpl.Execute(&buf, map[string]interface{}{"Data": data, "POImport": g.poImport}); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %w\n%s", file, err, buf.Bytes())
+	}
+	return os.WriteFile(file, src, 0o644)
+}
+
+// removeGenerated 只删除带有生成标记的文件，避免误删手写代码
+func removeGenerated(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+genSuffix))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		ok, err := isGenerated(file)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isGenerated(file string) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return false, nil
+	}
+	return strings.TrimSpace(line) == genHeader, nil
+}
+
+func hasTime(table Table) bool {
+	for _, c := range table.Columns {
+		if c.GoType == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasNumeric(table Table) bool {
+	for _, c := range table.Columns {
+		if !c.Primary && c.Numeric() {
+			return true
+		}
+	}
+	return false
+}
+
+var funcs = template.FuncMap{
+	"hasTime":    hasTime,
+	"hasNumeric": hasNumeric,
+}
+
+var poTemplate = template.Must(template.New("po").Funcs(funcs).Parse(`package po
+{{with .Data}}
+import (
+{{- if hasNumeric .}}
+	"gorm.io/gorm"
+{{- end}}
+{{- if hasTime .}}
+	"time"
+{{- end}}
+)
+
+// {{.StructName}} .
+type {{.StructName}} struct {
+	changes map[string]interface{}
+{{- range .Columns}}
+	{{.FieldName}} {{.GoType}} ` + "`" + `gorm:"{{if .Primary}}primaryKey;{{end}}column:{{.Name}}"` + "`" + `
+{{- end}}
+}
+
+// TableName .
+func (obj *{{.StructName}}) TableName() string {
+	return "{{.Name}}"
+}
+
+// Location .
+func (obj *{{.StructName}}) Location() map[string]interface{} {
+	return map[string]interface{}{"{{.PrimaryKey.Name}}": obj.{{.PrimaryKey.FieldName}}}
+}
+
+// GetChanges .
+func (obj *{{.StructName}}) GetChanges() map[string]interface{} {
+	if obj.changes == nil {
+		return nil
+	}
+	result := make(map[string]interface{})
+	for k, v := range obj.changes {
+		result[k] = v
+	}
+	obj.changes = nil
+	return result
+}
+
+// Update .
+func (obj *{{.StructName}}) Update(name string, value interface{}) {
+	if obj.changes == nil {
+		obj.changes = make(map[string]interface{})
+	}
+	obj.changes[name] = value
+}
+{{$struct := .StructName}}
+{{- range .Columns}}{{if not .Primary}}
+// Set{{.FieldName}} .
+func (obj *{{$struct}}) Set{{.FieldName}}({{.ParamName}} {{.GoType}}) {
+	obj.{{.FieldName}} = {{.ParamName}}
+	obj.Update("{{.Name}}", {{.ParamName}})
+}
+{{end}}{{end}}
+{{- range .Columns}}{{if and (not .Primary) .Numeric}}
+// Add{{.FieldName}} .
+func (obj *{{$struct}}) Add{{.FieldName}}({{.ParamName}} {{.GoType}}) {
+	obj.{{.FieldName}} += {{.ParamName}}
+	obj.Update("{{.Name}}", gorm.Expr("{{.Name}} + ?", {{.ParamName}}))
+}
+{{end}}{{end}}
+{{- end}}`))
+
+var repoTemplate = template.Must(template.New("repo").Funcs(funcs).Parse(`package repository
+{{with .Data}}
+import (
+	"godash/config"
+	"godash/domain/dependency"
+	"{{$.POImport}}"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		if config.Get().DB.Driver == config.DriverMemory {
+			return
+		}
+		initiator.BindRepository(func() *{{.StructName}}Repository {
+			return &{{.StructName}}Repository{}
+		})
+	})
+}
+
+// {{.StructName}}Repository {{.Name}} 表资源库（GORM）
+type {{.StructName}}Repository struct {
+	freedom.Repository
+}
+
+// Get 根据主键获取
+func (repo *{{.StructName}}Repository) Get({{.PrimaryKey.ParamName}} {{.PrimaryKey.GoType}}) (*po.{{.StructName}}, error) {
+	var obj po.{{.StructName}}
+	if err := repo.db().Where("{{.PrimaryKey.Name}} = ?", {{.PrimaryKey.ParamName}}).Take(&obj).Error; err != nil {
+		return nil, convertError(err)
+	}
+	return &obj, nil
+}
+
+// Finds 按非零字段查询
+func (repo *{{.StructName}}Repository) Finds(query po.{{.StructName}}) ([]po.{{.StructName}}, error) {
+	var list []po.{{.StructName}}
+	if err := repo.db().Where(&query).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// New 创建，回写主键
+func (repo *{{.StructName}}Repository) New(obj *po.{{.StructName}}) error {
+	return convertError(repo.db().Create(obj).Error)
+}
+
+// Save 只更新通过 SetX/AddX 修改过的列
+func (repo *{{.StructName}}Repository) Save(obj *po.{{.StructName}}) error {
+	changes := obj.GetChanges()
+	if len(changes) == 0 {
+		return nil
+	}
+	return convertError(repo.db().Table(obj.TableName()).Where(obj.Location()).Updates(changes).Error)
+}
+
+// Delete 根据主键删除
+func (repo *{{.StructName}}Repository) Delete({{.PrimaryKey.ParamName}} {{.PrimaryKey.GoType}}) error {
+	result := repo.db().Where("{{.PrimaryKey.Name}} = ?", {{.PrimaryKey.ParamName}}).Delete(&po.{{.StructName}}{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return dependency.ErrNotFound
+	}
+	return nil
+}
+
+// db .
+func (repo *{{.StructName}}Repository) db() *gorm.DB {
+	var db *gorm.DB
+	if err := repo.FetchDB(&db); err != nil {
+		panic(err)
+	}
+	return db
+}
+{{- end}}`))
+
+var modelsTemplate = template.Must(template.New("models").Parse(`package po
+
+// generatedModels 由 schema.json 生成的持久化对象
+var generatedModels = []interface{}{
+{{- range .Data}}
+	&{{.StructName}}{},
+{{- end}}
+}
+`))
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// update 为 true 时用生成结果覆盖 testdata/golden 下的文件：go test ./cmd/pogen -update
+var update = flag.Bool("update", false, "update golden files")
+
+// generate 按 schema 生成到临时目录，返回 po 和资源库两个输出目录
+func generate(t *testing.T, schema, poDir string) (string, string) {
+	t.Helper()
+	tables, err := loadSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkConflicts(poDir, tables); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	g := &generator{poDir: filepath.Join(out, "po"), repoDir: filepath.Join(out, "repository"), poImport: "godash/domain/po"}
+	for _, dir := range []string{g.poDir, g.repoDir} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.run(tables); err != nil {
+		t.Fatal(err)
+	}
+	return g.poDir, g.repoDir
+}
+
+// generatedFiles 目录下生成的文件名，按名称排序
+func generatedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+genSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		if ok, err := isGenerated(file); err != nil {
+			t.Fatal(err)
+		} else if ok {
+			names = append(names, filepath.Base(file))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compareDir 比较 got 目录下生成的文件与 want 目录下同名文件，suffix 为 want 中文件名多出的后缀
+func compareDir(t *testing.T, got, want, suffix string) {
+	t.Helper()
+	names := generatedFiles(t, got)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(got, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join(want, name+suffix)
+		if *update && suffix != "" {
+			if err := os.WriteFile(golden, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(data) != string(expected) {
+			t.Errorf("%s differs from %s:\n%s", name, golden, data)
+		}
+	}
+
+	// 多出的文件说明 schema 中已删除的表还留着旧的生成代码
+	stale, err := filepath.Glob(filepath.Join(want, "*"+genSuffix+suffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range stale {
+		name := filepath.Base(file)
+		name = name[:len(name)-len(suffix)]
+		if suffix == "" {
+			if ok, err := isGenerated(file); err != nil || !ok {
+				continue
+			}
+		}
+		if i := sort.SearchStrings(names, name); i == len(names) || names[i] != name {
+			t.Errorf("%s is not generated from the schema", file)
+		}
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	poDir, repoDir := generate(t, "testdata/schema.json", t.TempDir())
+	if names := generatedFiles(t, poDir); len(names) != 3 {
+		t.Errorf("generated po files %v, want goods_stock, tag and models (legacy is skipped)", names)
+	}
+	compareDir(t, poDir, "testdata/golden/po", ".golden")
+	compareDir(t, repoDir, "testdata/golden/repository", ".golden")
+}
+
+// TestGeneratedUpToDate 仓库中提交的生成代码与 domain/po/schema.json 一致，修改 schema 后需要执行 go generate ./domain/po
+func TestGeneratedUpToDate(t *testing.T) {
+	poDir, repoDir := generate(t, "../../domain/po/schema.json", "../../domain/po")
+	compareDir(t, poDir, "../../domain/po", "")
+	compareDir(t, repoDir, "../../adapter/repository", "")
+}
+
+func TestCheckConflicts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tag.go"), []byte("package po\n\ntype Tag struct{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := loadSchema("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkConflicts(dir, tables); err == nil {
+		t.Error("a hand-written Tag must conflict with the generated one")
+	}
+}
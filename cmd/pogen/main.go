@@ -0,0 +1,36 @@
+// Command pogen 读取 domain/po/schema.json，生成 po 结构体和 GORM 资源库骨架
+//
+// 用法（在 domain/po 目录下由 go generate 调用）：
+//
+//	go run ../../cmd/pogen -schema schema.json -po . -repo ../../adapter/repository
+package main
+
+import (
+	"flag"
+	"log"
+)
+
+func main() {
+	var (
+		schemaFile = flag.String("schema", "domain/po/schema.json", "表结构描述文件")
+		poDir      = flag.String("po", "domain/po", "po 结构体输出目录")
+		repoDir    = flag.String("repo", "adapter/repository", "资源库输出目录")
+		poImport   = flag.String("import", "godash/domain/po", "po 包的导入路径")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("pogen: ")
+
+	tables, err := loadSchema(*schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := checkConflicts(*poDir, tables); err != nil {
+		log.Fatal(err)
+	}
+
+	g := &generator{poDir: *poDir, repoDir: *repoDir, poImport: *poImport}
+	if err := g.run(tables); err != nil {
+		log.Fatal(err)
+	}
+}
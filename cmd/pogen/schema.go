@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const columnsPrefix = "columns:"
+
+// goTypes schema 中的列类型到 Go 类型的映射
+var goTypes = map[string]string{
+	"int":       "int",
+	"int8":      "int8",
+	"int16":     "int16",
+	"int32":     "int32",
+	"int64":     "int64",
+	"uint":      "uint",
+	"uint8":     "uint8",
+	"uint16":    "uint16",
+	"uint32":    "uint32",
+	"uint64":    "uint64",
+	"float32":   "float32",
+	"float64":   "float64",
+	"bool":      "bool",
+	"string":    "string",
+	"timestamp": "time.Time",
+	"datetime":  "time.Time",
+}
+
+// commonInitialisms 生成字段名时整体大写的缩写
+var commonInitialisms = map[string]bool{
+	"id": true, "ip": true, "url": true, "sku": true, "uuid": true, "api": true,
+}
+
+// Table 一张表的描述
+type Table struct {
+	Name       string
+	StructName string
+	PrimaryKey *Column
+	Columns    []Column
+	Skip       bool // 只描述表结构，不生成代码，如已有手写 po 的表
+}
+
+// Column 一列的描述
+type Column struct {
+	Name      string // 数据库列名，与 schema 保持一致
+	FieldName string
+	GoType    string
+	Primary   bool
+}
+
+// Numeric 数值列额外生成 AddX 方法
+func (c Column) Numeric() bool {
+	return strings.HasPrefix(c.GoType, "int") || strings.HasPrefix(c.GoType, "uint") || strings.HasPrefix(c.GoType, "float")
+}
+
+// ParamName 作为方法参数时使用的名字
+func (c Column) ParamName() string {
+	name := []rune(c.FieldName)
+	i := 0
+	for i < len(name) && unicode.IsUpper(name[i]) {
+		i++
+	}
+	// "ID" -> "id"，"RoleID" -> "roleID"
+	if i > 1 && i < len(name) {
+		i--
+	}
+	if i == 0 {
+		i = 1
+	}
+	return strings.ToLower(string(name[:i])) + string(name[i:])
+}
+
+// loadSchema 解析 schema.json，列的顺序与文件中出现的顺序一致；"skip": true 的表只校验不返回
+func loadSchema(file string) ([]Table, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	tables := make([]Table, 0, len(raws))
+	for i, raw := range raws {
+		table, err := parseTable(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: table #%d: %w", file, i, err)
+		}
+		if !table.Skip {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+func parseTable(raw json.RawMessage) (Table, error) {
+	var table Table
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	if _, err := dec.Token(); err != nil {
+		return table, err
+	}
+
+	var primaryKey string
+	seen := map[string]bool{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return table, err
+		}
+		key := tok.(string)
+
+		switch {
+		case key == "tableName":
+			if err := dec.Decode(&table.Name); err != nil {
+				return table, err
+			}
+		case key == "primaryKey":
+			if err := dec.Decode(&primaryKey); err != nil {
+				return table, err
+			}
+		case key == "skip":
+			if err := dec.Decode(&table.Skip); err != nil {
+				return table, err
+			}
+		case strings.HasPrefix(key, columnsPrefix):
+			typ := strings.TrimPrefix(key, columnsPrefix)
+			goType, ok := goTypes[typ]
+			if !ok {
+				return table, fmt.Errorf("unsupported column type %q", typ)
+			}
+			var names []string
+			if err := dec.Decode(&names); err != nil {
+				return table, err
+			}
+			for _, name := range names {
+				if seen[name] {
+					return table, fmt.Errorf("duplicate column %q", name)
+				}
+				seen[name] = true
+				table.Columns = append(table.Columns, Column{Name: name, FieldName: exportName(name), GoType: goType})
+			}
+		default:
+			return table, fmt.Errorf("unknown key %q", key)
+		}
+	}
+
+	if table.Name == "" {
+		return table, fmt.Errorf("missing tableName")
+	}
+	if primaryKey == "" {
+		return table, fmt.Errorf("%s: missing primaryKey", table.Name)
+	}
+	for i := range table.Columns {
+		if table.Columns[i].Name == primaryKey {
+			table.Columns[i].Primary = true
+			table.PrimaryKey = &table.Columns[i]
+		}
+	}
+	if table.PrimaryKey == nil {
+		return table, fmt.Errorf("%s: primaryKey %q is not a declared column", table.Name, primaryKey)
+	}
+	table.StructName = exportName(table.Name)
+	return table, nil
+}
+
+// exportName 把 user_id / userId 之类的名字转为导出标识符 UserID
+func exportName(name string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r):
+			flush()
+			word = append(word, unicode.ToLower(r))
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if commonInitialisms[w] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	return b.String()
+}
+
+// checkConflicts 生成的类型不能与 po 包中手写的类型重名
+func checkConflicts(poDir string, tables []Table) error {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(poDir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	declared := map[string]string{}
+	for _, file := range files {
+		if strings.HasSuffix(file, genSuffix) || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				declared[spec.(*ast.TypeSpec).Name.Name] = file
+			}
+		}
+	}
+
+	var conflicts []string
+	for _, table := range tables {
+		if file, ok := declared[table.StructName]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("table %q: type %s already declared in %s", table.Name, table.StructName, file))
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("conflicts with hand-written po:\n\t%s", strings.Join(conflicts, "\n\t"))
+	}
+	return nil
+}
@@ -28,14 +28,49 @@ type Configuration struct {
 	DB    DBConf                 `toml:"db" yaml:"db"`
 	Other map[string]interface{} `toml:"other" yaml:"other"`
 	Redis RedisConf              `toml:"redis" yaml:"redis"`
+	Auth  AuthConf               `toml:"auth" yaml:"auth"`
+
+	Maintenance MaintenanceConf `toml:"maintenance" yaml:"maintenance"`
 }
 
+// 存储驱动
+const (
+	DriverMemory = "memory" // 内存存储，不连接数据库
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
 // DBConf .
 type DBConf struct {
-	Addr            string `toml:"addr" yaml:"addr"`
+	Driver          string `toml:"driver" yaml:"driver"` // memory, mysql, sqlite
+	Addr            string `toml:"addr" yaml:"addr"`     // mysql 为 DSN，sqlite 为数据库文件路径
 	MaxOpenConns    int    `toml:"max_open_conns" yaml:"max_open_conns"`
 	MaxIdleConns    int    `toml:"max_idle_conns" yaml:"max_idle_conns"`
 	ConnMaxLifeTime int    `toml:"conn_max_life_time" yaml:"conn_max_life_time"`
+	AutoMigrate     bool   `toml:"auto_migrate" yaml:"auto_migrate"`   // 启动时自动迁移表结构
+	SettingsFile    string `toml:"settings_file" yaml:"settings_file"` // memory 模式下系统设置的保存文件，留空则只保存在内存
+}
+
+// 会话存储
+const (
+	SessionStoreMemory = "memory"
+	SessionStoreRedis  = "redis" // 使用 [redis] 配置的连接
+)
+
+// AuthConf 登录认证配置
+type AuthConf struct {
+	SessionStore  string `toml:"session_store" yaml:"session_store"`   // memory, redis
+	Cookie        string `toml:"cookie" yaml:"cookie"`                 // 会话 cookie 名称
+	Expires       int    `toml:"expires" yaml:"expires"`               // 会话有效期，分钟
+	AdminUsername string `toml:"admin_username" yaml:"admin_username"` // 启动时确保存在的管理员账号
+	AdminPassword string `toml:"admin_password" yaml:"admin_password"`
+}
+
+// MaintenanceConf 维护模式配置，开关在系统设置中，保存后立即生效
+type MaintenanceConf struct {
+	AllowPaths []string `toml:"allow_paths" yaml:"allow_paths"` // 维护期间仍可访问的路径前缀
+	AllowIPs   []string `toml:"allow_ips" yaml:"allow_ips"`     // 维护期间仍可访问的客户端 IP 或 CIDR 网段
+	RetryAfter int      `toml:"retry_after" yaml:"retry_after"` // 503 响应的 Retry-After，秒
 }
 
 // RedisConf .
@@ -65,6 +100,24 @@ func newConfig() *Configuration {
 	if err == nil {
 		result.App.Other = result.Other
 	}
+	if result.DB.Driver == "" {
+		result.DB.Driver = DriverMemory
+	}
+	if result.Auth.SessionStore == "" {
+		result.Auth.SessionStore = SessionStoreMemory
+	}
+	if result.Auth.Cookie == "" {
+		result.Auth.Cookie = "godash_session"
+	}
+	if result.Auth.Expires <= 0 {
+		result.Auth.Expires = 120
+	}
+	if result.Maintenance.AllowPaths == nil {
+		result.Maintenance.AllowPaths = []string{"/ping", "/settings", "/login", "/logout", "/static"}
+	}
+	if result.Maintenance.RetryAfter <= 0 {
+		result.Maintenance.RetryAfter = 300
+	}
 	if err != nil {
 		freedom.Logger().Fatal(err)
 	}
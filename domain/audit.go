@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"encoding/json"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/infra"
+	"sort"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *AuditService {
+			return &AuditService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *AuditService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+	})
+}
+
+// 审计实体
+const (
+	AuditEntityUser    = "user"
+	AuditEntityProduct = "product"
+	AuditEntityOrder   = "order"
+	AuditEntityRole    = "role"
+	AuditEntityRefund  = "refund"
+)
+
+// 审计动作
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditSystemActor 没有登录用户时（如启动任务）的操作人
+const AuditSystemActor = "system"
+
+// requestIDKey 与 middleware.NewTrace 使用的键一致
+const requestIDKey = "x-request-id"
+
+// auditIgnoredFields 不参与差异比较的字段，包括只用于列表展示的统计字段
+var auditIgnoredFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"user_count": true,
+}
+
+// AuditService 审计日志领域服务
+type AuditService struct {
+	Worker    freedom.Worker
+	AuditRepo dependency.AuditRepo
+}
+
+// List 按实体和操作人分页查询审计日志，最新的在前
+func (s *AuditService) List(query vo.ListQuery, filter vo.AuditFilter) (vo.Page[vo.AuditLog], error) {
+	return s.AuditRepo.Finds(query, filter)
+}
+
+// recordAudit 记录一次数据变更，操作人和请求 ID 取自当前请求。
+// 领域服务之间不能互相注入，所以各服务注入 AuditRepo 后调用此函数；
+// 写入失败只记录日志，不影响已经完成的业务操作。
+func recordAudit(worker freedom.Worker, repo dependency.AuditRepo, entity string, entityID int64, action string, changes []vo.AuditChange) {
+	if action == AuditUpdate && len(changes) == 0 {
+		return
+	}
+
+	log := &vo.AuditLog{
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Changes:   changes,
+		RequestID: worker.Bus().Get(requestIDKey),
+		CreatedAt: time.Now(),
+	}
+	log.ActorID, log.Actor = currentActor(worker)
+	if err := repo.New(log); err != nil {
+		worker.Logger().Error("写入审计日志失败", freedom.LogFields{"entity": entity, "id": entityID, "error": err.Error()})
+	}
+}
+
+// currentActor 当前请求的登录用户，没有时为系统操作
+func currentActor(worker freedom.Worker) (int64, string) {
+	if user, ok := infra.CurrentUser(worker.IrisContext()); ok {
+		return user.ID, user.Username
+	}
+	return 0, AuditSystemActor
+}
+
+// auditDiff 比较两个值对象的 JSON 字段，before 或 after 为 nil 时表示创建或删除
+func auditDiff(before, after interface{}) []vo.AuditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	keys := make([]string, 0, len(beforeFields)+len(afterFields))
+	for key := range beforeFields {
+		keys = append(keys, key)
+	}
+	for key := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []vo.AuditChange{}
+	for _, key := range keys {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		b, a := beforeFields[key], afterFields[key]
+		if b == a {
+			continue
+		}
+		changes = append(changes, vo.AuditChange{Field: key, Before: b, After: a})
+	}
+	return changes
+}
+
+// auditFields 把值对象展开为 字段名 -> 字符串值
+func auditFields(obj interface{}) map[string]string {
+	result := map[string]string{}
+	if obj == nil {
+		return result
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return result
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return result
+	}
+	for key, raw := range fields {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			result[key] = text
+			continue
+		}
+		if string(raw) == "null" {
+			result[key] = ""
+			continue
+		}
+		result[key] = string(raw)
+	}
+	return result
+}
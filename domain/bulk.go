@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+)
+
+// 批量操作
+const (
+	BulkDelete     = "delete"
+	BulkActivate   = "activate"
+	BulkDeactivate = "deactivate"
+	BulkBan        = "ban"
+	BulkSetRole    = "set_role"
+	BulkSetStatus  = "set_status"
+)
+
+// ErrBulkAction 不支持的批量操作或缺少目标值
+var ErrBulkAction = errors.New("不支持的批量操作")
+
+// bulkApply 逐条执行 fn，重复的 ID 只处理一次。每条记录走单条操作的逻辑，
+// 审计日志照常记录；失败的记录连同原因放入 Failed，不中断其余记录。
+func bulkApply[T any](ids []int64, fn func(id int64) (T, error)) vo.BulkResult[T] {
+	result := vo.BulkResult[T]{}
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item, err := fn(id)
+		if err != nil {
+			reason := err.Error()
+			if errors.Is(err, dependency.ErrNotFound) {
+				reason = "记录不存在"
+			}
+			result.Failed = append(result.Failed, vo.BulkFailure{ID: id, Reason: reason})
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, item)
+	}
+	return result
+}
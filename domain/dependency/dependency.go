@@ -0,0 +1,117 @@
+// Package dependency 依赖倒置的接口，由外部 adapter 负责实现
+package dependency
+
+import (
+	"errors"
+	"godash/domain/vo"
+	"time"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate 唯一字段冲突
+var ErrDuplicate = errors.New("duplicate record")
+
+// ErrConflict 记录已被其他请求修改
+var ErrConflict = errors.New("record changed")
+
+// Transaction 事务，fun 内的资源库操作要么全部生效，要么全部回滚。
+// 没有事务的存储（内存模式）在 fun 返回错误时按相反顺序执行 fun 中登记的补偿
+type Transaction interface {
+	Execute(fun func() error) error
+	Compensate(undo func())
+	AfterCommit(fun func()) // 事务提交后执行，回滚时丢弃；不在事务中时立即执行
+}
+
+// EventBus 领域事件总线，在事务中发布的事件提交后才投递
+type EventBus interface {
+	Publish(event vo.Event)
+}
+
+// UserRepo 用户资源库
+type UserRepo interface {
+	Get(id int64) (*vo.User, error)
+	FindByUsername(username string) (*vo.User, error)
+	Finds(query vo.ListQuery, filter vo.UserFilter) (vo.Page[vo.User], error)
+	CountByRole() (map[string]int, error)
+	CountByStatus() (map[string]int, error)
+	FindCreatedTimes(from, to time.Time) ([]time.Time, error) // 在 [from, to) 内注册的用户的注册时间
+	FindActiveIDs(roles []string) ([]int64, error)            // 角色在 roles 中且状态正常的用户 ID
+	New(user *vo.User) error
+	Save(user *vo.User) error
+	Delete(id int64) error
+}
+
+// ProductRepo 商品资源库
+type ProductRepo interface {
+	Get(id int64) (*vo.Product, error)
+	FindBySKU(sku string) (*vo.Product, error)
+	Finds(query vo.ListQuery, filter vo.ProductFilter) (vo.Page[vo.Product], error)
+	New(product *vo.Product) error
+	NewBatch(products []*vo.Product) error // 全部写入或全部不写入，SKU 冲突时返回 ErrDuplicate
+	Save(product *vo.Product) error
+	// UpdateStock 原子地读取 SKU 对应的商品交给 fn 修改（不存在的 SKU 不在 map 中），
+	// fn 返回 nil 时保存库存、预留和状态，返回错误时不做任何修改
+	UpdateStock(skus []string, fn func(products map[string]*vo.Product) error) error
+	Stats(lowStock int) (vo.ProductStats, error) // 商品总数和可售库存不超过 lowStock 的未下架商品数
+	Delete(id int64) error
+}
+
+// OrderRepo 订单资源库
+type OrderRepo interface {
+	Get(id int64) (*vo.Order, error)
+	Finds(query vo.ListQuery, filter vo.OrderFilter) (vo.Page[vo.Order], error)
+	New(order *vo.Order) error // 连同订单项一起写入，回写 ID；订单号重复时返回 ErrDuplicate
+	Save(order *vo.Order) error
+	SaveWithItems(order *vo.Order, status string) error             // 订单状态仍为 status 时保存主信息并替换全部订单项，否则返回 ErrConflict
+	SaveStatus(order *vo.Order, change *vo.OrderStatusChange) error // 订单状态仍为 change.From 时才保存，否则返回 ErrConflict
+	FindStatusChanges(orderID int64) ([]vo.OrderStatusChange, error)
+	Stats(since time.Time) (vo.OrderStats, error)           // since 之后下单的另计为今日
+	TopProducts(limit int) ([]vo.ProductSales, error)       // 按售出件数降序
+	FindPoints(from, to time.Time) ([]vo.OrderPoint, error) // 在 [from, to) 内下单的订单
+}
+
+// RefundRepo 退款资源库
+type RefundRepo interface {
+	Get(id int64) (*vo.Refund, error)
+	FindByOrder(orderID int64) ([]vo.Refund, error)  // 按申请时间先后排列
+	New(refund *vo.Refund) error                     // 连同退款项一起写入，回写 ID
+	SaveStatus(refund *vo.Refund, from string) error // 退款状态仍为 from 时才保存，否则返回 ErrConflict
+	// UpdateByOrder 原子地读取订单的全部退款（以 ID 为键）交给 fn 修改，
+	// fn 返回 nil 时保存状态，返回错误时不修改任何退款
+	UpdateByOrder(orderID int64, fn func(refunds map[int64]*vo.Refund) error) error
+}
+
+// RoleRepo 角色资源库
+type RoleRepo interface {
+	Get(id int64) (*vo.Role, error)
+	FindByCode(code string) (*vo.Role, error)
+	Finds() ([]vo.Role, error)
+	New(role *vo.Role) error
+	Save(role *vo.Role) error
+	Delete(id int64) error
+}
+
+// NotificationRepo 站内通知资源库，所有操作都限定在接收人 userID 的通知内
+type NotificationRepo interface {
+	NewBatch(notifications []*vo.Notification) error // 回写 ID
+	Finds(userID int64, query vo.ListQuery, filter vo.NotificationFilter) (vo.Page[vo.Notification], error)
+	CountUnread(userID int64) (int, error)
+	MarkRead(userID, id int64, at time.Time) (*vo.Notification, error) // 通知不存在或不属于 userID 时返回 ErrNotFound，已读时不修改
+	MarkAllRead(userID int64, at time.Time) (int, error)               // 返回标记的条数
+}
+
+// SettingRepo 系统设置资源库，按版本只追加
+type SettingRepo interface {
+	Latest() (*vo.SettingsVersion, error) // 从未保存时返回 ErrNotFound
+	Get(version int) (*vo.SettingsVersion, error)
+	Finds(query vo.ListQuery) (vo.Page[vo.SettingsVersion], error) // 按版本号降序分页，只支持页码模式
+	New(version *vo.SettingsVersion) error                         // 版本号已存在时返回 ErrDuplicate
+}
+
+// AuditRepo 审计日志资源库，只追加不修改
+type AuditRepo interface {
+	New(log *vo.AuditLog) error
+	Finds(query vo.ListQuery, filter vo.AuditFilter) (vo.Page[vo.AuditLog], error)
+}
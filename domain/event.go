@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"time"
+)
+
+// publishEvent 发布领域事件，bus 为空时（如启动任务）忽略
+func publishEvent(bus dependency.EventBus, topic, entity string, id int64, data map[string]interface{}) {
+	if bus == nil {
+		return
+	}
+	bus.Publish(vo.Event{Topic: topic, Entity: entity, EntityID: id, Data: data, Time: time.Now()})
+}
+
+// publishOrderEvent 发布订单事件，附带订单号、状态和金额
+func publishOrderEvent(bus dependency.EventBus, topic string, order vo.Order) {
+	publishEvent(bus, topic, AuditEntityOrder, order.ID, map[string]interface{}{
+		"order_no":     order.OrderNo,
+		"status":       order.Status,
+		"total_amount": order.TotalAmount,
+	})
+}
+
+// publishLowStock 商品可售库存从阈值以上降到阈值及以下时发布低库存事件，持续低于阈值时不重复发布；
+// 阈值取自系统设置
+func publishLowStock(bus dependency.EventBus, before, after vo.Product) {
+	threshold := CurrentSettings().LowStockThreshold
+	if before.Available() <= threshold || after.Available() > threshold {
+		return
+	}
+	publishEvent(bus, vo.EventStockLow, AuditEntityProduct, after.ID, map[string]interface{}{
+		"sku":       after.SKU,
+		"name":      after.Name,
+		"available": after.Available(),
+		"threshold": threshold,
+	})
+}
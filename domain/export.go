@@ -0,0 +1,29 @@
+package domain
+
+import "godash/domain/vo"
+
+// exportBatchSize 导出时每批读取的记录数
+const exportBatchSize = 200
+
+// eachRow 按 ID 游标分批读取列表并对每条记录调用 fn，内存中只保留一批记录。
+// 游标分页只能按 ID 翻页，所以导出忽略排序。
+func eachRow[T any](query vo.ListQuery, find func(vo.ListQuery) (vo.Page[T], error), fn func(T) error) error {
+	query.Mode = vo.PageModeCursor
+	query.Cursor = ""
+	query.PageSize = exportBatchSize
+	for {
+		page, err := find(query)
+		if err != nil {
+			return err
+		}
+		for _, item := range page.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if page.PageInfo.NextCursor == "" {
+			return nil
+		}
+		query.Cursor = page.PageInfo.NextCursor
+	}
+}
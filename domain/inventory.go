@@ -0,0 +1,258 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+// ErrProductUnavailable 订单项的商品不存在或已下架
+var ErrProductUnavailable = errors.New("商品不存在或已下架")
+
+// ErrStockShort 可售库存不足
+var ErrStockShort = errors.New("库存不足")
+
+// inventory 库存领域服务：按 SKU 把订单项对应到商品，订单创建时预留库存，支付时扣减，
+// 取消时释放预留或退回已扣减的库存，并按可售库存自动切换在售/缺货。
+// 领域服务之间不能互相注入，由持有 ProductRepo 的服务通过 newInventory 创建。
+// 每次库存变动都在 tx 中登记补偿，内存模式下事务内后续的保存失败时撤销变动。
+type inventory struct {
+	worker   freedom.Worker
+	products dependency.ProductRepo
+	audit    dependency.AuditRepo
+	events   dependency.EventBus
+	tx       dependency.Transaction
+}
+
+func newInventory(worker freedom.Worker, products dependency.ProductRepo, audit dependency.AuditRepo, events dependency.EventBus, tx dependency.Transaction) inventory {
+	return inventory{worker: worker, products: products, audit: audit, events: events, tx: tx}
+}
+
+// Reserve 预留库存并用商品的名称和当前价格填充订单项，
+// 任一商品不存在、已下架或可售库存不足时整单拒绝
+func (inv inventory) Reserve(items []vo.OrderItem) error {
+	return inv.Rereserve(nil, items)
+}
+
+// Rereserve 编辑未支付订单时按新旧订单项的数量差调整预留：增加的部分需要商品在售且可售库存足够，
+// 减少或移除的部分释放预留；新订单项按商品当前名称和价格重新填充
+func (inv inventory) Rereserve(previous, items []vo.OrderItem) error {
+	skus, quantities := tally(items)
+	required := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		required[sku] = true
+	}
+	for _, item := range previous {
+		if _, ok := quantities[item.SKU]; !ok {
+			skus = append(skus, item.SKU)
+		}
+		quantities[item.SKU] -= item.Quantity
+	}
+
+	resolved := make(map[string]vo.Product, len(skus))
+	err := inv.apply(skus, quantities, required, func(product *vo.Product, quantity int) error {
+		if quantity > 0 {
+			if err := checkStock(*product, quantity); err != nil {
+				return err
+			}
+			product.Reserved += quantity
+		} else {
+			product.Reserved -= min(-quantity, product.Reserved)
+		}
+		resolved[product.SKU] = *product
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		product := resolved[items[i].SKU]
+		items[i].ProductName = product.Name
+		items[i].Price = product.Price
+		items[i].Subtotal = roundAmount(product.Price * float64(items[i].Quantity))
+	}
+	return nil
+}
+
+// Quote 按商品当前价格计算订单项金额并检查库存，只读不预留；
+// previous 为编辑前的订单项，其预留的数量计入可售库存
+func (inv inventory) Quote(previous, items []vo.OrderItem) vo.OrderQuote {
+	_, held := tally(previous)
+	quote := vo.OrderQuote{Valid: len(items) > 0}
+	for _, item := range items {
+		line := vo.OrderLine{OrderItem: item}
+		product, err := inv.products.FindBySKU(item.SKU)
+		switch {
+		case err != nil:
+			line.Error = ErrProductUnavailable.Error()
+		default:
+			product.Reserved -= min(held[item.SKU], product.Reserved)
+			line.ProductName = product.Name
+			line.Price = product.Price
+			line.Subtotal = roundAmount(product.Price * float64(item.Quantity))
+			line.Available = product.Available()
+			if item.Quantity <= 0 {
+				line.Error = "数量必须大于 0"
+			} else if err := checkStock(*product, item.Quantity-held[item.SKU]); err != nil {
+				line.Error = err.Error()
+			}
+		}
+		if line.Error != "" {
+			quote.Valid = false
+		}
+		quote.TotalAmount += line.Subtotal
+		quote.Lines = append(quote.Lines, line)
+	}
+	quote.TotalAmount = roundAmount(quote.TotalAmount)
+	return quote
+}
+
+// checkStock 新增 quantity 件的预留前检查商品在售且可售库存足够，quantity 不大于 0 时不检查
+func checkStock(product vo.Product, quantity int) error {
+	if quantity <= 0 {
+		return nil
+	}
+	if product.Status == "inactive" {
+		return fmt.Errorf("%w：%s", ErrProductUnavailable, product.Name)
+	}
+	if product.Available() < quantity {
+		return fmt.Errorf("%w：%s 可售 %d，需要 %d", ErrStockShort, product.Name, product.Available(), quantity)
+	}
+	return nil
+}
+
+// Deduct 支付时扣减库存并消耗预留；没有预留的部分（如早期订单）需要可售库存足够，商品已删除时跳过
+func (inv inventory) Deduct(items []vo.OrderItem) error {
+	return inv.applyItems(items, func(product *vo.Product, quantity int) error {
+		reserved := min(quantity, product.Reserved)
+		if product.Available() < quantity-reserved {
+			return fmt.Errorf("%w：%s 可售 %d，需要 %d", ErrStockShort, product.Name, product.Available(), quantity-reserved)
+		}
+		product.Reserved -= reserved
+		product.Stock -= quantity
+		return nil
+	})
+}
+
+// Release 释放未支付订单的预留，商品已删除时跳过
+func (inv inventory) Release(items []vo.OrderItem) error {
+	return inv.applyItems(items, func(product *vo.Product, quantity int) error {
+		product.Reserved -= min(quantity, product.Reserved)
+		return nil
+	})
+}
+
+// Restock 退回已扣减的库存，商品已删除时跳过
+func (inv inventory) Restock(items []vo.OrderItem) error {
+	return inv.applyItems(items, func(product *vo.Product, quantity int) error {
+		product.Stock += quantity
+		return nil
+	})
+}
+
+// OnStatusChange 订单状态流转对应的库存变动：支付扣减，未支付取消释放预留，已支付取消退回库存
+func (inv inventory) OnStatusChange(from, to string, items []vo.OrderItem) error {
+	switch {
+	case from == vo.OrderPending && to == vo.OrderPaid:
+		return inv.Deduct(items)
+	case from == vo.OrderPending && to == vo.OrderCancelled:
+		return inv.Release(items)
+	case from == vo.OrderPaid && to == vo.OrderCancelled:
+		return inv.Restock(items)
+	}
+	return nil
+}
+
+// applyItems 按订单项数量修改库存，商品已删除时跳过
+func (inv inventory) applyItems(items []vo.OrderItem, change func(product *vo.Product, quantity int) error) error {
+	skus, quantities := tally(items)
+	return inv.apply(skus, quantities, nil, change)
+}
+
+// apply 原子地按 SKU 修改商品库存，事务提交后为每个有变动的商品记录审计日志，可售库存降到阈值时发布低库存事件。
+// required 中的 SKU 对应的商品不存在时整单失败，其余不存在的 SKU 跳过。
+func (inv inventory) apply(skus []string, quantities map[string]int, required map[string]bool, change func(product *vo.Product, quantity int) error) error {
+	if len(skus) == 0 {
+		return nil
+	}
+
+	var before, after []vo.Product
+	err := inv.products.UpdateStock(skus, func(products map[string]*vo.Product) error {
+		before, after = before[:0], after[:0]
+		now := time.Now()
+		for _, sku := range skus {
+			product, ok := products[sku]
+			if !ok {
+				if required[sku] {
+					return fmt.Errorf("%w：%s", ErrProductUnavailable, sku)
+				}
+				continue
+			}
+			original := *product
+			if err := change(product, quantities[sku]); err != nil {
+				return err
+			}
+			product.SyncStockStatus()
+			if *product == original {
+				continue
+			}
+			product.UpdatedAt = now
+			before = append(before, original)
+			after = append(after, *product)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(after) > 0 {
+		// 内存模式失败时库存由补偿撤销，审计日志和事件也要等提交后再写，避免留下没有生效的变动
+		inv.tx.AfterCommit(func() {
+			for i := range after {
+				recordAudit(inv.worker, inv.audit, AuditEntityProduct, after[i].ID, AuditUpdate, auditDiff(before[i], after[i]))
+				publishLowStock(inv.events, before[i], after[i])
+			}
+		})
+		inv.tx.Compensate(func() {
+			if err := inv.revert(before, after); err != nil {
+				inv.worker.Logger().Error("撤销库存变动失败", freedom.LogFields{"skus": skus, "error": err.Error()})
+			}
+		})
+	}
+	return nil
+}
+
+// revert 撤销一次 apply 对库存和预留的变动量，期间其他请求做的变动保留
+func (inv inventory) revert(before, after []vo.Product) error {
+	skus := make([]string, 0, len(after))
+	deltas := make(map[string]vo.Product, len(after))
+	for i := range after {
+		skus = append(skus, after[i].SKU)
+		deltas[after[i].SKU] = vo.Product{
+			Stock:    after[i].Stock - before[i].Stock,
+			Reserved: after[i].Reserved - before[i].Reserved,
+		}
+	}
+	return inv.apply(skus, nil, nil, func(product *vo.Product, _ int) error {
+		delta := deltas[product.SKU]
+		product.Stock -= delta.Stock
+		product.Reserved = max(product.Reserved-delta.Reserved, 0)
+		return nil
+	})
+}
+
+// tally 按 SKU 汇总订单项数量，skus 保持首次出现的顺序
+func tally(items []vo.OrderItem) (skus []string, quantities map[string]int) {
+	quantities = make(map[string]int, len(items))
+	for _, item := range items {
+		if _, ok := quantities[item.SKU]; !ok {
+			skus = append(skus, item.SKU)
+		}
+		quantities[item.SKU] += item.Quantity
+	}
+	return skus, quantities
+}
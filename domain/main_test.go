@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"godash/adapter/repository"
+	"godash/config"
+	"godash/infra"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/8treenet/freedom"
+	"github.com/8treenet/iris/v12/context"
+	"github.com/8treenet/iris/v12/core/memstore"
+)
+
+// baseWorker 单元测试工具的 Worker，只用来提供日志组件
+var baseWorker freedom.Worker
+
+// TestMain 以内存模式启动应用，设置文件写到临时目录
+func TestMain(m *testing.M) {
+	os.Setenv(freedom.ProfileENV, "../config")
+	dir, err := os.MkdirTemp("", "godash-test")
+	if err != nil {
+		panic(err)
+	}
+	config.Get().DB.Driver = config.DriverMemory
+	config.Get().DB.SettingsFile = filepath.Join(dir, "settings.json")
+
+	unitTest := freedom.NewUnitTest()
+	unitTest.Run()
+	var service *SettingService
+	unitTest.FetchService(&service)
+	baseWorker = service.Worker
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// testWorker 模拟一个请求的 Worker：请求上下文和请求存储各自独立，并发测试中每个 goroutine 使用一个
+type testWorker struct {
+	freedom.Worker
+	ctx   freedom.Context
+	store memstore.Store
+}
+
+func newTestWorker() *testWorker {
+	return &testWorker{Worker: baseWorker, ctx: context.NewContext(nil)}
+}
+
+// IrisContext .
+func (w *testWorker) IrisContext() freedom.Context {
+	return w.ctx
+}
+
+// Store .
+func (w *testWorker) Store() *memstore.Store {
+	return &w.store
+}
+
+func newTestTx(worker freedom.Worker) *infra.Transaction {
+	tx := &infra.Transaction{}
+	tx.BeginRequest(worker)
+	return tx
+}
+
+func newTestEvents(worker freedom.Worker) *infra.EventBus {
+	events := &infra.EventBus{}
+	events.BeginRequest(worker)
+	return events
+}
+
+// newTestOrderService 一个请求中的订单服务，资源库为内存实现
+func newTestOrderService() *OrderService {
+	worker := newTestWorker()
+	return &OrderService{
+		Worker:      worker,
+		OrderRepo:   &repository.OrderMemoryRepository{},
+		ProductRepo: &repository.ProductMemoryRepository{},
+		UserRepo:    &repository.UserMemoryRepository{},
+		AuditRepo:   &repository.AuditMemoryRepository{},
+		Tx:          newTestTx(worker),
+		Events:      newTestEvents(worker),
+	}
+}
+
+// newTestRefundService 一个请求中的退款服务
+func newTestRefundService() *RefundService {
+	worker := newTestWorker()
+	return &RefundService{
+		Worker:      worker,
+		OrderRepo:   &repository.OrderMemoryRepository{},
+		ProductRepo: &repository.ProductMemoryRepository{},
+		RefundRepo:  &repository.RefundMemoryRepository{},
+		AuditRepo:   &repository.AuditMemoryRepository{},
+		Tx:          newTestTx(worker),
+		Events:      newTestEvents(worker),
+	}
+}
+
+// newTestSettingService 一个请求中的设置服务
+func newTestSettingService() *SettingService {
+	return &SettingService{Worker: newTestWorker(), SettingRepo: &repository.SettingMemoryRepository{}}
+}
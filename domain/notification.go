@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/infra"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *NotificationService {
+			return &NotificationService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *NotificationService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+		initiator.BindBooting(func(bootManager freedom.BootManager) {
+			// 订阅随进程存在，不需要关闭；事件在发布的请求提交后才到达，通知异步生成，后台订阅不丢弃事件
+			sub := infra.SubscribeEvents(notificationTopics)
+			go func() {
+				for event := range sub.Events() {
+					err := freedom.ServiceLocator().Call(func(service *NotificationService) error {
+						return service.Notify(event)
+					})
+					if err != nil {
+						freedom.Logger().Errorf("生成通知失败: %v", err)
+					}
+				}
+			}()
+		})
+	})
+}
+
+// notificationMenuSize 顶部栏通知下拉显示的条数
+const notificationMenuSize = 5
+
+// notificationPermissions 生成通知的事件主题，以及接收人需要的权限
+var notificationPermissions = map[string]string{
+	vo.EventOrderCreated: "orders:read",
+	vo.EventStockLow:     "products:read",
+	vo.EventUserCreated:  "users:read",
+}
+
+var notificationTopics = []string{vo.EventOrderCreated, vo.EventStockLow, vo.EventUserCreated}
+
+// NotificationService 站内通知领域服务：新的待处理订单、低库存和新用户事件
+// 发给拥有对应模块读权限、状态正常的用户，触发事件的用户和新用户本人不会收到
+type NotificationService struct {
+	Worker           freedom.Worker
+	NotificationRepo dependency.NotificationRepo
+	UserRepo         dependency.UserRepo
+	RoleRepo         dependency.RoleRepo
+	Events           dependency.EventBus
+}
+
+// List 分页查询用户的通知，最新的在前
+func (s *NotificationService) List(userID int64, query vo.ListQuery, filter vo.NotificationFilter) (vo.Page[vo.Notification], error) {
+	return s.NotificationRepo.Finds(userID, query, filter)
+}
+
+// Unread 用户的未读通知数
+func (s *NotificationService) Unread(userID int64) (int, error) {
+	return s.NotificationRepo.CountUnread(userID)
+}
+
+// Menu 顶部栏通知下拉：未读数和最新几条通知
+func (s *NotificationService) Menu(userID int64) (vo.NotificationMenu, error) {
+	unread, err := s.NotificationRepo.CountUnread(userID)
+	if err != nil {
+		return vo.NotificationMenu{}, err
+	}
+	page, err := s.NotificationRepo.Finds(userID, vo.ListQuery{Page: 1, PageSize: notificationMenuSize}, vo.NotificationFilter{})
+	if err != nil {
+		return vo.NotificationMenu{}, err
+	}
+	return vo.NotificationMenu{Unread: unread, Recent: page.Items}, nil
+}
+
+// MarkRead 把用户的一条通知标记为已读并返回，不属于该用户的通知返回 ErrNotFound
+func (s *NotificationService) MarkRead(userID, id int64) (*vo.Notification, error) {
+	return s.NotificationRepo.MarkRead(userID, id, time.Now())
+}
+
+// MarkAllRead 把用户的全部未读通知标记为已读，返回标记的条数
+func (s *NotificationService) MarkAllRead(userID int64) (int, error) {
+	return s.NotificationRepo.MarkAllRead(userID, time.Now())
+}
+
+// Notify 按领域事件给接收人生成通知，并发布 notification 事件让在线用户刷新未读数
+func (s *NotificationService) Notify(event vo.Event) error {
+	permission, ok := notificationPermissions[event.Topic]
+	if !ok {
+		return nil
+	}
+	title, content, link := notificationMessage(event)
+	excluded := []int64{event.ActorID}
+	if event.Topic == vo.EventUserCreated {
+		excluded = append(excluded, event.EntityID)
+	}
+	recipients, err := s.recipients(permission, excluded)
+	if err != nil || len(recipients) == 0 {
+		return err
+	}
+
+	notifications := make([]*vo.Notification, len(recipients))
+	for i, userID := range recipients {
+		notifications[i] = &vo.Notification{
+			UserID:    userID,
+			Topic:     event.Topic,
+			Title:     title,
+			Content:   content,
+			Link:      link,
+			Entity:    event.Entity,
+			EntityID:  event.EntityID,
+			CreatedAt: event.Time,
+		}
+	}
+	if err := s.NotificationRepo.NewBatch(notifications); err != nil {
+		return err
+	}
+	publishEvent(s.Events, vo.EventNotification, "notification", 0, map[string]interface{}{"user_ids": recipients})
+	return nil
+}
+
+// recipients 拥有 permission 且状态正常的用户，排除 excluded 中的用户
+func (s *NotificationService) recipients(permission string, excluded []int64) ([]int64, error) {
+	roles, err := s.RoleRepo.Finds()
+	if err != nil {
+		return nil, err
+	}
+	codes := []string{AdminRole}
+	for _, role := range roles {
+		if role.Code != AdminRole && role.Has(permission) {
+			codes = append(codes, role.Code)
+		}
+	}
+	ids, err := s.UserRepo.FindActiveIDs(codes)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(ids, func(id int64) bool { return slices.Contains(excluded, id) }), nil
+}
+
+// notificationMessage 通知的标题、内容和点击后打开的页面
+func notificationMessage(event vo.Event) (title, content, link string) {
+	data := event.Data
+	switch event.Topic {
+	case vo.EventOrderCreated:
+		amount, _ := data["total_amount"].(float64)
+		return "新订单", fmt.Sprintf("订单 %v 待处理，金额 %s", data["order_no"], CurrentSettings().FormatMoney(amount)),
+			"/orders?keyword=" + url.QueryEscape(fmt.Sprint(data["order_no"]))
+	case vo.EventStockLow:
+		content = fmt.Sprintf("%v（%v）已无可售库存", data["name"], data["sku"])
+		if available, _ := data["available"].(int); available > 0 {
+			content = fmt.Sprintf("%v（%v）可售库存仅剩 %d 件", data["name"], data["sku"], available)
+		}
+		return "库存预警", content, "/products?keyword=" + url.QueryEscape(fmt.Sprint(data["sku"]))
+	case vo.EventUserCreated:
+		name := fmt.Sprint(data["real_name"])
+		if name == "" {
+			name = fmt.Sprint(data["username"])
+		}
+		return "新用户注册", fmt.Sprintf("%s（%v）已加入", name, data["username"]),
+			fmt.Sprintf("/users/%d", event.EntityID)
+	}
+	return event.Topic, "", ""
+}
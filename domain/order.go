@@ -0,0 +1,340 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *OrderService {
+			return &OrderService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *OrderService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+	})
+}
+
+// ErrOrderTransition 订单状态不允许这样流转
+var ErrOrderTransition = errors.New("订单状态不允许变更")
+
+// ErrOrderChanged 订单状态已被其他请求修改
+var ErrOrderChanged = errors.New("订单状态已被修改，请刷新后重试")
+
+// ErrOrderItems 订单没有商品或商品数量无效
+var ErrOrderItems = errors.New("订单至少需要一个商品，且数量必须大于 0")
+
+// ErrOrderCustomer 客户不是有效的用户
+var ErrOrderCustomer = errors.New("请选择有效的客户")
+
+// ErrOrderPayment 不支持的支付方式
+var ErrOrderPayment = errors.New("不支持的支付方式")
+
+// ErrOrderLocked 订单已支付或已关闭，不能再修改
+var ErrOrderLocked = errors.New("只有待处理的订单可以编辑")
+
+// ErrOrderNoTaken 多次生成的订单号都已存在
+var ErrOrderNoTaken = errors.New("订单号生成冲突，请重试")
+
+// orderPickerSize 商品选择器每次最多返回的商品数
+const orderPickerSize = 8
+
+// orderNoAttempts 订单号重复时最多尝试创建的次数
+const orderNoAttempts = 3
+
+// orderNoSeq 订单号的进程内序号，同一毫秒内创建的订单不重复
+var orderNoSeq atomic.Uint32
+
+// OrderService 订单领域服务
+type OrderService struct {
+	Worker      freedom.Worker
+	OrderRepo   dependency.OrderRepo
+	ProductRepo dependency.ProductRepo
+	UserRepo    dependency.UserRepo
+	AuditRepo   dependency.AuditRepo
+	Tx          dependency.Transaction
+	Events      dependency.EventBus
+}
+
+// List 按查询规格和筛选条件分页查询订单
+func (s *OrderService) List(query vo.ListQuery, filter vo.OrderFilter) (vo.Page[vo.Order], error) {
+	page, err := s.OrderRepo.Finds(query, filter)
+	if err != nil {
+		return page, err
+	}
+	for i := range page.Items {
+		if err := s.fillFulfillment(&page.Items[i]); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+// Export 按关键词和筛选条件逐条读取订单（含订单项），用于导出
+func (s *OrderService) Export(query vo.ListQuery, filter vo.OrderFilter, fn func(vo.Order) error) error {
+	return eachRow(query, func(q vo.ListQuery) (vo.Page[vo.Order], error) {
+		return s.OrderRepo.Finds(q, filter)
+	}, fn)
+}
+
+// Get 获取订单
+func (s *OrderService) Get(id int64) (*vo.Order, error) {
+	order, err := s.OrderRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillFulfillment(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// fillFulfillment 部分退款的订单按状态变更记录填充退款前的状态，用于决定能否继续发货或完成
+func (s *OrderService) fillFulfillment(order *vo.Order) error {
+	if order.Status != vo.OrderPartiallyRefunded {
+		return nil
+	}
+	history, err := s.OrderRepo.FindStatusChanges(order.ID)
+	if err != nil {
+		return err
+	}
+	order.Fulfillment = vo.FulfillmentBeforeRefund(history)
+	return nil
+}
+
+// Create 按表单创建待处理订单：订单项只取 SKU 和数量，名称、单价和金额按商品当前价格计算，
+// 同时预留库存；客户或支付方式无效、商品不存在、已下架或库存不足时拒绝整单
+func (s *OrderService) Create(form vo.OrderFormData) (*vo.Order, error) {
+	if form.CustomerID == 0 {
+		return nil, ErrOrderCustomer
+	}
+	order := vo.Order{}
+	if err := s.applyForm(&order, form); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order.Status = vo.OrderPending
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	// 多实例部署时订单号仍可能重复，换一个订单号重试；失败的尝试已回滚或补偿库存预留
+	err := ErrOrderNoTaken
+	for attempt := 0; attempt < orderNoAttempts && errors.Is(err, ErrOrderNoTaken); attempt++ {
+		order.OrderNo = newOrderNo(time.Now())
+		err = s.Tx.Execute(func() error {
+			if err := s.inventory().Reserve(order.Items); err != nil {
+				return err
+			}
+			order.TotalAmount = totalAmount(order.Items)
+			err := s.OrderRepo.New(&order)
+			if errors.Is(err, dependency.ErrDuplicate) {
+				return ErrOrderNoTaken
+			}
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Worker.Logger().Info("创建订单", freedom.LogFields{"id": order.ID, "order_no": order.OrderNo})
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityOrder, order.ID, AuditCreate, auditDiff(nil, order))
+	publishOrderEvent(s.Events, vo.EventOrderCreated, order)
+	return &order, nil
+}
+
+// Update 按表单修改待处理订单的客户、支付方式和订单项，按数量差调整库存预留并重新计算金额；
+// 其他状态的订单返回 ErrOrderLocked
+func (s *OrderService) Update(id int64, form vo.OrderFormData) (*vo.Order, error) {
+	order, err := s.OrderRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != vo.OrderPending {
+		return nil, ErrOrderLocked
+	}
+	before := cloneItems(*order)
+	if err := s.applyForm(order, form); err != nil {
+		return nil, err
+	}
+
+	order.UpdatedAt = time.Now()
+	err = s.Tx.Execute(func() error {
+		if err := s.inventory().Rereserve(before.Items, order.Items); err != nil {
+			return err
+		}
+		order.TotalAmount = totalAmount(order.Items)
+		return s.OrderRepo.SaveWithItems(order, vo.OrderPending)
+	})
+	if errors.Is(err, dependency.ErrConflict) {
+		return nil, ErrOrderChanged
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityOrder, id, AuditUpdate, auditDiff(before, order))
+	publishOrderEvent(s.Events, vo.EventOrderUpdated, *order)
+	return order, nil
+}
+
+// Quote 订单表单的实时报价，id 为 0 表示新订单，否则可售库存包含该订单已预留的数量
+func (s *OrderService) Quote(id int64, items []vo.OrderItem) (vo.OrderQuote, error) {
+	var previous []vo.OrderItem
+	if id != 0 {
+		order, err := s.OrderRepo.Get(id)
+		if err != nil {
+			return vo.OrderQuote{}, err
+		}
+		previous = order.Items
+	}
+	return s.inventory().Quote(previous, items), nil
+}
+
+// SearchProducts 商品选择器：按名称、SKU 等关键词搜索在售商品
+func (s *OrderService) SearchProducts(keyword string) ([]vo.Product, error) {
+	page, err := s.ProductRepo.Finds(vo.ListQuery{Keyword: keyword, Page: 1, PageSize: orderPickerSize},
+		vo.ProductFilter{Status: "active"})
+	return page.Items, err
+}
+
+// Customers 可选为客户的正常状态用户
+func (s *OrderService) Customers() ([]vo.User, error) {
+	page, err := s.UserRepo.Finds(vo.ListQuery{Page: 1, PageSize: 200}, vo.UserFilter{Status: "active"})
+	return page.Items, err
+}
+
+// applyForm 校验表单并写入订单的客户、支付方式和订单项（只有 SKU 和数量），
+// CustomerID 为 0 时保留订单原有客户
+func (s *OrderService) applyForm(order *vo.Order, form vo.OrderFormData) error {
+	items := form.Items()
+	if len(items) == 0 {
+		return ErrOrderItems
+	}
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return ErrOrderItems
+		}
+	}
+	if !slices.Contains(vo.PaymentMethods, form.PaymentMethod) {
+		return fmt.Errorf("%w：%s", ErrOrderPayment, form.PaymentMethod)
+	}
+	if form.CustomerID != 0 {
+		user, err := s.UserRepo.Get(form.CustomerID)
+		if errors.Is(err, dependency.ErrNotFound) || (err == nil && user.Status != "active") {
+			return ErrOrderCustomer
+		}
+		if err != nil {
+			return err
+		}
+		order.CustomerName = user.RealName
+		if order.CustomerName == "" {
+			order.CustomerName = user.Username
+		}
+		order.CustomerEmail = user.Email
+	}
+	order.PaymentMethod = form.PaymentMethod
+	order.Items = items
+	return nil
+}
+
+// ChangeStatus 按状态机更新订单状态并记录状态变更，不允许的流转返回 ErrOrderTransition。
+// 支付时扣减库存，取消时释放预留或退回库存，库存和订单状态在同一个事务中保存。
+func (s *OrderService) ChangeStatus(id int64, status string) (*vo.Order, error) {
+	order, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !order.CanTransit(status) {
+		return nil, fmt.Errorf("%w：%s → %s", ErrOrderTransition, vo.OrderStatusText(order.Status), vo.OrderStatusText(status))
+	}
+	before := *order
+
+	now := time.Now()
+	order.Status = status
+	order.UpdatedAt = now
+	change := &vo.OrderStatusChange{
+		OrderID:   id,
+		From:      before.Status,
+		To:        status,
+		CreatedAt: now,
+	}
+	change.ActorID, change.Actor = currentActor(s.Worker)
+	// 内存模式没有回滚，先做可能因库存不足失败的库存变动，保存失败时由 Tx 执行登记的库存补偿
+	err = s.Tx.Execute(func() error {
+		if err := s.inventory().OnStatusChange(before.Status, status, order.Items); err != nil {
+			return err
+		}
+		return s.OrderRepo.SaveStatus(order, change)
+	})
+	if errors.Is(err, dependency.ErrConflict) {
+		return nil, ErrOrderChanged
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityOrder, id, AuditUpdate, auditDiff(before, order))
+	publishOrderEvent(s.Events, vo.EventOrderStatus, *order)
+	return order, nil
+}
+
+// History 订单的状态变更记录，按时间先后排列
+func (s *OrderService) History(id int64) ([]vo.OrderStatusChange, error) {
+	return s.OrderRepo.FindStatusChanges(id)
+}
+
+// Bulk 批量更新订单状态，目前只支持 set_status，value 为目标状态
+func (s *OrderService) Bulk(ids []int64, action, value string) (vo.BulkResult[vo.Order], error) {
+	if action != BulkSetStatus {
+		return vo.BulkResult[vo.Order]{}, ErrBulkAction
+	}
+	if !slices.Contains(vo.OrderStatuses, value) {
+		return vo.BulkResult[vo.Order]{}, fmt.Errorf("%w: 未知的订单状态 %s", ErrBulkAction, value)
+	}
+	return bulkApply(ids, func(id int64) (vo.Order, error) {
+		order, err := s.ChangeStatus(id, value)
+		if err != nil {
+			return vo.Order{}, err
+		}
+		return *order, nil
+	}), nil
+}
+
+// inventory 订单使用的库存服务
+func (s *OrderService) inventory() inventory {
+	return newInventory(s.Worker, s.ProductRepo, s.AuditRepo, s.Events, s.Tx)
+}
+
+// newOrderNo 按下单时间和进程内序号生成订单号，如 ORD2025012510301512307
+func newOrderNo(now time.Time) string {
+	return fmt.Sprintf("ORD%s%03d%02d", now.Format("20060102150405"), now.Nanosecond()/int(time.Millisecond),
+		orderNoSeq.Add(1)%100)
+}
+
+// totalAmount 订单项小计之和
+func totalAmount(items []vo.OrderItem) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.Subtotal
+	}
+	return roundAmount(total)
+}
+
+// cloneItems 复制订单，订单项不与原订单共用底层数组
+func cloneItems(order vo.Order) vo.Order {
+	order.Items = slices.Clone(order.Items)
+	return order
+}
+
+// roundAmount 金额保留两位小数
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,262 @@
+package domain
+
+import (
+	"errors"
+	"godash/adapter/repository"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/internal/testutil"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestProduct 新建库存为 stock 的在售商品
+func newTestProduct(t *testing.T, stock int) *vo.Product {
+	t.Helper()
+	product := &vo.Product{Name: "测试商品", SKU: testutil.UniqueKey("TEST"), Price: 12.5, Stock: stock, Status: "active"}
+	if err := (&repository.ProductMemoryRepository{}).New(product); err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+// newTestCustomer 新建正常状态的客户
+func newTestCustomer(t *testing.T) int64 {
+	t.Helper()
+	user := &vo.User{Username: testutil.UniqueKey("customer"), RealName: "测试客户", Role: "viewer", Status: "active"}
+	if err := (&repository.UserMemoryRepository{}).New(user); err != nil {
+		t.Fatal(err)
+	}
+	return user.ID
+}
+
+func orderForm(customerID int64, sku string, quantity int) vo.OrderFormData {
+	return vo.OrderFormData{CustomerID: customerID, PaymentMethod: vo.PaymentMethods[0], SKUs: []string{sku}, Quantities: []int{quantity}}
+}
+
+// newTestOrder 为商品创建一个待处理订单
+func newTestOrder(t *testing.T, product *vo.Product, quantity int) *vo.Order {
+	t.Helper()
+	order, err := newTestOrderService().Create(orderForm(newTestCustomer(t), product.SKU, quantity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func getProduct(t *testing.T, id int64) *vo.Product {
+	t.Helper()
+	product, err := (&repository.ProductMemoryRepository{}).Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+// failingOrderRepo 模拟保存时订单已被其他请求修改或订单号重复
+type failingOrderRepo struct {
+	dependency.OrderRepo
+}
+
+func (failingOrderRepo) New(*vo.Order) error {
+	return dependency.ErrDuplicate
+}
+
+func (failingOrderRepo) SaveWithItems(*vo.Order, string) error {
+	return dependency.ErrConflict
+}
+
+func (failingOrderRepo) SaveStatus(*vo.Order, *vo.OrderStatusChange) error {
+	return dependency.ErrConflict
+}
+
+func TestOrderCreateReservesStockConcurrently(t *testing.T) {
+	product := newTestProduct(t, 10)
+	customer := newTestCustomer(t)
+
+	var mu sync.Mutex
+	orderNos := map[string]bool{}
+	var short atomic.Int32
+	testutil.Parallel(15, func(int) {
+		order, err := newTestOrderService().Create(orderForm(customer, product.SKU, 1))
+		switch {
+		case err == nil:
+			mu.Lock()
+			orderNos[order.OrderNo] = true
+			mu.Unlock()
+		case errors.Is(err, ErrStockShort):
+			short.Add(1)
+		default:
+			t.Errorf("Create: %v", err)
+		}
+	})
+
+	got := getProduct(t, product.ID)
+	if len(orderNos) != 10 || short.Load() != 5 {
+		t.Errorf("created %d orders, %d short of stock; want 10 and 5", len(orderNos), short.Load())
+	}
+	if got.Stock != 10 || got.Reserved != 10 || got.Status != "out_of_stock" {
+		t.Errorf("product stock %d reserved %d status %s; want 10, 10, out_of_stock", got.Stock, got.Reserved, got.Status)
+	}
+}
+
+func TestOrderChangeStatusConcurrently(t *testing.T) {
+	for round := 0; round < 10; round++ {
+		product := newTestProduct(t, 10)
+		order := newTestOrder(t, product, 3)
+
+		// 同时支付和取消同一个订单：每个状态只能成功流转一次（已支付的订单仍可取消），
+		// 失败的请求做过的库存变动必须撤销
+		var won atomic.Int32
+		testutil.Parallel(20, func(i int) {
+			status := vo.OrderPaid
+			if i%2 == 1 {
+				status = vo.OrderCancelled
+			}
+			_, err := newTestOrderService().ChangeStatus(order.ID, status)
+			switch {
+			case err == nil:
+				won.Add(1)
+			case errors.Is(err, ErrOrderChanged), errors.Is(err, ErrOrderTransition):
+			default:
+				t.Errorf("ChangeStatus: %v", err)
+			}
+		})
+
+		current, err := newTestOrderService().Get(order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history, err := newTestOrderService().History(order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if won.Load() == 0 || int(won.Load()) != len(history) || history[len(history)-1].To != current.Status {
+			t.Fatalf("won %d, history %v, status %s; want one change per success", won.Load(), history, current.Status)
+		}
+		for i, change := range history {
+			from := vo.OrderPending
+			if i > 0 {
+				from = history[i-1].To
+			}
+			if change.From != from {
+				t.Errorf("change %d from %s, want %s", i, change.From, from)
+			}
+		}
+		stock := 10
+		if current.Status == vo.OrderPaid {
+			stock = 7
+		}
+		if got := getProduct(t, product.ID); got.Stock != stock || got.Reserved != 0 {
+			t.Errorf("order %s: product stock %d reserved %d; want %d and 0", current.Status, got.Stock, got.Reserved, stock)
+		}
+	}
+}
+
+// productAudits 商品的审计日志条数，只查最近的 100 条
+func productAudits(t *testing.T, productID int64) int {
+	t.Helper()
+	page, err := (&repository.AuditMemoryRepository{}).Finds(vo.ListQuery{Page: 1, PageSize: 100}, vo.AuditFilter{Entity: AuditEntityProduct})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, log := range page.Items {
+		if log.EntityID == productID {
+			count++
+		}
+	}
+	return count
+}
+
+func TestOrderChangeStatusFailureUndoesStock(t *testing.T) {
+	product := newTestProduct(t, 10)
+	order := newTestOrder(t, product, 2)
+	audits := productAudits(t, product.ID)
+
+	// 保存状态时订单已被其他请求修改，已经扣减的库存要恢复，也不留下库存变动的审计日志
+	service := newTestOrderService()
+	service.OrderRepo = failingOrderRepo{service.OrderRepo}
+	if _, err := service.ChangeStatus(order.ID, vo.OrderPaid); !errors.Is(err, ErrOrderChanged) {
+		t.Errorf("ChangeStatus on a changed order: %v, want ErrOrderChanged", err)
+	}
+	if got := getProduct(t, product.ID); got.Stock != 10 || got.Reserved != 2 {
+		t.Errorf("product stock %d reserved %d after a failed save; want 10 and 2", got.Stock, got.Reserved)
+	}
+	if got := productAudits(t, product.ID); got != audits {
+		t.Errorf("%d product audit logs after a failed save, want %d", got, audits)
+	}
+
+	if _, err := newTestOrderService().ChangeStatus(order.ID, vo.OrderPaid); err != nil {
+		t.Fatal(err)
+	}
+	if got := productAudits(t, product.ID); got != audits+1 {
+		t.Errorf("%d product audit logs after paying, want %d", got, audits+1)
+	}
+}
+
+func TestOrderSaveFailureUndoesReservation(t *testing.T) {
+	product := newTestProduct(t, 10)
+	order := newTestOrder(t, product, 2)
+
+	failing := func() *OrderService {
+		service := newTestOrderService()
+		service.OrderRepo = failingOrderRepo{service.OrderRepo}
+		return service
+	}
+	if _, err := failing().Create(orderForm(newTestCustomer(t), product.SKU, 3)); !errors.Is(err, ErrOrderNoTaken) {
+		t.Errorf("Create with duplicate order numbers: %v, want ErrOrderNoTaken", err)
+	}
+	if _, err := failing().Update(order.ID, orderForm(0, product.SKU, 5)); !errors.Is(err, ErrOrderChanged) {
+		t.Errorf("Update on a changed order: %v, want ErrOrderChanged", err)
+	}
+	if got := getProduct(t, product.ID); got.Stock != 10 || got.Reserved != 2 {
+		t.Errorf("product stock %d reserved %d after failed saves; want 10 and 2", got.Stock, got.Reserved)
+	}
+}
+
+func TestOrderUpdateAdjustsReservation(t *testing.T) {
+	product := newTestProduct(t, 10)
+	order := newTestOrder(t, product, 2)
+
+	updated, err := newTestOrderService().Update(order.ID, orderForm(0, product.SKU, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.TotalAmount != 75 {
+		t.Errorf("total amount %.2f, want 75", updated.TotalAmount)
+	}
+	if got := getProduct(t, product.ID); got.Reserved != 6 {
+		t.Errorf("reserved %d, want 6", got.Reserved)
+	}
+
+	if _, err := newTestOrderService().Update(order.ID, orderForm(0, product.SKU, 11)); !errors.Is(err, ErrStockShort) {
+		t.Errorf("Update beyond stock: %v, want ErrStockShort", err)
+	}
+	if _, err := newTestOrderService().ChangeStatus(order.ID, vo.OrderCancelled); err != nil {
+		t.Fatal(err)
+	}
+	if got := getProduct(t, product.ID); got.Reserved != 0 || got.Stock != 10 {
+		t.Errorf("after cancel: stock %d reserved %d; want 10 and 0", got.Stock, got.Reserved)
+	}
+	if _, err := newTestOrderService().Update(order.ID, orderForm(0, product.SKU, 1)); !errors.Is(err, ErrOrderLocked) {
+		t.Errorf("Update of a cancelled order: %v, want ErrOrderLocked", err)
+	}
+}
+
+func TestNewOrderNoUnique(t *testing.T) {
+	now := time.Now()
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	testutil.Parallel(100, func(int) {
+		orderNo := newOrderNo(now)
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[orderNo] {
+			t.Errorf("duplicate order number %s", orderNo)
+		}
+		seen[orderNo] = true
+	})
+}
@@ -0,0 +1,60 @@
+package po
+
+import (
+	"encoding/json"
+	"godash/domain/vo"
+	"time"
+)
+
+// AuditLog 审计日志持久化对象，字段差异以 JSON 保存
+type AuditLog struct {
+	ID        int64     `gorm:"primaryKey;column:id"`
+	ActorID   int64     `gorm:"column:actor_id"`
+	Actor     string    `gorm:"column:actor;size:50;index"`
+	Entity    string    `gorm:"column:entity;size:32;index:idx_audit_entity"`
+	EntityID  int64     `gorm:"column:entity_id;index:idx_audit_entity"`
+	Action    string    `gorm:"column:action;size:16"`
+	Changes   string    `gorm:"column:changes;type:text"`
+	RequestID string    `gorm:"column:request_id;size:64"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// TableName .
+func (obj *AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// NewAuditLog 由值对象创建持久化对象
+func NewAuditLog(log vo.AuditLog) *AuditLog {
+	changes, _ := json.Marshal(log.Changes)
+	return &AuditLog{
+		ID:        log.ID,
+		ActorID:   log.ActorID,
+		Actor:     log.Actor,
+		Entity:    log.Entity,
+		EntityID:  log.EntityID,
+		Action:    log.Action,
+		Changes:   string(changes),
+		RequestID: log.RequestID,
+		CreatedAt: log.CreatedAt,
+	}
+}
+
+// ToVO 转换为值对象
+func (obj *AuditLog) ToVO() vo.AuditLog {
+	var changes []vo.AuditChange
+	if obj.Changes != "" {
+		_ = json.Unmarshal([]byte(obj.Changes), &changes)
+	}
+	return vo.AuditLog{
+		ID:        obj.ID,
+		ActorID:   obj.ActorID,
+		Actor:     obj.Actor,
+		Entity:    obj.Entity,
+		EntityID:  obj.EntityID,
+		Action:    obj.Action,
+		Changes:   changes,
+		RequestID: obj.RequestID,
+		CreatedAt: obj.CreatedAt,
+	}
+}
@@ -0,0 +1,66 @@
+package po
+
+import (
+	"godash/domain/vo"
+	"time"
+)
+
+// Notification 站内通知持久化对象
+type Notification struct {
+	ID        int64      `gorm:"primaryKey;column:id"`
+	UserID    int64      `gorm:"column:user_id;index:idx_notification_user"`
+	Topic     string     `gorm:"column:topic;size:32"`
+	Title     string     `gorm:"column:title;size:64"`
+	Content   string     `gorm:"column:content;size:255"`
+	Link      string     `gorm:"column:link;size:255"`
+	Entity    string     `gorm:"column:entity;size:32"`
+	EntityID  int64      `gorm:"column:entity_id"`
+	Read      bool       `gorm:"column:is_read;index:idx_notification_user"`
+	ReadAt    *time.Time `gorm:"column:read_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+}
+
+// TableName .
+func (obj *Notification) TableName() string {
+	return "notification"
+}
+
+// NewNotification 由值对象创建持久化对象
+func NewNotification(notification vo.Notification) *Notification {
+	result := &Notification{
+		ID:        notification.ID,
+		UserID:    notification.UserID,
+		Topic:     notification.Topic,
+		Title:     notification.Title,
+		Content:   notification.Content,
+		Link:      notification.Link,
+		Entity:    notification.Entity,
+		EntityID:  notification.EntityID,
+		Read:      notification.Read,
+		CreatedAt: notification.CreatedAt,
+	}
+	if notification.Read {
+		result.ReadAt = &notification.ReadAt
+	}
+	return result
+}
+
+// ToVO 转换为值对象
+func (obj *Notification) ToVO() vo.Notification {
+	result := vo.Notification{
+		ID:        obj.ID,
+		UserID:    obj.UserID,
+		Topic:     obj.Topic,
+		Title:     obj.Title,
+		Content:   obj.Content,
+		Link:      obj.Link,
+		Entity:    obj.Entity,
+		EntityID:  obj.EntityID,
+		Read:      obj.Read,
+		CreatedAt: obj.CreatedAt,
+	}
+	if obj.ReadAt != nil {
+		result.ReadAt = *obj.ReadAt
+	}
+	return result
+}
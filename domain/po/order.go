@@ -0,0 +1,136 @@
+package po
+
+import (
+	"godash/domain/vo"
+	"time"
+)
+
+// Order 订单持久化对象
+type Order struct {
+	ID            int64       `gorm:"primaryKey;column:id"`
+	OrderNo       string      `gorm:"column:order_no;size:64;uniqueIndex"`
+	CustomerName  string      `gorm:"column:customer_name;size:64"`
+	CustomerEmail string      `gorm:"column:customer_email;size:128"`
+	TotalAmount   float64     `gorm:"column:total_amount"`
+	Status        string      `gorm:"column:status;size:32;index"`
+	PaymentMethod string      `gorm:"column:payment_method;size:32"`
+	Items         []OrderItem `gorm:"foreignKey:OrderID"`
+	CreatedAt     time.Time   `gorm:"column:created_at;index"`
+	UpdatedAt     time.Time   `gorm:"column:updated_at"`
+}
+
+// TableName .
+func (obj *Order) TableName() string {
+	return "order"
+}
+
+// OrderItem 订单项持久化对象
+type OrderItem struct {
+	ID          int64   `gorm:"primaryKey;column:id"`
+	OrderID     int64   `gorm:"column:order_id;index"`
+	ProductName string  `gorm:"column:product_name;size:128"`
+	SKU         string  `gorm:"column:sku;size:64;index"`
+	Quantity    int     `gorm:"column:quantity"`
+	Price       float64 `gorm:"column:price"`
+	Subtotal    float64 `gorm:"column:subtotal"`
+}
+
+// TableName .
+func (obj *OrderItem) TableName() string {
+	return "order_item"
+}
+
+// NewOrder 由值对象创建持久化对象
+func NewOrder(order vo.Order) *Order {
+	result := &Order{
+		ID:            order.ID,
+		OrderNo:       order.OrderNo,
+		CustomerName:  order.CustomerName,
+		CustomerEmail: order.CustomerEmail,
+		TotalAmount:   order.TotalAmount,
+		Status:        order.Status,
+		PaymentMethod: order.PaymentMethod,
+		CreatedAt:     order.CreatedAt,
+		UpdatedAt:     order.UpdatedAt,
+	}
+	for _, item := range order.Items {
+		result.Items = append(result.Items, OrderItem{
+			ID:          item.ID,
+			OrderID:     order.ID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+			Subtotal:    item.Subtotal,
+		})
+	}
+	return result
+}
+
+// ToVO 转换为值对象
+func (obj *Order) ToVO() vo.Order {
+	result := vo.Order{
+		ID:            obj.ID,
+		OrderNo:       obj.OrderNo,
+		CustomerName:  obj.CustomerName,
+		CustomerEmail: obj.CustomerEmail,
+		TotalAmount:   obj.TotalAmount,
+		Status:        obj.Status,
+		PaymentMethod: obj.PaymentMethod,
+		CreatedAt:     obj.CreatedAt,
+		UpdatedAt:     obj.UpdatedAt,
+	}
+	for _, item := range obj.Items {
+		result.Items = append(result.Items, vo.OrderItem{
+			ID:          item.ID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+			Subtotal:    item.Subtotal,
+		})
+	}
+	return result
+}
+
+// OrderStatusChange 订单状态变更记录持久化对象
+type OrderStatusChange struct {
+	ID        int64     `gorm:"primaryKey;column:id"`
+	OrderID   int64     `gorm:"column:order_id;index"`
+	From      string    `gorm:"column:from_status;size:32"`
+	To        string    `gorm:"column:to_status;size:32"`
+	ActorID   int64     `gorm:"column:actor_id"`
+	Actor     string    `gorm:"column:actor;size:50"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// TableName .
+func (obj *OrderStatusChange) TableName() string {
+	return "order_status_change"
+}
+
+// NewOrderStatusChange 由值对象创建持久化对象
+func NewOrderStatusChange(change vo.OrderStatusChange) *OrderStatusChange {
+	return &OrderStatusChange{
+		ID:        change.ID,
+		OrderID:   change.OrderID,
+		From:      change.From,
+		To:        change.To,
+		ActorID:   change.ActorID,
+		Actor:     change.Actor,
+		CreatedAt: change.CreatedAt,
+	}
+}
+
+// ToVO 转换为值对象
+func (obj *OrderStatusChange) ToVO() vo.OrderStatusChange {
+	return vo.OrderStatusChange{
+		ID:        obj.ID,
+		OrderID:   obj.OrderID,
+		From:      obj.From,
+		To:        obj.To,
+		ActorID:   obj.ActorID,
+		Actor:     obj.Actor,
+		CreatedAt: obj.CreatedAt,
+	}
+}
@@ -1,2 +1,21 @@
 // Package po generated by 'freedom new-project godash'
 package po
+
+//go:generate go run ../../cmd/pogen -schema schema.json -po . -repo ../../adapter/repository
+
+// Models 全部持久化对象，用于启动时自动迁移
+func Models() []interface{} {
+	return append([]interface{}{
+		&User{},
+		&Product{},
+		&Order{},
+		&OrderItem{},
+		&OrderStatusChange{},
+		&Refund{},
+		&RefundItem{},
+		&Role{},
+		&AuditLog{},
+		&Notification{},
+		&SettingVersion{},
+	}, generatedModels...)
+}
@@ -0,0 +1,63 @@
+package po
+
+import (
+	"godash/domain/vo"
+	"time"
+)
+
+// Product 商品持久化对象
+type Product struct {
+	ID          int64     `gorm:"primaryKey;column:id"`
+	Name        string    `gorm:"column:name;size:128"`
+	SKU         string    `gorm:"column:sku;size:64;uniqueIndex"`
+	Category    string    `gorm:"column:category;size:64;index"`
+	Price       float64   `gorm:"column:price"`
+	Stock       int       `gorm:"column:stock"`
+	Reserved    int       `gorm:"column:reserved;not null;default:0"`
+	Status      string    `gorm:"column:status;size:32;index"`
+	Image       string    `gorm:"column:image;size:255"`
+	Description string    `gorm:"column:description;type:text"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+// TableName .
+func (obj *Product) TableName() string {
+	return "product"
+}
+
+// NewProduct 由值对象创建持久化对象
+func NewProduct(product vo.Product) *Product {
+	return &Product{
+		ID:          product.ID,
+		Name:        product.Name,
+		SKU:         product.SKU,
+		Category:    product.Category,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Reserved:    product.Reserved,
+		Status:      product.Status,
+		Image:       product.Image,
+		Description: product.Description,
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+	}
+}
+
+// ToVO 转换为值对象
+func (obj *Product) ToVO() vo.Product {
+	return vo.Product{
+		ID:          obj.ID,
+		Name:        obj.Name,
+		SKU:         obj.SKU,
+		Category:    obj.Category,
+		Price:       obj.Price,
+		Stock:       obj.Stock,
+		Reserved:    obj.Reserved,
+		Status:      obj.Status,
+		Image:       obj.Image,
+		Description: obj.Description,
+		CreatedAt:   obj.CreatedAt,
+		UpdatedAt:   obj.UpdatedAt,
+	}
+}
@@ -0,0 +1,97 @@
+package po
+
+import (
+	"godash/domain/vo"
+	"time"
+)
+
+// Refund 退款记录持久化对象
+type Refund struct {
+	ID        int64        `gorm:"primaryKey;column:id"`
+	OrderID   int64        `gorm:"column:order_id;index"`
+	Amount    float64      `gorm:"column:amount"`
+	Reason    string       `gorm:"column:reason;size:255"`
+	Status    string       `gorm:"column:status;size:32;index"`
+	Restock   bool         `gorm:"column:restock"`
+	Items     []RefundItem `gorm:"foreignKey:RefundID"`
+	ActorID   int64        `gorm:"column:actor_id"`
+	Actor     string       `gorm:"column:actor;size:50"`
+	CreatedAt time.Time    `gorm:"column:created_at"`
+	UpdatedAt time.Time    `gorm:"column:updated_at"`
+}
+
+// TableName .
+func (obj *Refund) TableName() string {
+	return "refund"
+}
+
+// RefundItem 退款项持久化对象
+type RefundItem struct {
+	ID          int64   `gorm:"primaryKey;column:id"`
+	RefundID    int64   `gorm:"column:refund_id;index"`
+	OrderItemID int64   `gorm:"column:order_item_id"`
+	ProductName string  `gorm:"column:product_name;size:128"`
+	SKU         string  `gorm:"column:sku;size:64"`
+	Quantity    int     `gorm:"column:quantity"`
+	Amount      float64 `gorm:"column:amount"`
+}
+
+// TableName .
+func (obj *RefundItem) TableName() string {
+	return "refund_item"
+}
+
+// NewRefund 由值对象创建持久化对象
+func NewRefund(refund vo.Refund) *Refund {
+	result := &Refund{
+		ID:        refund.ID,
+		OrderID:   refund.OrderID,
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+		Status:    refund.Status,
+		Restock:   refund.Restock,
+		ActorID:   refund.ActorID,
+		Actor:     refund.Actor,
+		CreatedAt: refund.CreatedAt,
+		UpdatedAt: refund.UpdatedAt,
+	}
+	for _, item := range refund.Items {
+		result.Items = append(result.Items, RefundItem{
+			ID:          item.ID,
+			RefundID:    refund.ID,
+			OrderItemID: item.OrderItemID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		})
+	}
+	return result
+}
+
+// ToVO 转换为值对象
+func (obj *Refund) ToVO() vo.Refund {
+	result := vo.Refund{
+		ID:        obj.ID,
+		OrderID:   obj.OrderID,
+		Amount:    obj.Amount,
+		Reason:    obj.Reason,
+		Status:    obj.Status,
+		Restock:   obj.Restock,
+		ActorID:   obj.ActorID,
+		Actor:     obj.Actor,
+		CreatedAt: obj.CreatedAt,
+		UpdatedAt: obj.UpdatedAt,
+	}
+	for _, item := range obj.Items {
+		result.Items = append(result.Items, vo.RefundItem{
+			ID:          item.ID,
+			OrderItemID: item.OrderItemID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			Amount:      item.Amount,
+		})
+	}
+	return result
+}
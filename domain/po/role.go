@@ -0,0 +1,56 @@
+package po
+
+import (
+	"godash/domain/vo"
+	"strings"
+	"time"
+)
+
+// Role 角色持久化对象，权限以逗号分隔保存
+type Role struct {
+	ID          int64     `gorm:"primaryKey;column:id"`
+	Code        string    `gorm:"column:code;size:32;uniqueIndex"`
+	Name        string    `gorm:"column:name;size:64"`
+	Description string    `gorm:"column:description;size:255"`
+	Permissions string    `gorm:"column:permissions;type:text"`
+	System      bool      `gorm:"column:system"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+// TableName .
+func (obj *Role) TableName() string {
+	return "role"
+}
+
+// NewRole 由值对象创建持久化对象
+func NewRole(role vo.Role) *Role {
+	return &Role{
+		ID:          role.ID,
+		Code:        role.Code,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: strings.Join(role.Permissions, ","),
+		System:      role.System,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// ToVO 转换为值对象
+func (obj *Role) ToVO() vo.Role {
+	var permissions []string
+	if obj.Permissions != "" {
+		permissions = strings.Split(obj.Permissions, ",")
+	}
+	return vo.Role{
+		ID:          obj.ID,
+		Code:        obj.Code,
+		Name:        obj.Name,
+		Description: obj.Description,
+		Permissions: permissions,
+		System:      obj.System,
+		CreatedAt:   obj.CreatedAt,
+		UpdatedAt:   obj.UpdatedAt,
+	}
+}
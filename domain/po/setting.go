@@ -0,0 +1,51 @@
+package po
+
+import (
+	"encoding/json"
+	"godash/domain/vo"
+	"time"
+)
+
+// SettingVersion 系统设置版本持久化对象，设置以 JSON 保存，增加设置项不需要迁移表结构
+type SettingVersion struct {
+	Version      int       `gorm:"primaryKey;autoIncrement:false;column:version"`
+	Data         string    `gorm:"column:data;type:text"`
+	AuthorID     int64     `gorm:"column:author_id"`
+	Author       string    `gorm:"column:author;size:64"`
+	RestoredFrom int       `gorm:"column:restored_from"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+}
+
+// TableName .
+func (obj *SettingVersion) TableName() string {
+	return "setting_version"
+}
+
+// NewSettingVersion 由值对象创建持久化对象
+func NewSettingVersion(version vo.SettingsVersion) (*SettingVersion, error) {
+	data, err := json.Marshal(version.Settings)
+	if err != nil {
+		return nil, err
+	}
+	return &SettingVersion{
+		Version:      version.Version,
+		Data:         string(data),
+		AuthorID:     version.AuthorID,
+		Author:       version.Author,
+		RestoredFrom: version.RestoredFrom,
+		CreatedAt:    version.CreatedAt,
+	}, nil
+}
+
+// ToVO 转换为值对象
+func (obj *SettingVersion) ToVO() (vo.SettingsVersion, error) {
+	result := vo.SettingsVersion{
+		Version:      obj.Version,
+		AuthorID:     obj.AuthorID,
+		Author:       obj.Author,
+		RestoredFrom: obj.RestoredFrom,
+		CreatedAt:    obj.CreatedAt,
+	}
+	err := json.Unmarshal([]byte(obj.Data), &result.Settings)
+	return result, err
+}
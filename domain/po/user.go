@@ -0,0 +1,60 @@
+package po
+
+import (
+	"godash/domain/vo"
+	"time"
+)
+
+// User 用户持久化对象
+type User struct {
+	ID        int64     `gorm:"primaryKey;column:id"`
+	Username  string    `gorm:"column:username;size:64;uniqueIndex"`
+	Email     string    `gorm:"column:email;size:128"`
+	RealName  string    `gorm:"column:real_name;size:64"`
+	Phone     string    `gorm:"column:phone;size:32"`
+	Role      string    `gorm:"column:role;size:32;index"`
+	Status    string    `gorm:"column:status;size:32;index"`
+	Avatar    string    `gorm:"column:avatar;size:255"`
+	Password  string    `gorm:"column:password;size:100"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// TableName .
+func (obj *User) TableName() string {
+	return "user"
+}
+
+// NewUser 由值对象创建持久化对象
+func NewUser(user vo.User) *User {
+	return &User{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		RealName:  user.RealName,
+		Phone:     user.Phone,
+		Role:      user.Role,
+		Status:    user.Status,
+		Avatar:    user.Avatar,
+		Password:  user.Password,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// ToVO 转换为值对象
+func (obj *User) ToVO() vo.User {
+	return vo.User{
+		ID:        obj.ID,
+		Username:  obj.Username,
+		Email:     obj.Email,
+		RealName:  obj.RealName,
+		Phone:     obj.Phone,
+		Role:      obj.Role,
+		Status:    obj.Status,
+		Avatar:    obj.Avatar,
+		Password:  obj.Password,
+		CreatedAt: obj.CreatedAt,
+		UpdatedAt: obj.UpdatedAt,
+	}
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *ProductService {
+			return &ProductService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *ProductService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+	})
+}
+
+// ErrSKUExists SKU 已存在
+var ErrSKUExists = errors.New("SKU 已存在，请使用其他 SKU")
+
+// ProductService 商品领域服务
+type ProductService struct {
+	Worker      freedom.Worker
+	ProductRepo dependency.ProductRepo
+	AuditRepo   dependency.AuditRepo
+	Events      dependency.EventBus
+}
+
+// List 按查询规格和筛选条件分页查询商品
+func (s *ProductService) List(query vo.ListQuery, filter vo.ProductFilter) (vo.Page[vo.Product], error) {
+	return s.ProductRepo.Finds(query, filter)
+}
+
+// Export 按关键词和筛选条件逐条读取商品，用于导出
+func (s *ProductService) Export(query vo.ListQuery, filter vo.ProductFilter, fn func(vo.Product) error) error {
+	return eachRow(query, func(q vo.ListQuery) (vo.Page[vo.Product], error) {
+		return s.ProductRepo.Finds(q, filter)
+	}, fn)
+}
+
+// Get 获取商品
+func (s *ProductService) Get(id int64) (*vo.Product, error) {
+	return s.ProductRepo.Get(id)
+}
+
+// Create 创建商品
+func (s *ProductService) Create(formData vo.ProductFormData) (*vo.Product, error) {
+	// 检查 SKU 是否已存在
+	_, err := s.ProductRepo.FindBySKU(formData.SKU)
+	if err == nil {
+		return nil, ErrSKUExists
+	}
+	if !errors.Is(err, dependency.ErrNotFound) {
+		return nil, err
+	}
+
+	product := newProduct(formData, time.Now())
+	if err := s.ProductRepo.New(product); err != nil {
+		if errors.Is(err, dependency.ErrDuplicate) {
+			return nil, ErrSKUExists
+		}
+		return nil, err
+	}
+	s.Worker.Logger().Info("创建商品", freedom.LogFields{"id": product.ID, "sku": product.SKU})
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityProduct, product.ID, AuditCreate, auditDiff(nil, product))
+	return product, nil
+}
+
+// Import 批量导入商品。rows 已完成字段校验，这里再检查 SKU 在文件内和库中是否重复；
+// 有任一行出错或 dryRun 时只返回报告，否则在一个事务中写入全部商品
+func (s *ProductService) Import(rows []vo.ProductImportRow, dryRun bool) (*vo.ProductImportReport, error) {
+	report := &vo.ProductImportReport{DryRun: dryRun, Rows: rows}
+	seen := make(map[string]int, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if sku := row.Data.SKU; sku != "" {
+			if line, ok := seen[sku]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("SKU 与第 %d 行重复", line))
+			} else {
+				seen[sku] = row.Line
+				_, err := s.ProductRepo.FindBySKU(sku)
+				if err == nil {
+					row.Errors = append(row.Errors, ErrSKUExists.Error())
+				} else if !errors.Is(err, dependency.ErrNotFound) {
+					return nil, err
+				}
+			}
+		}
+		if len(row.Errors) > 0 {
+			report.Failed++
+		}
+	}
+	if dryRun || report.Failed > 0 || len(rows) == 0 {
+		return report, nil
+	}
+
+	now := time.Now()
+	products := make([]*vo.Product, len(rows))
+	for i, row := range rows {
+		products[i] = newProduct(row.Data, now)
+	}
+	if err := s.ProductRepo.NewBatch(products); err != nil {
+		if errors.Is(err, dependency.ErrDuplicate) {
+			return nil, ErrSKUExists
+		}
+		return nil, err
+	}
+	report.Imported = len(products)
+	s.Worker.Logger().Info("导入商品", freedom.LogFields{"count": len(products)})
+	for _, product := range products {
+		recordAudit(s.Worker, s.AuditRepo, AuditEntityProduct, product.ID, AuditCreate, auditDiff(nil, product))
+	}
+	return report, nil
+}
+
+// Update 更新商品信息（SKU 不可修改）
+func (s *ProductService) Update(id int64, formData vo.ProductFormData) (*vo.Product, error) {
+	product, err := s.ProductRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *product
+
+	product.Name = formData.Name
+	product.Category = formData.Category
+	product.Price = formData.Price
+	product.Stock = formData.Stock
+	product.Status = formData.Status
+	product.Description = formData.Description
+	product.SyncStockStatus()
+	product.UpdatedAt = time.Now()
+	if err := s.ProductRepo.Save(product); err != nil {
+		return nil, err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityProduct, id, AuditUpdate, auditDiff(before, product))
+	publishLowStock(s.Events, before, *product)
+	return product, nil
+}
+
+// Delete 删除商品
+func (s *ProductService) Delete(id int64) error {
+	_, err := s.delete(id)
+	return err
+}
+
+// delete 删除商品并记录审计日志，返回被删除的商品
+func (s *ProductService) delete(id int64) (*vo.Product, error) {
+	product, err := s.ProductRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ProductRepo.Delete(id); err != nil {
+		return nil, err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityProduct, id, AuditDelete, auditDiff(product, nil))
+	return product, nil
+}
+
+// Bulk 批量上架、下架或删除商品
+func (s *ProductService) Bulk(ids []int64, action string) (vo.BulkResult[vo.Product], error) {
+	status := ""
+	switch action {
+	case BulkDelete:
+	case BulkActivate:
+		status = "active"
+	case BulkDeactivate:
+		status = "inactive"
+	default:
+		return vo.BulkResult[vo.Product]{}, ErrBulkAction
+	}
+
+	return bulkApply(ids, func(id int64) (vo.Product, error) {
+		if status == "" {
+			product, err := s.delete(id)
+			if err != nil {
+				return vo.Product{}, err
+			}
+			return *product, nil
+		}
+		product, err := s.ProductRepo.Get(id)
+		if err != nil {
+			return vo.Product{}, err
+		}
+		before := *product
+		product.Status = status
+		product.UpdatedAt = time.Now()
+		if err := s.ProductRepo.Save(product); err != nil {
+			return vo.Product{}, err
+		}
+		recordAudit(s.Worker, s.AuditRepo, AuditEntityProduct, id, AuditUpdate, auditDiff(before, product))
+		return *product, nil
+	}), nil
+}
+
+// newProduct 由表单数据生成新商品，状态按库存校正
+func newProduct(formData vo.ProductFormData, now time.Time) *vo.Product {
+	product := &vo.Product{
+		Name:        formData.Name,
+		SKU:         formData.SKU,
+		Category:    formData.Category,
+		Price:       formData.Price,
+		Stock:       formData.Stock,
+		Status:      formData.Status,
+		Image:       fmt.Sprintf("https://via.placeholder.com/300x200?text=%s", formData.Name),
+		Description: formData.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	product.SyncStockStatus()
+	return product
+}
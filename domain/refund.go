@@ -0,0 +1,305 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *RefundService {
+			return &RefundService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *RefundService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+	})
+}
+
+// ErrRefundNotAllowed 订单未付款、已取消或已全额退款
+var ErrRefundNotAllowed = errors.New("订单当前状态不能退款")
+
+// ErrRefundItems 没有选择要退款的商品，或商品不属于该订单
+var ErrRefundItems = errors.New("请选择要退款的商品和数量")
+
+// ErrRefundExceeded 退款数量超过订单项剩余可退数量
+var ErrRefundExceeded = errors.New("退款数量超过可退数量")
+
+// ErrRefundProcessed 退款已经确认或拒绝
+var ErrRefundProcessed = errors.New("退款已处理，请刷新后重试")
+
+// RefundService 退款领域服务：申请退款时按订单项单价计算金额，确认后退回库存并把订单标记为部分退款或已退款
+type RefundService struct {
+	Worker      freedom.Worker
+	OrderRepo   dependency.OrderRepo
+	ProductRepo dependency.ProductRepo
+	RefundRepo  dependency.RefundRepo
+	AuditRepo   dependency.AuditRepo
+	Tx          dependency.Transaction
+	Events      dependency.EventBus
+}
+
+// List 订单的退款记录，按申请时间先后排列
+func (s *RefundService) List(orderID int64) ([]vo.Refund, error) {
+	return s.RefundRepo.FindByOrder(orderID)
+}
+
+// Create 申请退款：全额退款退还每个订单项剩余的可退数量，部分退款按表单中的数量；
+// 待确认和已退款的数量都不能再次申请
+func (s *RefundService) Create(orderID int64, form vo.RefundFormData) (*vo.Refund, error) {
+	order, err := s.OrderRepo.Get(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Refundable() {
+		return nil, ErrRefundNotAllowed
+	}
+	refunds, err := s.RefundRepo.FindByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refund := vo.Refund{
+		OrderID:   orderID,
+		Reason:    form.Reason,
+		Status:    vo.RefundPending,
+		Restock:   form.Restock,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	refund.ActorID, refund.Actor = currentActor(s.Worker)
+	if refund.Items, err = refundItems(*order, refunds, form); err != nil {
+		return nil, err
+	}
+	for _, item := range refund.Items {
+		refund.Amount += item.Amount
+	}
+	// 没有订单项的早期订单只能按剩余金额全额退款
+	if len(order.Items) == 0 && form.Full != "" {
+		refund.Amount = order.TotalAmount - vo.RefundedAmount(refunds)
+	}
+	refund.Amount = roundAmount(refund.Amount)
+	if refund.Amount <= 0 && len(refund.Items) == 0 {
+		return nil, ErrRefundItems
+	}
+
+	if err := s.RefundRepo.New(&refund); err != nil {
+		return nil, err
+	}
+	s.Worker.Logger().Info("申请退款", freedom.LogFields{"order_id": orderID, "refund_id": refund.ID, "amount": refund.Amount})
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityRefund, refund.ID, AuditCreate, auditDiff(nil, refund))
+	return &refund, nil
+}
+
+// Complete 确认退款：退款状态、订单状态和库存在同一个事务中保存。
+// 锁定订单的退款后检查已确认的数量加上本次不超过订单项数量，并发申请的退款不会重复确认；
+// 按已确认的退款数量把订单标记为部分退款或已退款，Restock 为 true 时把退款数量退回库存
+func (s *RefundService) Complete(id int64) (*vo.Refund, error) {
+	refund, err := s.RefundRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != vo.RefundPending {
+		return nil, ErrRefundProcessed
+	}
+	order, err := s.OrderRepo.Get(refund.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.Refundable() {
+		return nil, ErrRefundNotAllowed
+	}
+	before := *refund
+
+	now := time.Now()
+	refund.Status = vo.RefundCompleted
+	refund.UpdatedAt = now
+	// 内存模式没有回滚，先保存可能冲突的状态，最后退回库存
+	err = s.Tx.Execute(func() error {
+		if err := s.completeRefund(*order, refund); err != nil {
+			return err
+		}
+		if err := s.markOrder(order, now); err != nil {
+			return err
+		}
+		if !refund.Restock {
+			return nil
+		}
+		items := make([]vo.OrderItem, 0, len(refund.Items))
+		for _, item := range refund.Items {
+			items = append(items, vo.OrderItem{SKU: item.SKU, Quantity: item.Quantity})
+		}
+		return newInventory(s.Worker, s.ProductRepo, s.AuditRepo, s.Events, s.Tx).Restock(items)
+	})
+	if errors.Is(err, dependency.ErrConflict) {
+		return nil, ErrRefundProcessed
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityRefund, id, AuditUpdate, auditDiff(before, refund))
+	return refund, nil
+}
+
+// Reject 拒绝退款，拒绝后数量可以重新申请
+func (s *RefundService) Reject(id int64) (*vo.Refund, error) {
+	refund, err := s.RefundRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != vo.RefundPending {
+		return nil, ErrRefundProcessed
+	}
+	before := *refund
+
+	refund.Status = vo.RefundRejected
+	refund.UpdatedAt = time.Now()
+	err = s.RefundRepo.SaveStatus(refund, vo.RefundPending)
+	if errors.Is(err, dependency.ErrConflict) {
+		return nil, ErrRefundProcessed
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityRefund, id, AuditUpdate, auditDiff(before, refund))
+	return refund, nil
+}
+
+// completeRefund 锁定订单的退款，退款仍待确认且数量没有超过可退数量时保存为已确认；
+// 内存模式下事务内后续的保存失败时恢复为待确认
+func (s *RefundService) completeRefund(order vo.Order, refund *vo.Refund) error {
+	err := s.RefundRepo.UpdateByOrder(order.ID, func(refunds map[int64]*vo.Refund) error {
+		current, ok := refunds[refund.ID]
+		if !ok {
+			return dependency.ErrNotFound
+		}
+		if current.Status != vo.RefundPending {
+			return dependency.ErrConflict
+		}
+		completed := []vo.Refund{*refund}
+		for _, other := range refunds {
+			if other.Status == vo.RefundCompleted {
+				completed = append(completed, *other)
+			}
+		}
+		if err := checkRefunded(order, completed); err != nil {
+			return err
+		}
+		current.Status, current.UpdatedAt = refund.Status, refund.UpdatedAt
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.Tx.Compensate(func() {
+		pending := *refund
+		pending.Status = vo.RefundPending
+		if err := s.RefundRepo.SaveStatus(&pending, vo.RefundCompleted); err != nil {
+			s.Worker.Logger().Error("恢复退款状态失败", freedom.LogFields{"refund_id": refund.ID, "error": err.Error()})
+		}
+	})
+	return nil
+}
+
+// checkRefunded 已确认的退款数量不能超过订单项数量，没有订单项的订单退款金额不能超过订单金额
+func checkRefunded(order vo.Order, completed []vo.Refund) error {
+	refunded := vo.RefundedQuantities(completed)
+	for _, item := range order.Items {
+		if refunded[item.ID] > item.Quantity {
+			return fmt.Errorf("%w：%s 确认后共退 %d 件，超过订单数量 %d", ErrRefundExceeded, item.ProductName,
+				refunded[item.ID], item.Quantity)
+		}
+	}
+	if len(order.Items) == 0 && roundAmount(vo.RefundedAmount(completed)) > order.TotalAmount {
+		return fmt.Errorf("%w：退款金额超过订单金额", ErrRefundExceeded)
+	}
+	return nil
+}
+
+// markOrder 按已确认的退款把订单标记为部分退款或已退款，并记录状态变更
+func (s *RefundService) markOrder(order *vo.Order, now time.Time) error {
+	refunds, err := s.RefundRepo.FindByOrder(order.ID)
+	if err != nil {
+		return err
+	}
+	var completed []vo.Refund
+	for _, refund := range refunds {
+		if refund.Status == vo.RefundCompleted {
+			completed = append(completed, refund)
+		}
+	}
+
+	status := vo.OrderRefunded
+	refunded := vo.RefundedQuantities(completed)
+	for _, item := range order.Items {
+		if refunded[item.ID] < item.Quantity {
+			status = vo.OrderPartiallyRefunded
+		}
+	}
+	if len(order.Items) == 0 && roundAmount(vo.RefundedAmount(completed)) < order.TotalAmount {
+		status = vo.OrderPartiallyRefunded
+	}
+	if status == order.Status {
+		return nil
+	}
+
+	before := *order
+	order.Status = status
+	order.UpdatedAt = now
+	change := &vo.OrderStatusChange{
+		OrderID:   order.ID,
+		From:      before.Status,
+		To:        status,
+		CreatedAt: now,
+	}
+	change.ActorID, change.Actor = currentActor(s.Worker)
+	if err := s.OrderRepo.SaveStatus(order, change); err != nil {
+		return err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityOrder, order.ID, AuditUpdate, auditDiff(before, order))
+	publishOrderEvent(s.Events, vo.EventOrderStatus, *order)
+	return nil
+}
+
+// refundItems 按表单计算退款项，数量不能超过订单项减去已退款和待确认的数量
+func refundItems(order vo.Order, refunds []vo.Refund, form vo.RefundFormData) ([]vo.RefundItem, error) {
+	used := vo.RefundedQuantities(refunds)
+	quantities := form.RefundQuantities()
+	if form.Full != "" {
+		quantities = make(map[int64]int, len(order.Items))
+		for _, item := range order.Items {
+			quantities[item.ID] = item.Quantity - used[item.ID]
+		}
+	}
+
+	var items []vo.RefundItem
+	for _, item := range order.Items {
+		quantity := quantities[item.ID]
+		delete(quantities, item.ID)
+		if quantity <= 0 {
+			continue
+		}
+		if remaining := item.Quantity - used[item.ID]; quantity > remaining {
+			return nil, fmt.Errorf("%w：%s 最多可退 %d", ErrRefundExceeded, item.ProductName, max(remaining, 0))
+		}
+		items = append(items, vo.RefundItem{
+			OrderItemID: item.ID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Quantity:    quantity,
+			Amount:      roundAmount(item.Price * float64(quantity)),
+		})
+	}
+	// 剩下的是不属于该订单的订单项
+	if len(quantities) > 0 || (len(items) == 0 && len(order.Items) > 0) {
+		return nil, ErrRefundItems
+	}
+	return items, nil
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"errors"
+	"godash/adapter/repository"
+	"godash/domain/vo"
+	"godash/internal/testutil"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPaidOrder 创建并支付一个订单
+func newPaidOrder(t *testing.T, product *vo.Product, quantity int) *vo.Order {
+	t.Helper()
+	order := newTestOrder(t, product, quantity)
+	paid, err := newTestOrderService().ChangeStatus(order.ID, vo.OrderPaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paid
+}
+
+// newPendingRefund 直接写入一个全额退款申请，模拟并发提交时都通过了申请检查
+func newPendingRefund(t *testing.T, order *vo.Order) *vo.Refund {
+	t.Helper()
+	item := order.Items[0]
+	now := time.Now()
+	refund := &vo.Refund{
+		OrderID:   order.ID,
+		Amount:    item.Subtotal,
+		Reason:    "测试退款",
+		Status:    vo.RefundPending,
+		Restock:   true,
+		Items:     []vo.RefundItem{{OrderItemID: item.ID, ProductName: item.ProductName, SKU: item.SKU, Quantity: item.Quantity, Amount: item.Subtotal}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := (&repository.RefundMemoryRepository{}).New(refund); err != nil {
+		t.Fatal(err)
+	}
+	return refund
+}
+
+func refundStatuses(t *testing.T, orderID int64) map[string]int {
+	t.Helper()
+	refunds, err := newTestRefundService().List(orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]int{}
+	for _, refund := range refunds {
+		statuses[refund.Status]++
+	}
+	return statuses
+}
+
+func TestRefundCompleteConcurrently(t *testing.T) {
+	for round := 0; round < 10; round++ {
+		product := newTestProduct(t, 10)
+		order := newPaidOrder(t, product, 3)
+		refunds := []*vo.Refund{newPendingRefund(t, order), newPendingRefund(t, order)}
+
+		// 两个全额退款同时确认，每个还各被重复提交；只能确认一个，库存只退回一次
+		var completed atomic.Int32
+		testutil.Parallel(8, func(i int) {
+			_, err := newTestRefundService().Complete(refunds[i%2].ID)
+			switch {
+			case err == nil:
+				completed.Add(1)
+			case errors.Is(err, ErrRefundExceeded), errors.Is(err, ErrRefundProcessed), errors.Is(err, ErrRefundNotAllowed):
+			default:
+				t.Errorf("Complete: %v", err)
+			}
+		})
+
+		if completed.Load() != 1 {
+			t.Fatalf("completed %d refunds, want 1", completed.Load())
+		}
+		if statuses := refundStatuses(t, order.ID); statuses[vo.RefundCompleted] != 1 || statuses[vo.RefundPending] != 1 {
+			t.Errorf("refund statuses %v, want one completed and one pending", statuses)
+		}
+		current, err := newTestOrderService().Get(order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current.Status != vo.OrderRefunded {
+			t.Errorf("order status %s, want refunded", current.Status)
+		}
+		if got := getProduct(t, product.ID); got.Stock != 10 || got.Reserved != 0 {
+			t.Errorf("product stock %d reserved %d; want 10 and 0", got.Stock, got.Reserved)
+		}
+	}
+}
+
+func TestRefundCompleteRestoresPendingOnFailure(t *testing.T) {
+	product := newTestProduct(t, 10)
+	order := newPaidOrder(t, product, 3)
+	refund := newPendingRefund(t, order)
+
+	service := newTestRefundService()
+	service.OrderRepo = failingOrderRepo{service.OrderRepo}
+	if _, err := service.Complete(refund.ID); !errors.Is(err, ErrRefundProcessed) {
+		t.Fatalf("Complete with a changed order: %v, want ErrRefundProcessed", err)
+	}
+	if statuses := refundStatuses(t, order.ID); statuses[vo.RefundPending] != 1 {
+		t.Errorf("refund statuses %v, want the refund still pending", statuses)
+	}
+	if got := getProduct(t, product.ID); got.Stock != 7 {
+		t.Errorf("product stock %d, want 7", got.Stock)
+	}
+
+	// 恢复为待确认后可以再次确认
+	if _, err := newTestRefundService().Complete(refund.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := getProduct(t, product.ID); got.Stock != 10 {
+		t.Errorf("product stock %d after refund, want 10", got.Stock)
+	}
+}
+
+func TestRefundCreatePartial(t *testing.T) {
+	product := newTestProduct(t, 10)
+	order := newPaidOrder(t, product, 3)
+	item := order.Items[0]
+	service := newTestRefundService()
+
+	partial := vo.RefundFormData{ItemIDs: []int64{item.ID}, Quantities: []int{2}, Reason: "部分退款"}
+	refund, err := service.Create(order.ID, partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Amount != 25 {
+		t.Errorf("refund amount %.2f, want 25", refund.Amount)
+	}
+	// 待确认的数量不能再次申请
+	if _, err := service.Create(order.ID, partial); !errors.Is(err, ErrRefundExceeded) {
+		t.Errorf("Create beyond the refundable quantity: %v, want ErrRefundExceeded", err)
+	}
+
+	if _, err := newTestRefundService().Complete(refund.ID); err != nil {
+		t.Fatal(err)
+	}
+	current, _ := newTestOrderService().Get(order.ID)
+	if current.Status != vo.OrderPartiallyRefunded {
+		t.Errorf("order status %s, want partially_refunded", current.Status)
+	}
+	if _, err := newTestOrderService().ChangeStatus(order.ID, vo.OrderShipped); err != nil {
+		t.Errorf("ship a partially refunded order: %v", err)
+	}
+}
+
+func TestRefundPartialKeepsFulfillment(t *testing.T) {
+	product := newTestProduct(t, 10)
+	order := newPaidOrder(t, product, 3)
+	orders := newTestOrderService()
+	for _, status := range []string{vo.OrderShipped, vo.OrderCompleted} {
+		if _, err := orders.ChangeStatus(order.ID, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	item := order.Items[0]
+	refund, err := newTestRefundService().Create(order.ID, vo.RefundFormData{ItemIDs: []int64{item.ID}, Quantities: []int{1}, Reason: "部分退款"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestRefundService().Complete(refund.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已完成的订单部分退款后不能退回发货，也不能手动标记为已退款
+	for _, status := range []string{vo.OrderShipped, vo.OrderCompleted, vo.OrderRefunded, vo.OrderCancelled} {
+		if _, err := newTestOrderService().ChangeStatus(order.ID, status); !errors.Is(err, ErrOrderTransition) {
+			t.Errorf("partially refunded completed order → %s: %v, want ErrOrderTransition", status, err)
+		}
+	}
+	current, err := newTestOrderService().Get(order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Status != vo.OrderPartiallyRefunded || current.Fulfillment != vo.OrderCompleted {
+		t.Errorf("order status %s fulfillment %s; want partially_refunded after completed", current.Status, current.Fulfillment)
+	}
+}
@@ -0,0 +1,306 @@
+package domain
+
+import (
+	"errors"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"sync"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *RoleService {
+			return &RoleService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *RoleService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+		initiator.BindBooting(func(bootManager freedom.BootManager) {
+			err := freedom.ServiceLocator().Call(func(service *RoleService) error {
+				return service.EnsureDefaults()
+			})
+			if err != nil {
+				freedom.Logger().Errorf("创建内置角色失败: %v", err)
+			}
+		})
+	})
+}
+
+// AdminRole 管理员角色，始终拥有全部权限
+const AdminRole = "admin"
+
+// 角色相关错误
+var (
+	ErrRoleCodeExists = errors.New("角色标识已存在")
+	ErrRoleInUse      = errors.New("该角色下还有用户，请先将用户转移到其他角色")
+	ErrSystemRole     = errors.New("系统内置角色不可删除")
+)
+
+// permissionModules 受权限控制的功能模块
+var permissionModules = []vo.PermissionModule{
+	{Code: "users", Name: "用户管理"},
+	{Code: "roles", Name: "角色权限"},
+	{Code: "products", Name: "商品管理"},
+	{Code: "orders", Name: "订单管理"},
+	{Code: "settings", Name: "系统设置"},
+	{Code: "audit", Name: "审计日志"},
+}
+
+// permissionActions 权限动作：查看、新增/编辑、删除
+var permissionActions = []vo.PermissionAction{
+	{Code: "read", Name: "查看"},
+	{Code: "write", Name: "编辑"},
+	{Code: "delete", Name: "删除"},
+}
+
+// defaultRoles 内置角色，与原权限矩阵保持一致
+var defaultRoles = []vo.Role{
+	{Code: AdminRole, Name: "管理员", Description: "系统管理员，拥有所有权限", System: true},
+	{Code: "editor", Name: "编辑", Description: "内容编辑，可以管理用户和商品", Permissions: []string{
+		"users:read", "users:write",
+		"products:read", "products:write", "products:delete",
+		"orders:read", "orders:write",
+	}},
+	{Code: "viewer", Name: "访客", Description: "只读权限，只能查看内容", Permissions: []string{
+		"products:read", "orders:read",
+	}},
+}
+
+// AllPermissions 全部权限标识，按模块和动作排序
+func AllPermissions() []string {
+	result := make([]string, 0, len(permissionModules)*len(permissionActions))
+	for _, module := range permissionModules {
+		for _, action := range permissionActions {
+			result = append(result, module.Code+":"+action.Code)
+		}
+	}
+	return result
+}
+
+// normalizePermissions 去掉未知和重复的权限，并按目录顺序排列
+func normalizePermissions(permissions []string) []string {
+	selected := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		selected[p] = true
+	}
+	result := []string{}
+	for _, p := range AllPermissions() {
+		if selected[p] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// policyCache 角色权限缓存，角色变更时失效
+var policyCache = struct {
+	sync.RWMutex
+	roles map[string]map[string]bool
+}{roles: map[string]map[string]bool{}}
+
+func invalidatePolicy() {
+	policyCache.Lock()
+	policyCache.roles = map[string]map[string]bool{}
+	policyCache.Unlock()
+}
+
+// RoleService 角色权限领域服务
+type RoleService struct {
+	Worker    freedom.Worker
+	RoleRepo  dependency.RoleRepo
+	UserRepo  dependency.UserRepo
+	AuditRepo dependency.AuditRepo
+}
+
+// Modules 权限模块
+func (s *RoleService) Modules() []vo.PermissionModule {
+	return permissionModules
+}
+
+// Actions 权限动作
+func (s *RoleService) Actions() []vo.PermissionAction {
+	return permissionActions
+}
+
+// List 查询全部角色，并统计每个角色的用户数
+func (s *RoleService) List() ([]vo.Role, error) {
+	roles, err := s.RoleRepo.Finds()
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.UserRepo.CountByRole()
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		roles[i].UserCount = counts[roles[i].Code]
+	}
+	return roles, nil
+}
+
+// Get 获取角色
+func (s *RoleService) Get(id int64) (*vo.Role, error) {
+	return s.RoleRepo.Get(id)
+}
+
+// Create 创建角色
+func (s *RoleService) Create(formData vo.RoleFormData) (*vo.Role, error) {
+	now := time.Now()
+	role := &vo.Role{
+		Code:        formData.Code,
+		Name:        formData.Name,
+		Description: formData.Description,
+		Permissions: normalizePermissions(formData.Permissions),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.RoleRepo.New(role); err != nil {
+		if errors.Is(err, dependency.ErrDuplicate) {
+			return nil, ErrRoleCodeExists
+		}
+		return nil, err
+	}
+	invalidatePolicy()
+	s.Worker.Logger().Info("创建角色", freedom.LogFields{"id": role.ID, "code": role.Code})
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityRole, role.ID, AuditCreate, auditDiff(nil, role))
+	return role, nil
+}
+
+// Update 更新角色（角色标识不可修改，管理员权限不可修改）
+func (s *RoleService) Update(id int64, formData vo.RoleFormData) (*vo.Role, error) {
+	role, err := s.RoleRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *role
+
+	role.Name = formData.Name
+	role.Description = formData.Description
+	if role.Code != AdminRole {
+		role.Permissions = normalizePermissions(formData.Permissions)
+	}
+	role.UpdatedAt = time.Now()
+	if err := s.RoleRepo.Save(role); err != nil {
+		return nil, err
+	}
+	invalidatePolicy()
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityRole, id, AuditUpdate, auditDiff(before, role))
+	return role, nil
+}
+
+// SavePermissions 批量保存权限矩阵，key 为角色 ID；管理员角色忽略
+func (s *RoleService) SavePermissions(matrix map[int64][]string) error {
+	roles, err := s.RoleRepo.Finds()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range roles {
+		role := &roles[i]
+		if role.Code == AdminRole {
+			continue
+		}
+		before := *role
+		role.Permissions = normalizePermissions(matrix[role.ID])
+		role.UpdatedAt = now
+		if err := s.RoleRepo.Save(role); err != nil {
+			return err
+		}
+		recordAudit(s.Worker, s.AuditRepo, AuditEntityRole, role.ID, AuditUpdate, auditDiff(before, role))
+	}
+	invalidatePolicy()
+	s.Worker.Logger().Info("更新权限矩阵", freedom.LogFields{"roles": len(roles)})
+	return nil
+}
+
+// Delete 删除角色，内置角色和仍有用户的角色不可删除
+func (s *RoleService) Delete(id int64) error {
+	role, err := s.RoleRepo.Get(id)
+	if err != nil {
+		return err
+	}
+	if role.System {
+		return ErrSystemRole
+	}
+	counts, err := s.UserRepo.CountByRole()
+	if err != nil {
+		return err
+	}
+	if counts[role.Code] > 0 {
+		return ErrRoleInUse
+	}
+	if err := s.RoleRepo.Delete(id); err != nil {
+		return err
+	}
+	invalidatePolicy()
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityRole, id, AuditDelete, auditDiff(role, nil))
+	return nil
+}
+
+// Allowed 判断角色是否拥有权限，结果按角色缓存
+func (s *RoleService) Allowed(roleCode, permission string) (bool, error) {
+	return roleAllowed(s.RoleRepo, roleCode, permission)
+}
+
+// roleAllowed 角色是否拥有指定权限，管理员角色始终返回 true
+func roleAllowed(repo dependency.RoleRepo, roleCode, permission string) (bool, error) {
+	if roleCode == AdminRole {
+		return true, nil
+	}
+	permissions, err := rolePermissions(repo, roleCode)
+	return permissions[permission], err
+}
+
+// rolePermissions 角色的权限集合，角色不存在时为空集合；结果缓存到角色变更
+func rolePermissions(repo dependency.RoleRepo, roleCode string) (map[string]bool, error) {
+	policyCache.RLock()
+	permissions, ok := policyCache.roles[roleCode]
+	policyCache.RUnlock()
+	if ok {
+		return permissions, nil
+	}
+
+	permissions = map[string]bool{}
+	role, err := repo.FindByCode(roleCode)
+	if err != nil && !errors.Is(err, dependency.ErrNotFound) {
+		return permissions, err
+	}
+	if role != nil {
+		for _, p := range role.Permissions {
+			permissions[p] = true
+		}
+	}
+	policyCache.Lock()
+	policyCache.roles[roleCode] = permissions
+	policyCache.Unlock()
+	return permissions, nil
+}
+
+// EnsureDefaults 创建缺失的内置角色，已存在的角色保持不变
+func (s *RoleService) EnsureDefaults() error {
+	now := time.Now()
+	for _, role := range defaultRoles {
+		_, err := s.RoleRepo.FindByCode(role.Code)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, dependency.ErrNotFound) {
+			return err
+		}
+		role.Permissions = append([]string(nil), role.Permissions...)
+		if role.Code == AdminRole {
+			role.Permissions = AllPermissions()
+		}
+		role.CreatedAt = now
+		role.UpdatedAt = now
+		if err := s.RoleRepo.New(&role); err != nil && !errors.Is(err, dependency.ErrDuplicate) {
+			return err
+		}
+	}
+	invalidatePolicy()
+	return nil
+}
@@ -0,0 +1,254 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"sync"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *SettingService {
+			return &SettingService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *SettingService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+		initiator.BindBooting(func(bootManager freedom.BootManager) {
+			// 预热缓存，设置文件或数据表不可读时尽早在日志中暴露
+			CurrentSettings()
+		})
+	})
+}
+
+// defaultSettings 从未保存过设置时使用的默认设置
+var defaultSettings = vo.SettingsData{
+	SiteName:          "HTMX 管理后台",
+	SiteDescription:   "基于 HTMX、Bulma 和 Alpine.js 的现代化管理后台系统",
+	ContactEmail:      "admin@example.com",
+	ContactPhone:      "400-123-4567",
+	ContactAddress:    "上海市浦东新区世纪大道 100 号",
+	Currency:          "CNY",
+	Timezone:          "Asia/Shanghai",
+	Language:          "zh-CN",
+	DateFormat:        "YYYY-MM-DD",
+	ItemsPerPage:      10,
+	LowStockThreshold: 10,
+}
+
+// settingsCacheTTL 设置缓存的有效期。本实例保存时立即失效，
+// 多实例部署时其他实例保存的设置最迟在有效期后生效
+const settingsCacheTTL = time.Minute
+
+// processStarted 进程启动时间，用于显示系统运行时长
+var processStarted = time.Now()
+
+// settingsCache 当前生效的系统设置
+var settingsCache struct {
+	sync.RWMutex
+	settings *vo.SettingsData
+	loadedAt time.Time
+}
+
+// CurrentSettings 当前生效的系统设置，各模块统一从这里读取。
+// 缓存失效后从资源库重新加载；加载失败时返回默认设置且不缓存，下次读取时重试
+func CurrentSettings() vo.SettingsData {
+	settingsCache.RLock()
+	settings, loadedAt := settingsCache.settings, settingsCache.loadedAt
+	settingsCache.RUnlock()
+	if settings != nil && time.Since(loadedAt) < settingsCacheTTL {
+		return *settings
+	}
+
+	settingsCache.Lock()
+	defer settingsCache.Unlock()
+	if settingsCache.settings != nil && time.Since(settingsCache.loadedAt) < settingsCacheTTL {
+		return *settingsCache.settings
+	}
+	var latest vo.SettingsVersion
+	err := freedom.ServiceLocator().Call(func(service *SettingService) (e error) {
+		latest, e = service.Latest()
+		return
+	})
+	if err != nil {
+		freedom.Logger().Errorf("加载系统设置失败: %v", err)
+		return defaultSettings
+	}
+	settingsCache.settings, settingsCache.loadedAt = &latest.Settings, time.Now()
+	return latest.Settings
+}
+
+// invalidateSettings 清除设置缓存，下次读取时重新加载
+func invalidateSettings() {
+	settingsCache.Lock()
+	settingsCache.settings = nil
+	settingsCache.Unlock()
+}
+
+// SettingService 系统设置领域服务，每次保存追加一个新版本，可以比较任意两个版本并回滚
+type SettingService struct {
+	Worker      freedom.Worker
+	SettingRepo dependency.SettingRepo
+}
+
+// Latest 最新的设置版本，从未保存时返回版本号为 0 的默认设置
+func (s *SettingService) Latest() (vo.SettingsVersion, error) {
+	latest, err := s.SettingRepo.Latest()
+	if errors.Is(err, dependency.ErrNotFound) {
+		return vo.SettingsVersion{Settings: defaultSettings}, nil
+	}
+	if err != nil {
+		return vo.SettingsVersion{}, err
+	}
+	return *latest, nil
+}
+
+// Page 设置页面数据：最新设置和保存统计
+func (s *SettingService) Page() (vo.SettingsPage, error) {
+	latest, err := s.Latest()
+	if err != nil {
+		return vo.SettingsPage{}, err
+	}
+	return vo.SettingsPage{SettingsData: latest.Settings, Stats: settingsStats(latest), Version: latest.Version}, nil
+}
+
+// Save 在编辑时加载的 baseVersion 之上保存设置为新版本并清除缓存；
+// 最新版本已不是 baseVersion（其他人在此期间保存过）时返回 ErrConflict
+func (s *SettingService) Save(settings vo.SettingsData, baseVersion int) (*vo.SettingsVersion, error) {
+	return s.save(settings, baseVersion, 0)
+}
+
+// History 分页查询设置的历史版本，最新的在前
+func (s *SettingService) History(query vo.ListQuery) (vo.SettingsHistoryData, error) {
+	query.Mode = vo.PageModeOffset
+	page, err := s.SettingRepo.Finds(query)
+	if err != nil {
+		return vo.SettingsHistoryData{}, err
+	}
+	latest, err := s.Latest()
+	if err != nil {
+		return vo.SettingsHistoryData{}, err
+	}
+	return vo.SettingsHistoryData{Versions: page.Items, PageInfo: page.PageInfo, Latest: latest.Version}, nil
+}
+
+// Diff 比较两个版本的设置项，版本 0 表示默认设置；版本不存在时返回 ErrNotFound
+func (s *SettingService) Diff(from, to int) (vo.SettingsDiff, error) {
+	before, err := s.version(from)
+	if err != nil {
+		return vo.SettingsDiff{}, err
+	}
+	after, err := s.version(to)
+	if err != nil {
+		return vo.SettingsDiff{}, err
+	}
+	diff := vo.SettingsDiff{From: before, To: after, Changes: []vo.SettingsChange{}}
+	for _, change := range auditDiff(before.Settings, after.Settings) {
+		label, ok := vo.SettingsFieldLabels[change.Field]
+		if !ok {
+			label = change.Field
+		}
+		diff.Changes = append(diff.Changes, vo.SettingsChange{
+			Field:  change.Field,
+			Label:  label,
+			Before: settingsValue(change.Before),
+			After:  settingsValue(change.After),
+		})
+	}
+	return diff, nil
+}
+
+// Rollback 把指定版本的设置保存为一个新版本，不删除之后的版本
+func (s *SettingService) Rollback(version int) (*vo.SettingsVersion, error) {
+	target, err := s.version(version)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.Latest()
+	if err != nil {
+		return nil, err
+	}
+	return s.save(target.Settings, latest.Version, version)
+}
+
+// version 读取指定版本，版本 0 为默认设置
+func (s *SettingService) version(version int) (vo.SettingsVersion, error) {
+	if version == 0 {
+		return vo.SettingsVersion{Settings: defaultSettings}, nil
+	}
+	result, err := s.SettingRepo.Get(version)
+	if err != nil {
+		return vo.SettingsVersion{}, err
+	}
+	return *result, nil
+}
+
+// save 在 baseVersion 之上追加新版本并清除缓存，restoredFrom 为回滚的来源版本
+func (s *SettingService) save(settings vo.SettingsData, baseVersion, restoredFrom int) (*vo.SettingsVersion, error) {
+	latest, err := s.Latest()
+	if err != nil {
+		return nil, err
+	}
+	if latest.Version != baseVersion {
+		return nil, dependency.ErrConflict
+	}
+	version := &vo.SettingsVersion{
+		Version:      baseVersion + 1,
+		Settings:     settings,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    time.Now(),
+	}
+	version.AuthorID, version.Author = currentActor(s.Worker)
+	if err := s.SettingRepo.New(version); err != nil {
+		if errors.Is(err, dependency.ErrDuplicate) {
+			return nil, dependency.ErrConflict
+		}
+		return nil, err
+	}
+	invalidateSettings()
+	return version, nil
+}
+
+// settingsValue 差异中开关类设置显示为开启/关闭
+func settingsValue(value string) string {
+	switch value {
+	case "true":
+		return "开启"
+	case "false":
+		return "关闭"
+	}
+	return value
+}
+
+// settingsStats 由最新版本生成设置统计，版本只追加，保存次数即版本号
+func settingsStats(latest vo.SettingsVersion) vo.SettingsStats {
+	stats := vo.SettingsStats{
+		TotalSaves:    latest.Version,
+		ConfigVersion: fmt.Sprintf("v%d", latest.Version),
+		SystemUptime:  formatUptime(time.Since(processStarted)),
+	}
+	if latest.Version > 0 {
+		stats.LastSaveTime = CurrentSettings().FormatDateTime(latest.CreatedAt) + " · " + latest.Author
+	}
+	return stats
+}
+
+// formatUptime 运行时长，如“3 天 2 小时”“5 分钟”
+func formatUptime(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d 天 %d 小时", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%d 小时 %d 分钟", hours, minutes)
+	}
+	return fmt.Sprintf("%d 分钟", minutes)
+}
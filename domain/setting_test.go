@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/internal/testutil"
+	"sync"
+	"testing"
+)
+
+func TestSettingSaveConcurrently(t *testing.T) {
+	latest, err := newTestSettingService().Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 都在同一个版本上编辑，只有一个能保存，其余的返回 ErrConflict
+	var mu sync.Mutex
+	var versions []int
+	testutil.Parallel(20, func(i int) {
+		settings := latest.Settings
+		settings.SiteName = fmt.Sprintf("并发站点 %d", i)
+		version, err := newTestSettingService().Save(settings, latest.Version)
+		if errors.Is(err, dependency.ErrConflict) {
+			return
+		}
+		if err != nil {
+			t.Errorf("Save: %v", err)
+			return
+		}
+		mu.Lock()
+		versions = append(versions, version.Version)
+		mu.Unlock()
+	})
+
+	if len(versions) != 1 || versions[0] != latest.Version+1 {
+		t.Fatalf("saved versions %v, want only version %d", versions, latest.Version+1)
+	}
+	current, err := newTestSettingService().Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Version != versions[0] || CurrentSettings().SiteName != current.Settings.SiteName {
+		t.Errorf("latest version %d site %q, cached site %q; want version %d", current.Version,
+			current.Settings.SiteName, CurrentSettings().SiteName, versions[0])
+	}
+
+	// 在过期的版本上保存被拒绝
+	if _, err := newTestSettingService().Save(latest.Settings, latest.Version); !errors.Is(err, dependency.ErrConflict) {
+		t.Errorf("Save on a stale version: %v, want ErrConflict", err)
+	}
+}
+
+func TestSettingRollback(t *testing.T) {
+	service := newTestSettingService()
+	latest, err := service.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := latest.Settings
+	settings.SiteName = "回滚前"
+	settings.MaintenanceMode = false
+	first, err := service.Save(settings, latest.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.SiteName = "回滚后"
+	settings.MaintenanceMode = true
+	if _, err := service.Save(settings, first.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := service.Rollback(first.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Version != first.Version+2 || restored.RestoredFrom != first.Version || restored.Settings != first.Settings {
+		t.Errorf("rollback saved version %d from %d; want version %d restored from %d",
+			restored.Version, restored.RestoredFrom, first.Version+2, first.Version)
+	}
+	if CurrentSettings().SiteName != "回滚前" {
+		t.Errorf("cached site %q after rollback, want 回滚前", CurrentSettings().SiteName)
+	}
+
+	diff, err := service.Diff(first.Version+1, restored.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := map[string]vo.SettingsChange{}
+	for _, change := range diff.Changes {
+		changes[change.Field] = change
+	}
+	if len(changes) != 2 || changes["site_name"].After != "回滚前" || changes["maintenance_mode"].After != "关闭" {
+		t.Errorf("diff %+v, want site_name and maintenance_mode", diff.Changes)
+	}
+
+	if _, err := service.Rollback(restored.Version + 100); !errors.Is(err, dependency.ErrNotFound) {
+		t.Errorf("Rollback to a missing version: %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *StatsService {
+			return &StatsService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *StatsService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+	})
+}
+
+// 仪表盘列表的条数
+const (
+	dashboardRecentSize = 5
+	dashboardTopSize    = 4
+)
+
+// StatsService 统计领域服务：仪表盘的汇总数字、最近的订单和用户以及热门商品，
+// 汇总由各资源库聚合查询完成，不加载全部记录
+type StatsService struct {
+	Worker      freedom.Worker
+	UserRepo    dependency.UserRepo
+	ProductRepo dependency.ProductRepo
+	OrderRepo   dependency.OrderRepo
+}
+
+// Stats 汇总统计，可售库存不超过 lowStock 的商品计为低库存，今日按 location 时区的零点起算，
+// 只统计 access 中可见的模块
+func (s *StatsService) Stats(lowStock int, location *time.Location, access vo.DashboardAccess) (vo.DashboardStats, error) {
+	stats := vo.DashboardStats{LowStockThreshold: lowStock, Access: access}
+	if access.Users {
+		users, err := s.UserRepo.CountByStatus()
+		if err != nil {
+			return vo.DashboardStats{}, err
+		}
+		for _, count := range users {
+			stats.TotalUsers += int64(count)
+		}
+		stats.ActiveUsers = int64(users["active"])
+	}
+	if access.Products {
+		products, err := s.ProductRepo.Stats(lowStock)
+		if err != nil {
+			return vo.DashboardStats{}, err
+		}
+		stats.TotalProducts, stats.LowStock = products.Total, products.LowStock
+	}
+	if access.Orders {
+		now := time.Now().In(location)
+		orders, err := s.OrderRepo.Stats(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location))
+		if err != nil {
+			return vo.DashboardStats{}, err
+		}
+		stats.TotalOrders = orders.Total
+		stats.TotalRevenue = roundAmount(orders.Revenue)
+		stats.PendingOrders = orders.Pending
+		stats.TodayOrders = orders.Today
+		stats.TodayRevenue = roundAmount(orders.TodayRevenue)
+	}
+	return stats, nil
+}
+
+// Dashboard 仪表盘数据：汇总统计、最新的订单和用户，以及按售出件数排名的商品，只查询 access 中可见的模块
+func (s *StatsService) Dashboard(lowStock int, location *time.Location, access vo.DashboardAccess) (vo.DashboardData, error) {
+	stats, err := s.Stats(lowStock, location, access)
+	if err != nil {
+		return vo.DashboardData{}, err
+	}
+	data := vo.DashboardData{Stats: stats}
+	latest := vo.ListQuery{
+		Sort:     vo.SortState{{Field: "created_at", Desc: true}},
+		Page:     1,
+		PageSize: dashboardRecentSize,
+	}
+	if access.Orders {
+		orders, err := s.OrderRepo.Finds(latest, vo.OrderFilter{})
+		if err != nil {
+			return vo.DashboardData{}, err
+		}
+		top, err := s.OrderRepo.TopProducts(dashboardTopSize)
+		if err != nil {
+			return vo.DashboardData{}, err
+		}
+		for i := range top {
+			top[i].Amount = roundAmount(top[i].Amount)
+		}
+		data.RecentOrders, data.TopProducts = orders.Items, top
+	}
+	if access.Users {
+		users, err := s.UserRepo.Finds(latest, vo.UserFilter{})
+		if err != nil {
+			return vo.DashboardData{}, err
+		}
+		data.RecentUsers = users.Items
+	}
+
+	data.Series, err = s.Series(vo.SeriesQuery{}, location, access)
+	if err != nil {
+		return vo.DashboardData{}, err
+	}
+	return data, nil
+}
+
+// Series 最近 query.Days 天（含今天）的订单数、收入和新用户，按 location 时区的天、周（周一开始）或月汇总。
+// 第一个时间段从包含起始日的那一天、周或月开始，保证每段都是完整的；订单和新用户只统计 access 中可见的部分
+func (s *StatsService) Series(query vo.SeriesQuery, location *time.Location, access vo.DashboardAccess) (vo.Series, error) {
+	query = query.Normalize()
+	now := time.Now().In(location)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, location)
+	from := seriesStart(to.AddDate(0, 0, -query.Days), query.Interval)
+
+	series := vo.Series{SeriesQuery: query, From: from, To: to, Access: access}
+	buckets := map[int64]int{} // 时间段开始时间 -> 下标
+	for start := from; start.Before(to); start = seriesNext(start, query.Interval) {
+		buckets[start.Unix()] = len(series.Points)
+		series.Points = append(series.Points, vo.SeriesPoint{Start: start, Label: seriesLabel(start, query.Interval)})
+	}
+	// index 下单或注册时间所在的时间段
+	index := func(t time.Time) int {
+		return buckets[seriesStart(t.In(location), query.Interval).Unix()]
+	}
+
+	if access.Orders {
+		orders, err := s.OrderRepo.FindPoints(from, to)
+		if err != nil {
+			return series, err
+		}
+		for _, order := range orders {
+			point := &series.Points[index(order.CreatedAt)]
+			point.Orders++
+			point.Revenue += order.Revenue
+			series.TotalOrders++
+			series.TotalRevenue += order.Revenue
+		}
+	}
+	if access.Users {
+		users, err := s.UserRepo.FindCreatedTimes(from, to)
+		if err != nil {
+			return series, err
+		}
+		for _, created := range users {
+			series.Points[index(created)].NewUsers++
+			series.TotalNewUsers++
+		}
+	}
+
+	for i := range series.Points {
+		series.Points[i].Revenue = roundAmount(series.Points[i].Revenue)
+	}
+	series.TotalRevenue = roundAmount(series.TotalRevenue)
+	return series, nil
+}
+
+// seriesStart t 所在的天、周或月的开始时间
+func seriesStart(t time.Time, interval string) time.Time {
+	switch interval {
+	case vo.SeriesWeek:
+		return time.Date(t.Year(), t.Month(), t.Day()-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case vo.SeriesMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// seriesNext 下一个时间段的开始时间
+func seriesNext(start time.Time, interval string) time.Time {
+	switch interval {
+	case vo.SeriesWeek:
+		return start.AddDate(0, 0, 7)
+	case vo.SeriesMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// seriesLabel 时间段在图表横轴上的标签，周以周一的日期表示
+func seriesLabel(start time.Time, interval string) string {
+	if interval == vo.SeriesMonth {
+		return start.Format("2006-01")
+	}
+	return start.Format("01-02")
+}
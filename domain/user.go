@@ -0,0 +1,384 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"godash/config"
+	"godash/domain/dependency"
+	"godash/domain/vo"
+	"godash/infra"
+	"time"
+
+	"github.com/8treenet/freedom"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindService(func() *UserService {
+			return &UserService{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (service *UserService) {
+			initiator.FetchService(ctx, &service)
+			return
+		})
+		initiator.BindBooting(func(bootManager freedom.BootManager) {
+			conf := config.Get().Auth
+			if conf.AdminUsername == "" {
+				return
+			}
+			// 不使用内置的默认密码，未配置密码时不创建管理员
+			if conf.AdminPassword == "" {
+				freedom.Logger().Warnf("未设置 [auth] admin_password，不创建管理员账号 %s", conf.AdminUsername)
+				return
+			}
+			err := freedom.ServiceLocator().Call(func(service *UserService) error {
+				return service.EnsureAdmin(conf.AdminUsername, conf.AdminPassword)
+			})
+			if err != nil {
+				freedom.Logger().Errorf("创建管理员账号失败: %v", err)
+			}
+		})
+	})
+}
+
+// ErrUsernameExists 用户名已存在
+var ErrUsernameExists = errors.New("用户名已存在")
+
+// ErrPasswordRequired 新增用户必须设置密码
+var ErrPasswordRequired = errors.New("请设置登录密码")
+
+// ErrInvalidCredentials 用户名或密码错误
+var ErrInvalidCredentials = errors.New("用户名或密码错误")
+
+// ErrUserInactive 账户未启用
+var ErrUserInactive = errors.New("账户已被禁用，请联系管理员")
+
+// ErrSelfOperation 不能删除、封禁当前登录的账户或修改其角色
+var ErrSelfOperation = errors.New("不能对当前登录的账户执行该操作")
+
+// ErrRoleNotFound 角色不存在
+var ErrRoleNotFound = errors.New("角色不存在")
+
+// ErrRoleForbidden 没有分配角色或管理管理员账户的权限
+var ErrRoleForbidden = errors.New("需要角色权限（roles:write）才能分配该角色或修改管理员账户")
+
+// rolePermission 分配、修改角色和管理管理员账户需要的权限
+const rolePermission = "roles:write"
+
+// UserService 用户领域服务
+type UserService struct {
+	Worker    freedom.Worker
+	UserRepo  dependency.UserRepo
+	RoleRepo  dependency.RoleRepo
+	AuditRepo dependency.AuditRepo
+	Events    dependency.EventBus
+}
+
+// List 按查询规格和筛选条件分页查询用户
+func (s *UserService) List(query vo.ListQuery, filter vo.UserFilter) (vo.Page[vo.User], error) {
+	return s.UserRepo.Finds(query, filter)
+}
+
+// Export 按关键词和筛选条件逐条读取用户，用于导出
+func (s *UserService) Export(query vo.ListQuery, filter vo.UserFilter, fn func(vo.User) error) error {
+	return eachRow(query, func(q vo.ListQuery) (vo.Page[vo.User], error) {
+		return s.UserRepo.Finds(q, filter)
+	}, fn)
+}
+
+// Get 获取用户
+func (s *UserService) Get(id int64) (*vo.User, error) {
+	return s.UserRepo.Get(id)
+}
+
+// Active 读取状态正常的用户，认证中间件每次请求用它确认会话中的用户仍然有效；
+// 用户不存在时返回 ErrNotFound，未启用或被封禁时返回 ErrUserInactive
+func (s *UserService) Active(id int64) (*vo.User, error) {
+	user, err := s.UserRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != "active" {
+		return nil, ErrUserInactive
+	}
+	return user, nil
+}
+
+// Create 创建用户
+func (s *UserService) Create(formData vo.UserFormData) (*vo.User, error) {
+	// 检查用户名是否已存在
+	_, err := s.UserRepo.FindByUsername(formData.Username)
+	if err == nil {
+		return nil, ErrUsernameExists
+	}
+	if !errors.Is(err, dependency.ErrNotFound) {
+		return nil, err
+	}
+	if formData.Password == "" {
+		return nil, ErrPasswordRequired
+	}
+	if err := s.checkRoleAssignment(formData.Role); err != nil {
+		return nil, err
+	}
+	password, err := hashPassword(formData.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &vo.User{
+		Username:  formData.Username,
+		Email:     formData.Email,
+		RealName:  formData.RealName,
+		Phone:     formData.Phone,
+		Role:      formData.Role,
+		Status:    formData.Status,
+		Avatar:    "/static/images/zxg.jpg",
+		Password:  password,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.UserRepo.New(user); err != nil {
+		if errors.Is(err, dependency.ErrDuplicate) {
+			return nil, ErrUsernameExists
+		}
+		return nil, err
+	}
+	s.Worker.Logger().Info("创建用户", freedom.LogFields{"id": user.ID, "username": user.Username})
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityUser, user.ID, AuditCreate, auditDiff(nil, user))
+	publishEvent(s.Events, vo.EventUserCreated, AuditEntityUser, user.ID, map[string]interface{}{
+		"username":  user.Username,
+		"real_name": user.RealName,
+	})
+	return user, nil
+}
+
+// Update 更新用户信息（用户名不可修改）
+func (s *UserService) Update(id int64, formData vo.UserFormData) (*vo.User, error) {
+	user, err := s.UserRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+	if err := s.checkManage(user); err != nil {
+		return nil, err
+	}
+	if formData.Role != user.Role {
+		if err := s.checkRoleChange(formData.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Email = formData.Email
+	user.RealName = formData.RealName
+	user.Phone = formData.Phone
+	user.Role = formData.Role
+	user.Status = formData.Status
+	if formData.Password != "" {
+		if user.Password, err = hashPassword(formData.Password); err != nil {
+			return nil, err
+		}
+	}
+	user.UpdatedAt = time.Now()
+	if err := s.UserRepo.Save(user); err != nil {
+		return nil, err
+	}
+
+	// 密码哈希不输出到 JSON，单独记录是否修改过
+	changes := auditDiff(before, user)
+	if user.Password != before.Password {
+		changes = append(changes, vo.AuditChange{Field: "password", Before: "***", After: "***"})
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityUser, id, AuditUpdate, changes)
+	return user, nil
+}
+
+// Delete 删除用户
+func (s *UserService) Delete(id int64) error {
+	_, err := s.delete(id)
+	return err
+}
+
+// delete 删除用户并记录审计日志，返回被删除的用户
+func (s *UserService) delete(id int64) (*vo.User, error) {
+	user, err := s.UserRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(user); err != nil {
+		return nil, err
+	}
+	if err := s.UserRepo.Delete(id); err != nil {
+		return nil, err
+	}
+	recordAudit(s.Worker, s.AuditRepo, AuditEntityUser, id, AuditDelete, auditDiff(user, nil))
+	return user, nil
+}
+
+// Bulk 批量删除、启用、封禁用户或设置角色，set_role 的 value 为角色标识。
+// 当前登录的账户不能删除、封禁或修改角色，避免把自己锁在系统外。
+func (s *UserService) Bulk(ids []int64, action, value string) (vo.BulkResult[vo.User], error) {
+	var change func(user *vo.User)
+	switch action {
+	case BulkDelete:
+	case BulkActivate:
+		change = func(user *vo.User) { user.Status = "active" }
+	case BulkBan:
+		change = func(user *vo.User) { user.Status = "banned" }
+	case BulkSetRole:
+		if err := s.checkRoleChange(value); err != nil {
+			if errors.Is(err, ErrRoleNotFound) {
+				return vo.BulkResult[vo.User]{}, fmt.Errorf("%w: 角色 %s 不存在", ErrBulkAction, value)
+			}
+			if errors.Is(err, ErrRoleForbidden) {
+				return vo.BulkResult[vo.User]{}, fmt.Errorf("%w: %s", ErrBulkAction, err.Error())
+			}
+			return vo.BulkResult[vo.User]{}, err
+		}
+		change = func(user *vo.User) { user.Role = value }
+	default:
+		return vo.BulkResult[vo.User]{}, ErrBulkAction
+	}
+
+	current, _ := infra.CurrentUser(s.Worker.IrisContext())
+	return bulkApply(ids, func(id int64) (vo.User, error) {
+		if id == current.ID && action != BulkActivate {
+			return vo.User{}, ErrSelfOperation
+		}
+		if change == nil {
+			user, err := s.delete(id)
+			if err != nil {
+				return vo.User{}, err
+			}
+			return *user, nil
+		}
+		user, err := s.UserRepo.Get(id)
+		if err != nil {
+			return vo.User{}, err
+		}
+		if err := s.checkManage(user); err != nil {
+			return vo.User{}, err
+		}
+		before := *user
+		change(user)
+		user.UpdatedAt = time.Now()
+		if err := s.UserRepo.Save(user); err != nil {
+			return vo.User{}, err
+		}
+		recordAudit(s.Worker, s.AuditRepo, AuditEntityUser, id, AuditUpdate, auditDiff(before, user))
+		return *user, nil
+	}), nil
+}
+
+// actorAllowed 当前登录用户的角色是否拥有权限；没有登录用户时（启动任务）视为系统操作
+func (s *UserService) actorAllowed(permission string) (bool, error) {
+	actor, ok := infra.CurrentUser(s.Worker.IrisContext())
+	if !ok {
+		return true, nil
+	}
+	return roleAllowed(s.RoleRepo, actor.Role, permission)
+}
+
+// checkRoleAssignment 新增用户时校验角色：角色必须存在；没有 roles:write 权限时
+// 只能分配权限不超过自己的非管理员角色，避免借新账户提权
+func (s *UserService) checkRoleAssignment(code string) error {
+	role, err := s.RoleRepo.FindByCode(code)
+	if errors.Is(err, dependency.ErrNotFound) {
+		return ErrRoleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if ok, err := s.actorAllowed(rolePermission); ok || err != nil {
+		return err
+	}
+	if role.Code == AdminRole {
+		return ErrRoleForbidden
+	}
+	actor, _ := infra.CurrentUser(s.Worker.IrisContext())
+	own, err := rolePermissions(s.RoleRepo, actor.Role)
+	if err != nil {
+		return err
+	}
+	for _, permission := range role.Permissions {
+		if !own[permission] {
+			return ErrRoleForbidden
+		}
+	}
+	return nil
+}
+
+// checkRoleChange 修改已有用户的角色：角色必须存在，且需要 roles:write 权限
+func (s *UserService) checkRoleChange(code string) error {
+	if _, err := s.RoleRepo.FindByCode(code); err != nil {
+		if errors.Is(err, dependency.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	return s.requireRolePermission()
+}
+
+// requireRolePermission 当前用户没有 roles:write 权限时返回 ErrRoleForbidden
+func (s *UserService) requireRolePermission() error {
+	ok, err := s.actorAllowed(rolePermission)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRoleForbidden
+	}
+	return nil
+}
+
+// checkManage 修改、删除、封禁管理员账户（包括重置其密码）需要 roles:write 权限
+func (s *UserService) checkManage(user *vo.User) error {
+	if user.Role != AdminRole {
+		return nil
+	}
+	return s.requireRolePermission()
+}
+
+// Authenticate 校验用户名和密码，只有活跃用户可以登录
+func (s *UserService) Authenticate(username, password string) (*vo.User, error) {
+	user, err := s.UserRepo.FindByUsername(username)
+	if errors.Is(err, dependency.ErrNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Status != "active" {
+		return nil, ErrUserInactive
+	}
+	return user, nil
+}
+
+// EnsureAdmin 确保配置的管理员账号存在，已存在时不做修改
+func (s *UserService) EnsureAdmin(username, password string) error {
+	_, err := s.Create(vo.UserFormData{
+		Username: username,
+		Email:    username + "@godash.local",
+		RealName: "管理员",
+		Role:     "admin",
+		Status:   "active",
+		Password: password,
+	})
+	if errors.Is(err, ErrUsernameExists) {
+		return nil
+	}
+	return err
+}
+
+// hashPassword 生成 bcrypt 密码哈希
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,36 @@
+package vo
+
+import "time"
+
+// AuditLog 审计日志，记录一次增删改操作
+type AuditLog struct {
+	ID        int64         `json:"id"`
+	ActorID   int64         `json:"actor_id"` // 操作人 ID，系统任务为 0
+	Actor     string        `json:"actor"`    // 操作人用户名
+	Entity    string        `json:"entity"`   // user, product, order, role
+	EntityID  int64         `json:"entity_id"`
+	Action    string        `json:"action"`  // create, update, delete
+	Changes   []AuditChange `json:"changes"` // 变更前后的字段差异
+	RequestID string        `json:"request_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// AuditChange 单个字段的变更
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// AuditFilter 审计日志筛选条件
+type AuditFilter struct {
+	Entity string `json:"entity" url:"entity" validate:"omitempty,oneof=user product order role refund"`
+	Actor  string `json:"actor" url:"actor" validate:"max=50"`
+}
+
+// AuditListData 审计日志列表数据
+type AuditListData struct {
+	Logs     []AuditLog  `json:"logs"`
+	PageInfo PageInfo    `json:"page_info"`
+	Filter   AuditFilter `json:"filter"` // 当前筛选条件
+}
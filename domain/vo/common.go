@@ -1,21 +1,172 @@
 package vo
 
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// 分页模式
+const (
+	PageModeOffset = "offset" // 页码分页，统计总数
+	PageModeCursor = "cursor" // 游标分页，不统计总数，适合大列表
+)
+
 // PageInfo 分页信息
 type PageInfo struct {
-	Page       int   `json:"page"`        // 当前页码
-	PageSize   int   `json:"page_size"`   // 每页数量
-	Total      int64 `json:"total"`       // 总记录数
-	TotalPages int   `json:"total_pages"` // 总页数
+	Page       int    `json:"page"`                  // 当前页码
+	PageSize   int    `json:"page_size"`             // 每页数量
+	Total      int64  `json:"total"`                 // 总记录数
+	TotalPages int    `json:"total_pages"`           // 总页数
+	Mode       string `json:"mode"`                  // offset, cursor
+	Cursor     string `json:"cursor,omitempty"`      // 当前游标，首页为空
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，为空表示没有更多
+}
+
+// ListQuery 列表查询规格，筛选和分页都下推到资源库执行
+type ListQuery struct {
+	Keyword  string    // 关键词
+	Sort     SortState // 排序字段，由资源库按白名单过滤
+	Page     int       // 页码，从 1 开始
+	PageSize int       // 每页数量
+	Mode     string    // 分页模式，默认 offset
+	Cursor   string    // 游标模式下上一页最后一条记录的 ID
+}
+
+// Offset 页码模式下跳过的记录数
+func (q ListQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// CursorMode 是否使用游标分页
+func (q ListQuery) CursorMode() bool {
+	return q.Mode == PageModeCursor
 }
 
-// SearchParams 搜索参数
+// maxSortFields 最多同时排序的字段数
+const maxSortFields = 3
+
+// SortField 排序字段
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SortState 多列排序，第一个字段优先
+type SortState []SortField
+
+// ParseSort 解析 sort_by=price,created_at&order=desc,asc，缺少的方向按升序
+func ParseSort(sortBy, order string) SortState {
+	if sortBy == "" {
+		return nil
+	}
+	orders := strings.Split(order, ",")
+	var state SortState
+	for i, field := range strings.Split(sortBy, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || state.Direction(field) != "" {
+			continue
+		}
+		desc := i < len(orders) && strings.EqualFold(strings.TrimSpace(orders[i]), "desc")
+		state = append(state, SortField{Field: field, Desc: desc})
+		if len(state) == maxSortFields {
+			break
+		}
+	}
+	return state
+}
+
+// Direction 字段的排序方向 asc/desc，未参与排序时为空
+func (s SortState) Direction(field string) string {
+	for _, f := range s {
+		if f.Field == field {
+			if f.Desc {
+				return "desc"
+			}
+			return "asc"
+		}
+	}
+	return ""
+}
+
+// By 当前排序字段，逗号分隔
+func (s SortState) By() string {
+	fields := make([]string, len(s))
+	for i, f := range s {
+		fields[i] = f.Field
+	}
+	return strings.Join(fields, ",")
+}
+
+// Orders 当前排序方向，逗号分隔
+func (s SortState) Orders() string {
+	orders := make([]string, len(s))
+	for i, f := range s {
+		orders[i] = "asc"
+		if f.Desc {
+			orders[i] = "desc"
+		}
+	}
+	return strings.Join(orders, ",")
+}
+
+// Toggle 点击表头后的排序参数：被点击的字段成为第一排序，
+// 已是第一排序时切换方向，其余字段保持原顺序作为次要排序
+func (s SortState) Toggle(field string) string {
+	next := SortState{{Field: field}}
+	if len(s) > 0 && s[0].Field == field {
+		next[0].Desc = !s[0].Desc
+	}
+	for _, f := range s {
+		if f.Field != field && len(next) < maxSortFields {
+			next = append(next, f)
+		}
+	}
+	return "sort_by=" + next.By() + "&order=" + next.Orders()
+}
+
+// Page 一页类型化的数据
+type Page[T any] struct {
+	Items    []T      `json:"items"`
+	PageInfo PageInfo `json:"page_info"`
+}
+
+// NewPage 页码模式的分页结果
+func NewPage[T any](items []T, total int64, query ListQuery) Page[T] {
+	return Page[T]{
+		Items: items,
+		PageInfo: PageInfo{
+			Page:       query.Page,
+			PageSize:   query.PageSize,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(query.PageSize))),
+			Mode:       PageModeOffset,
+		},
+	}
+}
+
+// NewCursorPage 游标模式的分页结果，nextCursor 为空表示没有下一页
+func NewCursorPage[T any](items []T, query ListQuery, nextCursor string) Page[T] {
+	return Page[T]{
+		Items: items,
+		PageInfo: PageInfo{
+			PageSize:   query.PageSize,
+			Mode:       PageModeCursor,
+			Cursor:     query.Cursor,
+			NextCursor: nextCursor,
+		},
+	}
+}
+
+// SearchParams 搜索、排序和分页参数，各列表的筛选条件见 UserFilter、ProductFilter 等
 type SearchParams struct {
 	Keyword  string `url:"keyword"`   // 搜索关键词
 	Page     int    `url:"page"`      // 页码
 	PageSize int    `url:"page_size"` // 每页数量
-	Status   string `url:"status"`    // 状态筛选
 	SortBy   string `url:"sort_by"`   // 排序字段
 	Order    string `url:"order"`     // 排序方向 asc/desc
+	Mode     string `url:"mode"`      // 分页模式 offset/cursor
+	Cursor   string `url:"cursor"`    // 游标
 }
 
 // Response 通用响应结构
@@ -24,3 +175,36 @@ type Response struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
+
+// dateLayout 筛选条件中的日期格式
+const dateLayout = "2006-01-02"
+
+// dayRange 把日期区间转换为 [from, to)，to 为结束日期的次日零点，空值返回零时间
+func dayRange(dateFrom, dateTo string) (from, to time.Time) {
+	if t, err := time.ParseInLocation(dateLayout, dateFrom, time.Local); err == nil {
+		from = t
+	}
+	if t, err := time.ParseInLocation(dateLayout, dateTo, time.Local); err == nil {
+		to = t.AddDate(0, 0, 1)
+	}
+	return
+}
+
+// BulkForm 批量操作表单，ids 可重复提交多个
+type BulkForm struct {
+	IDs    []int64 `form:"ids" validate:"required,min=1,max=200"`
+	Action string  `form:"action" validate:"required"`
+	Value  string  `form:"value"` // 设置角色、订单状态等操作的目标值
+}
+
+// BulkFailure 批量操作中失败的记录
+type BulkFailure struct {
+	ID     int64
+	Reason string
+}
+
+// BulkResult 批量操作结果，单条失败不影响其余记录
+type BulkResult[T any] struct {
+	Succeeded []T
+	Failed    []BulkFailure
+}
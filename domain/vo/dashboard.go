@@ -1,5 +1,7 @@
 package vo
 
+import "time"
+
 // DashboardStats 仪表盘统计数据
 type DashboardStats struct {
 	TotalUsers    int64   `json:"total_users"`    // 总用户数
@@ -10,6 +12,42 @@ type DashboardStats struct {
 	PendingOrders int64   `json:"pending_orders"` // 待处理订单
 	LowStock      int64   `json:"low_stock"`      // 低库存商品
 	TodayOrders   int64   `json:"today_orders"`   // 今日订单
+	TodayRevenue  float64 `json:"today_revenue"`  // 今日收入
+
+	LowStockThreshold int `json:"low_stock_threshold"` // 可售库存不超过此值视为低库存
+
+	Access DashboardAccess `json:"-"`
+}
+
+// DashboardAccess 当前用户在仪表盘上可以看到的模块，由控制器按各模块的读权限决定，
+// 不可见的模块不查询，对应的数字保持为 0
+type DashboardAccess struct {
+	Users    bool // users:read，用户数、最近用户和新用户趋势
+	Products bool // products:read，商品数和低库存
+	Orders   bool // orders:read，订单数、收入、最近订单、热门商品和订单趋势
+}
+
+// OrderStats 订单汇总，收入为已付款订单的金额减去已确认的退款，今日按下单时间统计
+type OrderStats struct {
+	Total        int64   `json:"total"`
+	Pending      int64   `json:"pending"`
+	Today        int64   `json:"today"`
+	Revenue      float64 `json:"revenue"`
+	TodayRevenue float64 `json:"today_revenue"`
+}
+
+// ProductStats 商品汇总
+type ProductStats struct {
+	Total    int64 `json:"total"`
+	LowStock int64 `json:"low_stock"` // 未下架且可售库存不超过阈值的商品数
+}
+
+// ProductSales 商品销量，按 SKU 汇总已售出的订单项
+type ProductSales struct {
+	SKU         string  `json:"sku"`
+	ProductName string  `json:"product_name"`
+	Quantity    int64   `json:"quantity"` // 售出件数
+	Amount      float64 `json:"amount"`   // 销售额
 }
 
 // DashboardData 仪表盘数据
@@ -17,7 +55,60 @@ type DashboardData struct {
 	Stats        DashboardStats `json:"stats"`
 	RecentOrders []Order        `json:"recent_orders"` // 最近订单
 	RecentUsers  []User         `json:"recent_users"`  // 最近用户
-	TopProducts  []Product      `json:"top_products"`  // 热门商品
+	TopProducts  []ProductSales `json:"top_products"`  // 热门商品，按销量排序
+	Series       Series         `json:"series"`        // 趋势图
+}
+
+// 趋势图的时间粒度
+const (
+	SeriesDay   = "day"
+	SeriesWeek  = "week"
+	SeriesMonth = "month"
+)
+
+// SeriesQuery 趋势图查询参数：最近 Days 天，按 Interval 汇总
+type SeriesQuery struct {
+	Interval string `json:"interval" url:"interval" validate:"omitempty,oneof=day week month"`
+	Days     int    `json:"days" url:"days" validate:"omitempty,oneof=7 30 90 180 365"`
+}
+
+// Normalize 补齐默认值：最近 30 天，按天汇总
+func (q SeriesQuery) Normalize() SeriesQuery {
+	if q.Interval == "" {
+		q.Interval = SeriesDay
+	}
+	if q.Days == 0 {
+		q.Days = 30
+	}
+	return q
+}
+
+// SeriesPoint 一个时间段的汇总
+type SeriesPoint struct {
+	Start    time.Time `json:"start"` // 时间段开始，系统时区的零点
+	Label    string    `json:"label"`
+	Orders   int64     `json:"orders"`
+	Revenue  float64   `json:"revenue"`
+	NewUsers int64     `json:"new_users"`
+}
+
+// Series 按天、周或月汇总的订单数、收入和新用户，收入口径与仪表盘统计一致
+type Series struct {
+	SeriesQuery
+	From          time.Time     `json:"from"`
+	To            time.Time     `json:"to"` // 不含
+	Points        []SeriesPoint `json:"points"`
+	TotalOrders   int64         `json:"total_orders"`
+	TotalRevenue  float64       `json:"total_revenue"`
+	TotalNewUsers int64         `json:"total_new_users"`
+
+	Access DashboardAccess `json:"-"`
+}
+
+// OrderPoint 订单的下单时间和计入收入的金额（未付款为 0，已付款扣除已确认的退款）
+type OrderPoint struct {
+	CreatedAt time.Time
+	Revenue   float64
 }
 
 // SettingsData 系统设置数据（扩展版本）
@@ -35,7 +126,7 @@ type SettingsData struct {
 
 	// 区域设置
 	Currency   string `json:"currency" form:"currency" validate:"required"`
-	Timezone   string `json:"timezone" form:"timezone" validate:"required"`
+	Timezone   string `json:"timezone" form:"timezone" validate:"required,timezone"`
 	Language   string `json:"language" form:"language" validate:"required"`
 	DateFormat string `json:"date_format" form:"date_format"`
 
@@ -44,14 +135,11 @@ type SettingsData struct {
 	SidebarColor string `json:"sidebar_color" form:"sidebar_color"`
 
 	// 功能开关
-	EnableRegistration  bool `json:"enable_registration" form:"enable_registration"`
-	EnableComments      bool `json:"enable_comments" form:"enable_comments"`
-	EnableNotifications bool `json:"enable_notifications" form:"enable_notifications"`
-	MaintenanceMode     bool `json:"maintenance_mode" form:"maintenance_mode"`
+	MaintenanceMode bool `json:"maintenance_mode" form:"maintenance_mode"`
 
 	// 其他
-	ItemsPerPage   int `json:"items_per_page" form:"items_per_page"`
-	SessionTimeout int `json:"session_timeout" form:"session_timeout"`
+	ItemsPerPage      int `json:"items_per_page" form:"items_per_page" validate:"gte=0,lte=100"`
+	LowStockThreshold int `json:"low_stock_threshold" form:"low_stock_threshold" validate:"gte=0,lte=100000"`
 }
 
 // SettingsStats 设置统计信息
@@ -0,0 +1,31 @@
+package vo
+
+import "time"
+
+// 领域事件主题，同时是 SSE 的事件名
+const (
+	EventOrderCreated = "order-created" // 创建订单
+	EventOrderUpdated = "order-updated" // 编辑待处理订单
+	EventOrderStatus  = "order-status"  // 订单状态变更，包括退款
+	EventStockLow     = "stock-low"     // 商品可售库存降到低库存阈值及以下
+	EventUserCreated  = "user-created"  // 新增用户
+	EventNotification = "notification"  // 生成了站内通知，数据为接收人 ID
+)
+
+// EventTopics 可以订阅的事件主题
+var EventTopics = []string{EventOrderCreated, EventOrderUpdated, EventOrderStatus, EventStockLow, EventUserCreated, EventNotification}
+
+// Event 领域事件，Entity 与审计日志的实体类型一致
+type Event struct {
+	Topic    string                 `json:"topic"`
+	Entity   string                 `json:"entity"`
+	EntityID int64                  `json:"entity_id"`
+	ActorID  int64                  `json:"actor_id"` // 触发事件的登录用户，系统任务为 0
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Time     time.Time              `json:"time"`
+}
+
+// EventQuery 事件流的订阅参数，Topics 为空时订阅全部主题
+type EventQuery struct {
+	Topics []string `url:"topic" validate:"max=10,dive,oneof=order-created order-updated order-status stock-low user-created notification"`
+}
@@ -0,0 +1,40 @@
+package vo
+
+import "time"
+
+// NotificationUnread 通知筛选：只看未读
+const NotificationUnread = "unread"
+
+// Notification 站内通知，由领域事件按接收人生成
+type Notification struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"` // 接收人
+	Topic     string    `json:"topic"`   // 来源事件主题，如 order-created
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Link      string    `json:"link"` // 点击后打开的页面
+	Entity    string    `json:"entity"`
+	EntityID  int64     `json:"entity_id"`
+	Read      bool      `json:"read"`
+	ReadAt    time.Time `json:"read_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationFilter 通知筛选条件
+type NotificationFilter struct {
+	Status string `json:"status" url:"status" validate:"omitempty,oneof=unread"`
+}
+
+// NotificationListData 通知列表数据
+type NotificationListData struct {
+	Notifications []Notification     `json:"notifications"`
+	PageInfo      PageInfo           `json:"page_info"`
+	Filter        NotificationFilter `json:"filter"`
+	Unread        int                `json:"unread"`
+}
+
+// NotificationMenu 顶部栏通知下拉的数据：未读数和最新几条通知
+type NotificationMenu struct {
+	Unread int            `json:"unread"`
+	Recent []Notification `json:"recent"`
+}
@@ -1,6 +1,9 @@
 package vo
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Order 订单信息
 type Order struct {
@@ -9,9 +12,10 @@ type Order struct {
 	CustomerName  string      `json:"customer_name"`   // 客户名称
 	CustomerEmail string      `json:"customer_email"`  // 客户邮箱
 	TotalAmount   float64     `json:"total_amount"`    // 总金额
-	Status        string      `json:"status"`          // pending, paid, shipped, completed, cancelled
+	Status        string      `json:"status"`          // pending, paid, shipped, completed, cancelled, partially_refunded, refunded
 	PaymentMethod string      `json:"payment_method"`  // 支付方式
 	Items         []OrderItem `json:"items,omitempty"` // 订单项
+	Fulfillment   string      `json:"-"`               // 部分退款前的状态（paid、shipped 或 completed），由领域服务按状态变更记录填充
 	CreatedAt     time.Time   `json:"created_at"`
 	UpdatedAt     time.Time   `json:"updated_at"`
 }
@@ -26,16 +30,201 @@ type OrderItem struct {
 	Subtotal    float64 `json:"subtotal"`
 }
 
+// 订单状态
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderCompleted = "completed"
+	OrderCancelled = "cancelled"
+	// 退款产生的状态，只能通过确认退款进入
+	OrderPartiallyRefunded = "partially_refunded"
+	OrderRefunded          = "refunded"
+)
+
+// OrderStatuses 订单状态
+var OrderStatuses = []string{OrderPending, OrderPaid, OrderShipped, OrderCompleted, OrderCancelled,
+	OrderPartiallyRefunded, OrderRefunded}
+
+// OrderPaidStatuses 已付款的订单状态，订单金额减去已确认的退款计入收入
+var OrderPaidStatuses = []string{OrderPaid, OrderShipped, OrderCompleted, OrderPartiallyRefunded, OrderRefunded}
+
+// OrderSoldStatuses 计入商品销量的订单状态：已付款且没有全额退款
+var OrderSoldStatuses = []string{OrderPaid, OrderShipped, OrderCompleted, OrderPartiallyRefunded}
+
+// orderStatusTexts 订单状态的中文名称
+var orderStatusTexts = map[string]string{
+	OrderPending:   "待处理",
+	OrderPaid:      "已支付",
+	OrderShipped:   "已发货",
+	OrderCompleted: "已完成",
+	OrderCancelled: "已取消",
+
+	OrderPartiallyRefunded: "部分退款",
+	OrderRefunded:          "已退款",
+}
+
+// orderTransitions 订单状态机：pending→paid→shipped→completed，只有未发货的订单可以取消；
+// completed 和 cancelled 是终态。只能由确认退款进入退款状态，见 Order.Refundable
+var orderTransitions = map[string][]string{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderShipped, OrderCancelled},
+	OrderShipped: {OrderCompleted},
+}
+
+// fulfillmentTransitions 部分退款的订单按退款前的状态继续发货或完成，不能再取消
+var fulfillmentTransitions = map[string][]string{
+	OrderPaid:    {OrderShipped},
+	OrderShipped: {OrderCompleted},
+}
+
+// OrderStatusText 订单状态的中文名称，未知状态原样返回
+func OrderStatusText(status string) string {
+	if text, ok := orderStatusTexts[status]; ok {
+		return text
+	}
+	return status
+}
+
+// NextStatuses 当前状态可以流转到的状态，部分退款的订单按 Fulfillment 决定
+func (o Order) NextStatuses() []string {
+	if o.Status == OrderPartiallyRefunded {
+		return fulfillmentTransitions[o.Fulfillment]
+	}
+	return orderTransitions[o.Status]
+}
+
+// CanTransit 当前状态能否流转到 status
+func (o Order) CanTransit(status string) bool {
+	return slices.Contains(o.NextStatuses(), status)
+}
+
+// FulfillmentBeforeRefund 按状态变更记录找出订单最近一次进入部分退款前的状态，没有记录时返回空
+func FulfillmentBeforeRefund(history []OrderStatusChange) string {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].To == OrderPartiallyRefunded {
+			return history[i].From
+		}
+	}
+	return ""
+}
+
+// OrderStatusChange 订单状态变更记录
+type OrderStatusChange struct {
+	ID        int64     `json:"id"`
+	OrderID   int64     `json:"order_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ActorID   int64     `json:"actor_id"`
+	Actor     string    `json:"actor"` // 操作人用户名，系统操作为 system
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PaymentMethods 支持的支付方式
+var PaymentMethods = []string{"支付宝", "微信支付", "银行卡", "货到付款"}
+
+// OrderFilter 订单列表筛选条件，日期格式为 2006-01-02，金额为 0 表示不限
+type OrderFilter struct {
+	Status        string  `json:"status" url:"status" validate:"omitempty,oneof=pending paid shipped completed cancelled partially_refunded refunded"`
+	PaymentMethod string  `json:"payment_method" url:"payment_method" validate:"max=32"`
+	DateFrom      string  `json:"date_from" url:"date_from" validate:"omitempty,date"`
+	DateTo        string  `json:"date_to" url:"date_to" validate:"omitempty,date"`
+	MinAmount     float64 `json:"min_amount" url:"min_amount" validate:"gte=0"`
+	MaxAmount     float64 `json:"max_amount" url:"max_amount" validate:"omitempty,gtefield=MinAmount"`
+}
+
+// CreatedRange 下单时间区间 [from, to)，未设置的一端为零时间
+func (f OrderFilter) CreatedRange() (from, to time.Time) {
+	return dayRange(f.DateFrom, f.DateTo)
+}
+
 // OrderListData 订单列表数据
 type OrderListData struct {
-	Orders   []Order  `json:"orders"`
-	PageInfo PageInfo `json:"page_info"`
-	Query    string   `json:"query"`  // 当前搜索关键词
-	Status   string   `json:"status"` // 当前状态筛选
+	Orders   []Order     `json:"orders"`
+	PageInfo PageInfo    `json:"page_info"`
+	Query    string      `json:"query"`  // 当前搜索关键词
+	Filter   OrderFilter `json:"filter"` // 当前筛选条件
+	Sort     SortState   `json:"sort"`   // 当前排序
+	Payments []string    `json:"-"`      // 支付方式筛选选项
 }
 
 // OrderDetailData 订单详情数据
 type OrderDetailData struct {
-	Order    Order `json:"order"`
-	IsModal  bool  `json:"is_modal"`
+	Order   Order               `json:"order"`
+	History []OrderStatusChange `json:"history"` // 状态变更记录，按时间先后排列
+	Refunds []Refund            `json:"refunds"` // 退款记录，按申请时间先后排列
+	IsModal bool                `json:"is_modal"`
+}
+
+// RefundableQuantity 订单项还可以申请退款的数量
+func (d OrderDetailData) RefundableQuantity(item OrderItem) int {
+	return max(item.Quantity-RefundedQuantities(d.Refunds)[item.ID], 0)
+}
+
+// CanRefund 订单状态允许退款且还有可退的订单项或金额
+func (d OrderDetailData) CanRefund() bool {
+	if !d.Order.Refundable() {
+		return false
+	}
+	if len(d.Order.Items) == 0 {
+		return RefundedAmount(d.Refunds) < d.Order.TotalAmount
+	}
+	for _, item := range d.Order.Items {
+		if d.RefundableQuantity(item) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderFormData 订单表单数据（用于新增/编辑）。订单项的 SKU 和数量按下标一一对应，
+// 名称、单价和金额由服务端按商品当前价格重新计算；编辑时 CustomerID 为 0 表示保留原客户
+type OrderFormData struct {
+	CustomerID    int64    `json:"customer_id" form:"customer_id" validate:"gte=0"`
+	PaymentMethod string   `json:"payment_method" form:"payment_method" validate:"required"`
+	SKUs          []string `json:"skus" form:"sku" validate:"required,max=50,dive,required,max=64"`
+	Quantities    []int    `json:"quantities" form:"quantity" validate:"required,max=50,dive,gt=0,lte=9999"`
+}
+
+// Items 按 SKU 合并数量后的订单项，保持首次出现的顺序；缺少数量的 SKU 按 0 处理
+func (f OrderFormData) Items() []OrderItem {
+	var items []OrderItem
+	index := make(map[string]int, len(f.SKUs))
+	for i, sku := range f.SKUs {
+		quantity := 0
+		if i < len(f.Quantities) {
+			quantity = f.Quantities[i]
+		}
+		if n, ok := index[sku]; ok {
+			items[n].Quantity += quantity
+			continue
+		}
+		index[sku] = len(items)
+		items = append(items, OrderItem{SKU: sku, Quantity: quantity})
+	}
+	return items
+}
+
+// OrderLine 订单表单中的一行：按商品当前价格计算的订单项和下单前的库存检查结果
+type OrderLine struct {
+	OrderItem
+	Available int    `json:"available"` // 可售库存，编辑订单时包含本订单已预留的数量
+	Error     string `json:"error"`     // 商品不存在、已下架或库存不足时的提示
+}
+
+// OrderQuote 订单表单的实时报价，只计算不预留库存
+type OrderQuote struct {
+	Lines       []OrderLine `json:"lines"`
+	TotalAmount float64     `json:"total_amount"`
+	Valid       bool        `json:"valid"` // 至少一行且每行都没有错误
+}
+
+// OrderFormPage 订单新增/编辑页面数据，Order 为 nil 时是新增
+type OrderFormPage struct {
+	Order    *Order        `json:"order"`
+	Form     OrderFormData `json:"form"`
+	Quote    OrderQuote    `json:"quote"`
+	Users    []User        `json:"users"`    // 可选客户
+	Payments []string      `json:"payments"` // 支付方式选项
+	Error    string        `json:"error"`
 }
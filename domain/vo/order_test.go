@@ -0,0 +1,75 @@
+package vo
+
+import "testing"
+
+func TestOrderCanTransit(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{OrderPending, OrderPaid, true},
+		{OrderPending, OrderCancelled, true},
+		{OrderPending, OrderShipped, false},
+		{OrderPaid, OrderShipped, true},
+		{OrderPaid, OrderCancelled, true},
+		{OrderPaid, OrderRefunded, false},
+		{OrderShipped, OrderCompleted, true},
+		{OrderShipped, OrderCancelled, false},
+		{OrderCompleted, OrderRefunded, false},
+		{OrderCancelled, OrderPaid, false},
+		{OrderRefunded, OrderShipped, false},
+	}
+	for _, tt := range tests {
+		if got := (Order{Status: tt.from}).CanTransit(tt.to); got != tt.want {
+			t.Errorf("%s → %s: CanTransit = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestOrderPartiallyRefundedTransit(t *testing.T) {
+	// 部分退款的订单按退款前的状态继续发货或完成，不能取消
+	tests := []struct {
+		fulfillment, to string
+		want            bool
+	}{
+		{OrderPaid, OrderShipped, true},
+		{OrderPaid, OrderCompleted, false},
+		{OrderPaid, OrderCancelled, false},
+		{OrderShipped, OrderCompleted, true},
+		{OrderShipped, OrderShipped, false},
+		{OrderCompleted, OrderShipped, false},
+		{OrderCompleted, OrderCompleted, false},
+		{"", OrderShipped, false},
+	}
+	for _, tt := range tests {
+		order := Order{Status: OrderPartiallyRefunded, Fulfillment: tt.fulfillment}
+		if got := order.CanTransit(tt.to); got != tt.want {
+			t.Errorf("partially_refunded after %q → %s: CanTransit = %v, want %v", tt.fulfillment, tt.to, got, tt.want)
+		}
+	}
+
+	history := []OrderStatusChange{
+		{From: OrderPending, To: OrderPaid},
+		{From: OrderPaid, To: OrderPartiallyRefunded},
+		{From: OrderPartiallyRefunded, To: OrderShipped},
+		{From: OrderShipped, To: OrderPartiallyRefunded},
+	}
+	if got := FulfillmentBeforeRefund(history); got != OrderShipped {
+		t.Errorf("FulfillmentBeforeRefund = %q, want the latest state before the refund", got)
+	}
+}
+
+func TestOrderRefundable(t *testing.T) {
+	// 只有确认退款可以进入退款状态，手动流转不能进入部分退款或已退款
+	for _, status := range OrderStatuses {
+		for _, fulfillment := range []string{"", OrderPaid, OrderShipped, OrderCompleted} {
+			order := Order{Status: status, Fulfillment: fulfillment}
+			if order.CanTransit(OrderPartiallyRefunded) || order.CanTransit(OrderRefunded) {
+				t.Errorf("%s (%q) must not transit to a refund status manually", status, fulfillment)
+			}
+		}
+	}
+	if !(Order{Status: OrderPaid}).Refundable() || (Order{Status: OrderCancelled}).Refundable() {
+		t.Error("paid orders must be refundable and cancelled orders must not")
+	}
+}
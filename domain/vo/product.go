@@ -10,6 +10,7 @@ type Product struct {
 	Category    string    `json:"category"`    // 分类
 	Price       float64   `json:"price"`       // 价格
 	Stock       int       `json:"stock"`       // 库存
+	Reserved    int       `json:"reserved"`    // 未支付订单预留的库存
 	Status      string    `json:"status"`      // active, inactive, out_of_stock
 	Image       string    `json:"image"`       // 商品图片URL
 	Description string    `json:"description"` // 描述
@@ -17,12 +18,36 @@ type Product struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// Available 可售库存：库存减去预留
+func (p Product) Available() int {
+	return p.Stock - p.Reserved
+}
+
+// SyncStockStatus 按可售库存在在售和缺货之间自动切换，下架的商品保持不变
+func (p *Product) SyncStockStatus() {
+	switch {
+	case p.Status == "active" && p.Available() <= 0:
+		p.Status = "out_of_stock"
+	case p.Status == "out_of_stock" && p.Available() > 0:
+		p.Status = "active"
+	}
+}
+
+// ProductFilter 商品列表筛选条件，价格为 0 表示不限
+type ProductFilter struct {
+	Category string  `json:"category" url:"category" validate:"max=50"`
+	Status   string  `json:"status" url:"status" validate:"omitempty,oneof=active inactive out_of_stock"`
+	MinPrice float64 `json:"min_price" url:"min_price" validate:"gte=0"`
+	MaxPrice float64 `json:"max_price" url:"max_price" validate:"omitempty,gtefield=MinPrice"`
+}
+
 // ProductListData 商品列表数据
 type ProductListData struct {
-	Products []Product `json:"products"`
-	PageInfo PageInfo  `json:"page_info"`
-	Query    string    `json:"query"`    // 当前搜索关键词
-	Category string    `json:"category"` // 当前分类筛选（保留，已经有了）
+	Products []Product     `json:"products"`
+	PageInfo PageInfo      `json:"page_info"`
+	Query    string        `json:"query"`  // 当前搜索关键词
+	Filter   ProductFilter `json:"filter"` // 当前筛选条件
+	Sort     SortState     `json:"sort"`   // 当前排序
 }
 
 // ProductFormData 商品表单数据
@@ -32,7 +57,22 @@ type ProductFormData struct {
 	SKU         string  `json:"sku" form:"sku" validate:"required"`
 	Category    string  `json:"category" form:"category" validate:"required"`
 	Price       float64 `json:"price" form:"price" validate:"required,gt=0"`
-	Stock       int     `json:"stock" form:"stock" validate:"required,gte=0"`
-	Status      string  `json:"status" form:"status" validate:"required"`
+	Stock       int     `json:"stock" form:"stock" validate:"gte=0"`
+	Status      string  `json:"status" form:"status" validate:"required,oneof=active inactive out_of_stock"`
 	Description string  `json:"description" form:"description"`
 }
+
+// ProductImportRow CSV 导入的一行
+type ProductImportRow struct {
+	Line   int             `json:"line"` // CSV 中的行号，表头为第 1 行
+	Data   ProductFormData `json:"data"`
+	Errors []string        `json:"errors"` // 为空表示可以导入
+}
+
+// ProductImportReport 商品导入报告
+type ProductImportReport struct {
+	DryRun   bool               `json:"dry_run"` // 只校验不写入
+	Rows     []ProductImportRow `json:"rows"`
+	Failed   int                `json:"failed"`   // 校验失败的行数
+	Imported int                `json:"imported"` // 实际写入的行数
+}
@@ -0,0 +1,59 @@
+package vo
+
+import "time"
+
+// Role 角色
+type Role struct {
+	ID          int64     `json:"id"`
+	Code        string    `json:"code"` // 角色标识，对应 User.Role
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"` // 权限标识，如 products:write
+	System      bool      `json:"system"`      // 系统内置角色不可删除
+	UserCount   int       `json:"user_count"`  // 关联用户数，仅列表展示
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Has 角色是否拥有权限
+func (r Role) Has(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionModule 权限模块
+type PermissionModule struct {
+	Code string `json:"code"` // users, products, orders ...
+	Name string `json:"name"`
+}
+
+// PermissionAction 权限动作
+type PermissionAction struct {
+	Code string `json:"code"` // read, write, delete
+	Name string `json:"name"`
+}
+
+// RoleListData 角色列表数据
+type RoleListData struct {
+	Roles []Role `json:"roles"`
+}
+
+// RoleFormData 角色表单数据（用于新增/编辑）
+type RoleFormData struct {
+	ID          int64    `json:"id" form:"id"`
+	Code        string   `json:"code" form:"code" validate:"required,alphanum,max=32"`
+	Name        string   `json:"name" form:"name" validate:"required"`
+	Description string   `json:"description" form:"description"`
+	Permissions []string `json:"permissions" form:"permissions"`
+}
+
+// PermissionMatrixData 权限矩阵数据
+type PermissionMatrixData struct {
+	Roles   []Role             `json:"roles"`
+	Modules []PermissionModule `json:"modules"`
+	Actions []PermissionAction `json:"actions"`
+}
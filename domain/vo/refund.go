@@ -0,0 +1,107 @@
+package vo
+
+import (
+	"slices"
+	"time"
+)
+
+// 退款状态：申请后由管理员确认或拒绝，确认时退回库存并更新订单状态
+const (
+	RefundPending   = "pending"
+	RefundCompleted = "completed"
+	RefundRejected  = "rejected"
+)
+
+// refundStatusTexts 退款状态的中文名称
+var refundStatusTexts = map[string]string{
+	RefundPending:   "待确认",
+	RefundCompleted: "已退款",
+	RefundRejected:  "已拒绝",
+}
+
+// RefundStatusText 退款状态的中文名称，未知状态原样返回
+func RefundStatusText(status string) string {
+	if text, ok := refundStatusTexts[status]; ok {
+		return text
+	}
+	return status
+}
+
+// refundableStatuses 可以申请退款的订单状态：已付款且未取消
+var refundableStatuses = []string{OrderPaid, OrderShipped, OrderCompleted, OrderPartiallyRefunded}
+
+// Refundable 订单当前状态是否可以退款
+func (o Order) Refundable() bool {
+	return slices.Contains(refundableStatuses, o.Status)
+}
+
+// Refund 退款记录，Items 为空表示没有订单项的订单按总额退款
+type Refund struct {
+	ID        int64        `json:"id"`
+	OrderID   int64        `json:"order_id"`
+	Amount    float64      `json:"amount"`  // 退款金额，按订单项单价计算
+	Reason    string       `json:"reason"`  // 退款原因
+	Status    string       `json:"status"`  // pending, completed, rejected
+	Restock   bool         `json:"restock"` // 确认退款时是否退回库存
+	Items     []RefundItem `json:"items,omitempty"`
+	ActorID   int64        `json:"actor_id"` // 申请人
+	Actor     string       `json:"actor"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// RefundItem 退款的订单项和数量
+type RefundItem struct {
+	ID          int64   `json:"id"`
+	OrderItemID int64   `json:"order_item_id"`
+	ProductName string  `json:"product_name"`
+	SKU         string  `json:"sku"`
+	Quantity    int     `json:"quantity"`
+	Amount      float64 `json:"amount"`
+}
+
+// RefundFormData 退款申请表单。Full 不为空时退还全部可退数量，
+// 否则按下标对应的 ItemIDs 和 Quantities 部分退款，数量为 0 的行忽略
+type RefundFormData struct {
+	Full       string  `json:"full" form:"full"`
+	ItemIDs    []int64 `json:"item_ids" form:"item_id" validate:"max=100"`
+	Quantities []int   `json:"quantities" form:"quantity" validate:"max=100,dive,gte=0,lte=9999"`
+	Reason     string  `json:"reason" form:"reason" validate:"required,max=255"`
+	Restock    bool    `json:"restock" form:"restock"`
+}
+
+// RefundQuantities 按订单项 ID 汇总的部分退款数量
+func (f RefundFormData) RefundQuantities() map[int64]int {
+	quantities := make(map[int64]int, len(f.ItemIDs))
+	for i, id := range f.ItemIDs {
+		if i < len(f.Quantities) && f.Quantities[i] > 0 {
+			quantities[id] += f.Quantities[i]
+		}
+	}
+	return quantities
+}
+
+// RefundedQuantities 按订单项 ID 汇总已退款和待确认的数量，已拒绝的不计入
+func RefundedQuantities(refunds []Refund) map[int64]int {
+	quantities := make(map[int64]int)
+	for _, refund := range refunds {
+		if refund.Status == RefundRejected {
+			continue
+		}
+		for _, item := range refund.Items {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+	}
+	return quantities
+}
+
+// RefundedAmount 已退款和待确认的金额之和，已拒绝的不计入
+func RefundedAmount(refunds []Refund) float64 {
+	total := 0.0
+	for _, refund := range refunds {
+		if refund.Status != RefundRejected {
+			total += refund.Amount
+		}
+	}
+	return total
+}
@@ -0,0 +1,151 @@
+package vo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SettingsVersion 系统设置的一个版本，每次保存追加一个新版本
+type SettingsVersion struct {
+	Version      int          `json:"version"` // 从 1 开始递增，从未保存时为 0
+	Settings     SettingsData `json:"settings"`
+	AuthorID     int64        `json:"author_id"`
+	Author       string       `json:"author"`
+	RestoredFrom int          `json:"restored_from,omitempty"` // 由回滚生成时为被恢复的版本号
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// SettingsHistoryData 设置历史版本列表数据
+type SettingsHistoryData struct {
+	Versions []SettingsVersion `json:"versions"`
+	PageInfo PageInfo          `json:"page_info"`
+	Latest   int               `json:"latest"` // 当前生效的版本号
+}
+
+// SettingsDiffQuery 比较两个设置版本，版本 0 表示默认设置
+type SettingsDiffQuery struct {
+	From int `json:"from" url:"from" validate:"gte=0"`
+	To   int `json:"to" url:"to" validate:"gte=0"`
+}
+
+// SettingsDiff 两个设置版本的字段差异
+type SettingsDiff struct {
+	From    SettingsVersion  `json:"from"`
+	To      SettingsVersion  `json:"to"`
+	Changes []SettingsChange `json:"changes"`
+}
+
+// SettingsChange 单个设置项的变化
+type SettingsChange struct {
+	Field  string `json:"field"`
+	Label  string `json:"label"` // 设置项名称，如“站点名称”
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// SettingsFieldLabels 设置项 JSON 字段对应的名称
+var SettingsFieldLabels = map[string]string{
+	"site_name":           "站点名称",
+	"site_description":    "站点描述",
+	"site_logo":           "站点 Logo",
+	"site_url":            "站点地址",
+	"contact_email":       "联系邮箱",
+	"contact_phone":       "联系电话",
+	"contact_address":     "联系地址",
+	"currency":            "货币",
+	"timezone":            "时区",
+	"language":            "语言",
+	"date_format":         "日期格式",
+	"theme_color":         "主题色",
+	"sidebar_color":       "侧边栏颜色",
+	"maintenance_mode":    "维护模式",
+	"items_per_page":      "每页条数",
+	"low_stock_threshold": "低库存阈值",
+}
+
+// SettingsPage 系统设置页面数据
+type SettingsPage struct {
+	SettingsData
+	Stats   SettingsStats
+	Version int // 表单加载时的版本号，保存时提交回来，已有更新的版本时拒绝保存
+}
+
+// defaultItemsPerPage 未设置每页条数时列表的默认值
+const defaultItemsPerPage = 10
+
+// currencySymbols 货币对应的符号
+var currencySymbols = map[string]string{
+	"CNY": "¥",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+}
+
+// locations 已加载的时区，避免每次格式化时间都读取时区数据库
+var locations sync.Map
+
+// Location 设置的时区，无效时使用服务器本地时区
+func (s SettingsData) Location() *time.Location {
+	if cached, ok := locations.Load(s.Timezone); ok {
+		return cached.(*time.Location)
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.Local
+	}
+	locations.Store(s.Timezone, location)
+	return location
+}
+
+// DateLayout 把设置中的日期格式（如 YYYY-MM-DD、DD/MM/YYYY）转换为 Go 的时间格式，
+// 未设置时使用 2006-01-02，本身就是 Go 格式时原样使用
+func (s SettingsData) DateLayout() string {
+	if s.DateFormat == "" {
+		return "2006-01-02"
+	}
+	if strings.Contains(s.DateFormat, "2006") {
+		return s.DateFormat
+	}
+	return strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02", "HH", "15", "mm", "04", "ss", "05").Replace(s.DateFormat)
+}
+
+// FormatDate 按设置的时区和日期格式输出日期
+func (s SettingsData) FormatDate(t time.Time) string {
+	return t.In(s.Location()).Format(s.DateLayout())
+}
+
+// FormatDateTime 日期加时分，日期格式已经包含时间时不再追加
+func (s SettingsData) FormatDateTime(t time.Time) string {
+	layout := s.DateLayout()
+	if !strings.Contains(layout, "15") {
+		layout += " 15:04"
+	}
+	return t.In(s.Location()).Format(layout)
+}
+
+// CurrencySymbol 设置的货币符号，未知货币使用货币代码
+func (s SettingsData) CurrencySymbol() string {
+	if symbol, ok := currencySymbols[s.Currency]; ok {
+		return symbol
+	}
+	return s.Currency + " "
+}
+
+// FormatMoney 带货币符号的金额，日元不保留小数
+func (s SettingsData) FormatMoney(amount float64) string {
+	if s.Currency == "JPY" {
+		return fmt.Sprintf("%s%.0f", s.CurrencySymbol(), amount)
+	}
+	return fmt.Sprintf("%s%.2f", s.CurrencySymbol(), amount)
+}
+
+// PageSize 列表的默认每页条数
+func (s SettingsData) PageSize() int {
+	if s.ItemsPerPage <= 0 {
+		return defaultItemsPerPage
+	}
+	return s.ItemsPerPage
+}
@@ -12,16 +12,25 @@ type User struct {
 	Role      string    `json:"role"`   // admin, editor, viewer
 	Status    string    `json:"status"` // active, inactive, banned
 	Avatar    string    `json:"avatar"` // 头像URL
+	Password  string    `json:"-"`      // bcrypt 哈希，不输出
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UserFilter 用户列表筛选条件
+type UserFilter struct {
+	Role   string `json:"role" url:"role" validate:"omitempty,alphanum,max=32"`
+	Status string `json:"status" url:"status" validate:"omitempty,oneof=active inactive banned"`
+}
+
 // UserListData 用户列表数据
 type UserListData struct {
-	Users    []User   `json:"users"`
-	PageInfo PageInfo `json:"page_info"`
-	Query    string   `json:"query"`  // 当前搜索关键词
-	Status   string   `json:"status"` // 当前状态筛选
+	Users    []User     `json:"users"`
+	PageInfo PageInfo   `json:"page_info"`
+	Query    string     `json:"query"`  // 当前搜索关键词
+	Filter   UserFilter `json:"filter"` // 当前筛选条件
+	Roles    []Role     `json:"roles"`  // 角色筛选选项
+	Sort     SortState  `json:"sort"`   // 当前排序
 }
 
 // UserFormData 用户表单数据（用于新增/编辑）
@@ -35,3 +44,9 @@ type UserFormData struct {
 	Status   string `json:"status" form:"status" validate:"required"`
 	Password string `json:"password" form:"password"` // 新增时必填，编辑时可选
 }
+
+// LoginFormData 登录表单数据
+type LoginFormData struct {
+	Username string `json:"username" form:"username" validate:"required"`
+	Password string `json:"password" form:"password" validate:"required"`
+}
@@ -0,0 +1,264 @@
+package infra
+
+import (
+	"encoding/json"
+	"fmt"
+	"godash/domain/vo"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindInfra(false, func() *EventBus {
+			return &EventBus{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (com *EventBus) {
+			initiator.FetchInfra(ctx, &com)
+			return
+		})
+	})
+}
+
+// pendingEventsKey 事务中暂存待投递事件的键
+const pendingEventsKey = "infra.pending_events"
+
+// eventBufferSize 每个订阅者的缓冲事件数。请求中的订阅者（SSE）缓冲满时丢弃新事件而不阻塞发布者，
+// 后台订阅者缓冲满时事件在队列中等待
+const eventBufferSize = 64
+
+// EventBus 进程内事件总线的请求组件，所有请求共用同一组订阅者。
+// 只在单进程内投递，多实例部署时各实例只能收到自己发布的事件。
+type EventBus struct {
+	freedom.Infra
+}
+
+// BeginRequest .
+func (b *EventBus) BeginRequest(worker freedom.Worker) {
+	b.Infra.BeginRequest(worker)
+}
+
+// Publish 发布事件。在 Transaction.Execute 中发布时先暂存，事务提交后投递，回滚时丢弃
+func (b *EventBus) Publish(event vo.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if b.Worker() != nil && event.ActorID == 0 {
+		if user, ok := CurrentUser(b.Worker().IrisContext()); ok {
+			event.ActorID = user.ID
+		}
+	}
+	if b.Worker() != nil {
+		if pending, ok := b.Worker().Store().Get(pendingEventsKey).(*[]vo.Event); ok {
+			*pending = append(*pending, event)
+			return
+		}
+	}
+	events.deliver(event)
+}
+
+// Subscribe 订阅 topics 中的事件，为空时订阅全部主题；用完后必须 Close。
+// 处理不及时时丢弃新事件，适合 SSE 等只关心最新状态的订阅者
+func (b *EventBus) Subscribe(topics []string) *Subscription {
+	return events.subscribe(topics, false)
+}
+
+// SubscribeEvents 在请求之外（如启动时的后台任务）订阅事件，用法同 EventBus.Subscribe。
+// 不丢弃事件：处理不及时的事件在不限长度的队列中等待，不阻塞发布者
+func SubscribeEvents(topics []string) *Subscription {
+	return events.subscribe(topics, true)
+}
+
+// Subscription 一个事件订阅
+type Subscription struct {
+	topics []string
+	events chan vo.Event
+	queue  *eventQueue // 不丢弃事件的订阅者的等待队列，为 nil 时缓冲满即丢弃
+}
+
+// Events 事件通道，Close 后关闭
+func (s *Subscription) Events() <-chan vo.Event {
+	return s.events
+}
+
+// Close 取消订阅并关闭事件通道，可以重复调用
+func (s *Subscription) Close() {
+	events.unsubscribe(s)
+}
+
+// EventStreamResponse 以 Server-Sent Events 推送订阅的事件，每隔 Heartbeat 发送注释行保持连接，
+// 客户端断开或订阅关闭时返回并取消订阅。每个事件发送两次：事件名为主题（如 order-status），
+// 以及实体和 ID（如 order-5），页面可以只监听某一行对应的实体；不针对单个实体（ID 为 0）的事件只发送前者。
+type EventStreamResponse struct {
+	Subscription *Subscription
+	Heartbeat    time.Duration
+}
+
+// Dispatch .
+func (srep EventStreamResponse) Dispatch(ctx freedom.Context) {
+	defer srep.Subscription.Close()
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	// 关闭 nginx 等反向代理的响应缓冲
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.StatusCode(200)
+
+	w := ctx.ResponseWriter()
+	// 断线后 EventSource 3 秒重连
+	if _, err := io.WriteString(w, "retry: 3000\n\n"); err != nil {
+		return
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(srep.Heartbeat)
+	defer ticker.Stop()
+	done := ctx.Request().Context().Done()
+	for {
+		var err error
+		select {
+		case <-done:
+			return
+		case event, ok := <-srep.Subscription.Events():
+			if !ok {
+				return
+			}
+			err = writeEvent(w, event)
+		case <-ticker.C:
+			_, err = io.WriteString(w, ": ping\n\n")
+		}
+		if err != nil {
+			return
+		}
+		w.Flush()
+	}
+}
+
+// writeEvent 按 SSE 格式写入事件，数据为单行 JSON
+func writeEvent(w io.Writer, event vo.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Topic, data); err != nil || event.EntityID == 0 {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s-%d\ndata: %s\n\n", event.Entity, event.EntityID, data)
+	return err
+}
+
+// eventHub 进程内的订阅者集合
+type eventHub struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+var events = &eventHub{subscribers: make(map[*Subscription]struct{})}
+
+func (h *eventHub) subscribe(topics []string, queued bool) *Subscription {
+	sub := &Subscription{topics: topics, events: make(chan vo.Event, eventBufferSize)}
+	if queued {
+		sub.queue = &eventQueue{wake: make(chan struct{}, 1)}
+		go sub.queue.pump(sub.events)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (h *eventHub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	if sub.queue != nil {
+		// 由 pump 转完队列中的事件后关闭通道
+		close(sub.queue.wake)
+		return
+	}
+	close(sub.events)
+}
+
+// deliver 把事件投递给订阅了该主题的订阅者，持有读锁保证不会向已关闭的通道发送
+func (h *eventHub) deliver(event vo.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subscribers {
+		if len(sub.topics) > 0 && !slices.Contains(sub.topics, event.Topic) {
+			continue
+		}
+		if sub.queue != nil {
+			sub.queue.push(event)
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			freedom.Logger().Warnf("事件订阅者处理过慢，丢弃事件 %s", event.Topic)
+		}
+	}
+}
+
+// eventQueue 不限长度的事件队列，由 pump 按发布顺序转入订阅通道
+type eventQueue struct {
+	mu     sync.Mutex
+	events []vo.Event
+	wake   chan struct{} // 有新事件时通知 pump，取消订阅时关闭
+}
+
+// push 追加事件并唤醒 pump，不阻塞
+func (q *eventQueue) push(event vo.Event) {
+	q.mu.Lock()
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump 把队列中的事件依次转入 out，取消订阅后转完剩余事件并关闭 out
+func (q *eventQueue) pump(out chan<- vo.Event) {
+	defer close(out)
+	for range q.wake {
+		for {
+			q.mu.Lock()
+			pending := q.events
+			q.events = nil
+			q.mu.Unlock()
+			if len(pending) == 0 {
+				break
+			}
+			for _, event := range pending {
+				out <- event
+			}
+		}
+	}
+}
+
+// beginEvents 开始暂存 worker 发布的事件，已经在暂存时（嵌套事务）返回 false，由外层负责投递
+func beginEvents(worker freedom.Worker) bool {
+	if worker == nil || worker.Store().Get(pendingEventsKey) != nil {
+		return false
+	}
+	worker.Store().Set(pendingEventsKey, &[]vo.Event{})
+	return true
+}
+
+// endEvents 结束暂存，commit 为 true 时投递暂存的事件，否则丢弃
+func endEvents(worker freedom.Worker, commit bool) {
+	pending, _ := worker.Store().Get(pendingEventsKey).(*[]vo.Event)
+	worker.Store().Remove(pendingEventsKey)
+	if !commit || pending == nil {
+		return
+	}
+	for _, event := range *pending {
+		events.deliver(event)
+	}
+}
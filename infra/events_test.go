@@ -0,0 +1,63 @@
+package infra
+
+import (
+	"godash/domain/vo"
+	"sync"
+	"testing"
+)
+
+func TestEventsQueuedSubscriberKeepsAllEvents(t *testing.T) {
+	const topic, total = "test-queued", 500
+	queued := SubscribeEvents([]string{topic})
+	bounded := events.subscribe([]string{topic}, false)
+	defer bounded.Close()
+
+	// 订阅者都不读取，后台订阅者的事件在队列中等待，SSE 订阅者缓冲满后丢弃
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			events.deliver(vo.Event{Topic: topic, EntityID: int64(i + 1)})
+		}(i)
+	}
+	wg.Wait()
+	queued.Close()
+
+	seen := make(map[int64]bool, total)
+	for event := range queued.Events() {
+		if seen[event.EntityID] {
+			t.Fatalf("event %d delivered twice", event.EntityID)
+		}
+		seen[event.EntityID] = true
+	}
+	if len(seen) != total {
+		t.Errorf("queued subscriber received %d events, want %d", len(seen), total)
+	}
+	if got := len(bounded.Events()); got != eventBufferSize {
+		t.Errorf("bounded subscriber buffered %d events, want %d", got, eventBufferSize)
+	}
+}
+
+func TestEventsCloseWhileDelivering(t *testing.T) {
+	const topic = "test-close"
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		sub := events.subscribe([]string{topic}, i%2 == 0)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				events.deliver(vo.Event{Topic: topic})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			sub.Close()
+			sub.Close()
+			for range sub.Events() {
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,207 @@
+package infra
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/8treenet/freedom"
+)
+
+// 导出格式
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// ExportResponse 以附件形式流式导出表格，Rows 逐行调用 write，整张表不会缓存在内存中。
+// 单元格支持 string、整数、float64 和 time.Time，XLSX 中数值写为数字单元格。
+type ExportResponse struct {
+	Filename string // 文件名，不含扩展名
+	Format   string // csv 或 xlsx，其他值按 csv 导出
+	Header   []string
+	Rows     func(write func(row ...interface{}) error) error
+}
+
+// Dispatch .
+func (erep ExportResponse) Dispatch(ctx freedom.Context) {
+	var table tableWriter
+	format := ExportCSV
+	if erep.Format == ExportXLSX {
+		format = ExportXLSX
+		// ContentType 会把含 "." 的值当作扩展名处理，这里直接设置响应头
+		ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		table = newXLSXWriter(ctx.ResponseWriter())
+	} else {
+		ctx.ContentType("text/csv; charset=utf-8")
+		table = newCSVWriter(ctx.ResponseWriter())
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, erep.Filename, format))
+	ctx.StatusCode(200)
+
+	header := make([]interface{}, len(erep.Header))
+	for i, name := range erep.Header {
+		header[i] = name
+	}
+	// 响应头已经发出，中途失败只能记录日志，客户端收到的是截断的文件
+	err := table.WriteRow(header)
+	if err == nil {
+		err = erep.Rows(func(row ...interface{}) error {
+			return table.WriteRow(row)
+		})
+	}
+	if closeErr := table.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		freedom.Logger().Errorf("导出 %s.%s 失败: %v", erep.Filename, format, err)
+	}
+}
+
+// formulaPrefixes 表格软件会当作公式处理的开头字符
+const formulaPrefixes = "=+-@\t\r"
+
+// tableWriter 表格写入器
+type tableWriter interface {
+	WriteRow(row []interface{}) error
+	Close() error
+}
+
+// cellString 单元格的文本形式
+func cellString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// csvWriter CSV 写入器，带 UTF-8 BOM 以便 Excel 正确识别中文
+type csvWriter struct {
+	out     io.Writer
+	w       *csv.Writer
+	started bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{out: w, w: csv.NewWriter(w)}
+}
+
+// WriteRow .
+func (cw *csvWriter) WriteRow(row []interface{}) error {
+	if !cw.started {
+		cw.started = true
+		if _, err := io.WriteString(cw.out, "\ufeff"); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(row))
+	for i, value := range row {
+		record[i] = cellString(value)
+		// 以公式字符开头的文本加前缀，防止在表格软件中被当作公式执行
+		if s, ok := value.(string); ok && s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+			record[i] = "'" + s
+		}
+	}
+	return cw.w.Write(record)
+}
+
+// Close .
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// XLSX 包中固定不变的部件
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter 只含一个工作表的 XLSX 写入器。工作表是 zip 中的最后一个部件，
+// 行数据直接压缩写入响应，不依赖临时文件。
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	err   error
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	xw := &xlsxWriter{zip: zip.NewWriter(w)}
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", xlsxSheetStart},
+	}
+	for _, part := range parts {
+		f, err := xw.zip.Create(part.name)
+		if err == nil {
+			_, err = io.WriteString(f, part.body)
+		}
+		if err != nil {
+			xw.err = err
+			return xw
+		}
+		xw.sheet = bufio.NewWriter(f)
+	}
+	return xw
+}
+
+// WriteRow .
+func (xw *xlsxWriter) WriteRow(row []interface{}) error {
+	if xw.err != nil {
+		return xw.err
+	}
+	xw.sheet.WriteString("<row>")
+	for _, value := range row {
+		switch v := value.(type) {
+		case int, int32, int64, float64:
+			fmt.Fprintf(xw.sheet, "<c><v>%s</v></c>", cellString(v))
+		default:
+			xw.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(xw.sheet, []byte(cellString(v)))
+			xw.sheet.WriteString("</t></is></c>")
+		}
+	}
+	_, xw.err = xw.sheet.WriteString("</row>")
+	return xw.err
+}
+
+// Close 写入工作表结尾并完成 zip 目录
+func (xw *xlsxWriter) Close() error {
+	if xw.err == nil {
+		xw.sheet.WriteString(xlsxSheetEnd)
+		xw.err = xw.sheet.Flush()
+	}
+	if err := xw.zip.Close(); xw.err == nil {
+		xw.err = err
+	}
+	return xw.err
+}
@@ -0,0 +1,71 @@
+package infra
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrEmptyCSV CSV 文件没有表头
+var ErrEmptyCSV = errors.New("CSV 文件为空")
+
+// ReadCSV 读取带表头的 CSV，把每行按表头转换为 map 后调用 fn，line 为该行在文件中的行号。
+// 表头不区分大小写，会去掉 UTF-8 BOM 和导出时为防公式加的单引号；required 中的列缺失时返回错误。
+func ReadCSV(r io.Reader, required []string, fn func(line int, record map[string]string) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return ErrEmptyCSV
+	}
+	if err != nil {
+		return err
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	columns := make(map[string]bool, len(header))
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		columns[header[i]] = true
+	}
+	var missing []string
+	for _, name := range required {
+		if !columns[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("CSV 缺少列: %s", strings.Join(missing, ", "))
+	}
+
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		record := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(fields) {
+				record[name] = unescapeCell(strings.TrimSpace(fields[i]))
+			}
+		}
+		if err := fn(line, record); err != nil {
+			return err
+		}
+	}
+}
+
+// unescapeCell 去掉 csvWriter 为公式字符加的单引号前缀
+func unescapeCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,254 @@
+package infra
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/8treenet/freedom"
+)
+
+// A4 纵向页面尺寸，单位为点（1/72 英寸）
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// PDFResponse 以内联方式返回 PDF，浏览器直接预览和打印
+type PDFResponse struct {
+	Filename string // 文件名，不含扩展名
+	Document *PDF
+}
+
+// Dispatch .
+func (prep PDFResponse) Dispatch(ctx freedom.Context) {
+	// ContentType 会把含 "." 的值当作扩展名处理，这里直接设置响应头
+	ctx.Header("Content-Type", "application/pdf")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, prep.Filename))
+	ctx.StatusCode(200)
+	if _, err := prep.Document.WriteTo(ctx.ResponseWriter()); err != nil {
+		freedom.Logger().Errorf("输出 %s.pdf 失败: %v", prep.Filename, err)
+	}
+}
+
+// PDF 极简的 PDF 文档生成器，只支持文本、直线和矩形，坐标原点在页面左上角。
+// 拉丁字符使用 Helvetica，中文等其他字符使用阅读器内置的 STSong-Light（Adobe-GB1），
+// 两者都是 PDF 标准字体，不嵌入字体文件，生成的文档只有几 KB。
+type PDF struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+}
+
+// NewPDF 创建空文档，写入内容前需要先 AddPage
+func NewPDF() *PDF {
+	return &PDF{}
+}
+
+// AddPage 开始新的一页
+func (p *PDF) AddPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+}
+
+// SetPage 切换到第 i 页（从 0 开始）继续写入，用于在全部内容写完后补充页脚和页码
+func (p *PDF) SetPage(i int) {
+	p.page = p.pages[i]
+}
+
+// PageCount 当前页数
+func (p *PDF) PageCount() int {
+	return len(p.pages)
+}
+
+// Text 在 (x, y) 处写一行文本，y 为基线位置
+func (p *PDF) Text(x, y, size float64, bold bool, text string) {
+	fmt.Fprintf(p.page, "BT 1 0 0 1 %.2f %.2f Tm ", x, PageHeight-y)
+	for _, run := range splitRuns(text) {
+		font := "F1"
+		if bold {
+			font = "F2"
+		}
+		if run.cjk {
+			// 中文字体没有粗体，用描边加粗
+			font = "F3"
+			if bold {
+				fmt.Fprintf(p.page, "2 Tr %.2f w ", size/30)
+			}
+		}
+		fmt.Fprintf(p.page, "/%s %.2f Tf <%X> Tj ", font, size, run.encode())
+		if run.cjk && bold {
+			p.page.WriteString("0 Tr ")
+		}
+	}
+	p.page.WriteString("ET\n")
+}
+
+// TextRight 写一行右对齐的文本，right 为右边界
+func (p *PDF) TextRight(right, y, size float64, bold bool, text string) {
+	p.Text(right-TextWidth(text, size, bold), y, size, bold, text)
+}
+
+// Line 画一条直线
+func (p *PDF) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(p.page, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Rect 画矩形，gray 为填充灰度（0 黑 1 白），小于 0 时只描边不填充
+func (p *PDF) Rect(x, y, w, h, gray float64) {
+	if gray < 0 {
+		fmt.Fprintf(p.page, "0.5 w %.2f %.2f %.2f %.2f re S\n", x, PageHeight-y-h, w, h)
+		return
+	}
+	fmt.Fprintf(p.page, "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, PageHeight-y-h, w, h)
+}
+
+// WriteTo 输出完整的 PDF 文件
+func (p *PDF) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// 对象编号：1 目录，2 页面树，3-6 字体，之后每页一个页面对象和一个内容流
+	const firstPage = 7
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [6 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor << /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >> /DW 1000 >>")
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPage+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.WriteTo(w)
+}
+
+// TextWidth 文本在指定字号下的宽度
+func TextWidth(text string, size float64, bold bool) float64 {
+	width := 0.0
+	for _, r := range text {
+		if code, ok := winAnsi(r); ok {
+			width += latinWidth(code, bold)
+			continue
+		}
+		width += 1000
+	}
+	return width * size / 1000
+}
+
+// FitText 超出 maxWidth 时截断并以 "..." 结尾
+func FitText(text string, size, maxWidth float64, bold bool) string {
+	if TextWidth(text, size, bold) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if fitted := string(runes) + "..."; TextWidth(fitted, size, bold) <= maxWidth {
+			return fitted
+		}
+	}
+	return ""
+}
+
+// textRun 使用同一种字体的一段文本
+type textRun struct {
+	cjk   bool
+	runes []rune
+}
+
+// encode Helvetica 段按 WinAnsi 编码，中文段按 UCS-2 大端编码，BMP 以外的字符替换为问号
+func (run textRun) encode() []byte {
+	var out []byte
+	for _, r := range run.runes {
+		if !run.cjk {
+			code, _ := winAnsi(r)
+			out = append(out, code)
+			continue
+		}
+		if r > 0xFFFF || utf16.IsSurrogate(r) {
+			r = '?'
+		}
+		out = append(out, byte(r>>8), byte(r))
+	}
+	return out
+}
+
+// splitRuns 按字体把文本切分成连续的段
+func splitRuns(text string) []textRun {
+	var runs []textRun
+	for _, r := range text {
+		_, latin := winAnsi(r)
+		if n := len(runs); n > 0 && runs[n-1].cjk == !latin {
+			runs[n-1].runes = append(runs[n-1].runes, r)
+			continue
+		}
+		runs = append(runs, textRun{cjk: !latin, runes: []rune{r}})
+	}
+	return runs
+}
+
+// winAnsi 字符在 WinAnsiEncoding 中的编码，只处理 ASCII、Latin-1 和欧元符号
+func winAnsi(r rune) (byte, bool) {
+	switch {
+	case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+		return byte(r), true
+	case r == '€':
+		return 0x80, true
+	}
+	return 0, false
+}
+
+// latinWidth Helvetica 字符宽度（千分之一字号），取自标准字体的 AFM 文件
+func latinWidth(code byte, bold bool) float64 {
+	table := helveticaWidths
+	if bold {
+		table = helveticaBoldWidths
+	}
+	if code >= 0x20 && code < 0x7F {
+		return float64(table[code-0x20])
+	}
+	return 556
+}
+
+// helveticaWidths Helvetica 的 ASCII 字符宽度，从空格（0x20）到 ~（0x7E）
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths Helvetica-Bold 的 ASCII 字符宽度
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
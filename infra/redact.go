@@ -0,0 +1,67 @@
+package infra
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/8treenet/freedom"
+)
+
+// rawBodyKey 被隐藏字段之前的原始请求体
+const rawBodyKey = "raw_request_body"
+
+// maxFormBytes 普通表单请求体的上限，超过时返回 413；文件上传是 multipart，不经过这里
+const maxFormBytes = 1 << 20
+
+// NewBodyRedactor 隐藏表单中的敏感字段，避免请求日志记录明文密码。
+// redact 安装在请求日志中间件之前，restore 安装在其之后，把原始请求体交还给控制器。
+func NewBodyRedactor(fields ...string) (redact, restore freedom.Handler) {
+	redact = func(ctx freedom.Context) {
+		if !strings.HasPrefix(ctx.GetContentTypeRequested(), "application/x-www-form-urlencoded") {
+			ctx.Next()
+			return
+		}
+
+		raw, err := io.ReadAll(http.MaxBytesReader(ctx.ResponseWriter(), ctx.Request().Body, maxFormBytes))
+		ctx.Request().Body.Close()
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				ctx.StatusCode(http.StatusRequestEntityTooLarge)
+			} else {
+				ctx.StatusCode(http.StatusBadRequest)
+			}
+			ctx.StopExecution()
+			return
+		}
+		body := raw
+
+		if values, err := url.ParseQuery(string(raw)); err == nil {
+			redacted := false
+			for _, field := range fields {
+				if _, ok := values[field]; ok {
+					values.Set(field, "***")
+					redacted = true
+				}
+			}
+			if redacted {
+				ctx.Values().Set(rawBodyKey, raw)
+				body = []byte(values.Encode())
+			}
+		}
+		ctx.Request().Body = io.NopCloser(bytes.NewReader(body))
+		ctx.Next()
+	}
+
+	restore = func(ctx freedom.Context) {
+		if raw, ok := ctx.Values().Get(rawBodyKey).([]byte); ok {
+			ctx.Request().Body = io.NopCloser(bytes.NewReader(raw))
+		}
+		ctx.Next()
+	}
+	return
+}
@@ -5,9 +5,13 @@ import (
 	"io/ioutil"
 
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/8treenet/freedom"
+	"github.com/8treenet/iris/v12/context"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -15,6 +19,16 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	// date 校验 2006-01-02 格式的日期字符串，用于列表的日期筛选
+	validate.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse("2006-01-02", fl.Field().String())
+		return err == nil
+	})
+	// timezone 校验 IANA 时区名称，如 Asia/Shanghai
+	validate.RegisterValidation("timezone", func(fl validator.FieldLevel) bool {
+		_, err := time.LoadLocation(fl.Field().String())
+		return err == nil
+	})
 	freedom.Prepare(func(initiator freedom.Initiator) {
 		initiator.BindInfra(false, func() *Request {
 			return &Request{}
@@ -52,9 +66,10 @@ func (req *Request) ReadJSON(obj interface{}, validates ...bool) error {
 	return req.validate(obj)
 }
 
-// ReadQuery .
+// ReadQuery 读取查询参数，忽略 obj 中没有的参数，
+// 这样搜索分页参数和各列表的筛选条件可以分别从同一个查询串读取
 func (req *Request) ReadQuery(obj interface{}, validates ...bool) error {
-	if err := req.Worker().IrisContext().ReadQuery(obj); err != nil {
+	if err := req.Worker().IrisContext().ReadQuery(obj); err != nil && !context.IsErrPath(err) {
 		return err
 	}
 	if len(validates) == 0 || !validates[0] {
@@ -63,9 +78,10 @@ func (req *Request) ReadQuery(obj interface{}, validates ...bool) error {
 	return validate.Struct(obj)
 }
 
-// ReadForm .
+// ReadForm 读取表单，与 ReadQuery 一样忽略 obj 中没有的字段，
+// 这样表单里只用于页面交互的输入（如商品选择器的搜索框）不会导致读取失败
 func (req *Request) ReadForm(obj interface{}, validates ...bool) error {
-	if err := req.Worker().IrisContext().ReadForm(obj); err != nil {
+	if err := req.Worker().IrisContext().ReadForm(obj); err != nil && !context.IsErrPath(err) {
 		return err
 	}
 	if len(validates) == 0 || !validates[0] {
@@ -74,6 +90,28 @@ func (req *Request) ReadForm(obj interface{}, validates ...bool) error {
 	return req.validate(obj)
 }
 
+// Validate 按 validate 标签校验结构体，用于不经过表单读取的数据，如导入文件中的行
+func (req *Request) Validate(obj interface{}) error {
+	return req.validate(obj)
+}
+
+// ValidationMessages 把校验错误拆成逐个字段的提示
+func ValidationMessages(err error) []string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []string{err.Error()}
+	}
+	messages := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		messages = append(messages, fmt.Sprintf("%s 不符合 %s", fe.Field(), rule))
+	}
+	return messages
+}
+
 // validate .
 func (req *Request) validate(obj interface{}) error {
 	val := reflect.ValueOf(obj)
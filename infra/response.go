@@ -14,6 +14,7 @@ type ViewResponse = mvc.View
 // JSONResponse .
 type JSONResponse struct {
 	Code             int
+	Status           int // HTTP 状态码，默认 200，业务结果由 Code 表示
 	Error            error
 	Object           interface{}
 	DisableLogOutput bool
@@ -46,9 +47,46 @@ func (jrep JSONResponse) Dispatch(ctx freedom.Context) {
 		ctx.Values().Set("response", string(content))
 	}
 
+	status := jrep.Status
+	if status == 0 {
+		status = 200
+	}
 	ctx.ContentType("application/json")
-	ctx.StatusCode(200)
+	ctx.StatusCode(status)
 	if _, err := ctx.Write(content); err != nil {
 		freedom.Logger().Error("JSONResponse dispatch error:%!v(MISSING)", err)
 	}
 }
+
+// LocationResponse 在 SPA 内打开页面，HTMX 请求返回 HX-Location，把页面加载到 Target 并更新地址栏；
+// 普通请求返回 302
+type LocationResponse struct {
+	Path   string
+	Target string
+}
+
+// Dispatch .
+func (lrep LocationResponse) Dispatch(ctx freedom.Context) {
+	if ctx.GetHeader("HX-Request") == "true" {
+		location, _ := json.Marshal(map[string]string{"path": lrep.Path, "target": lrep.Target})
+		ctx.Header("HX-Location", string(location))
+		ctx.StatusCode(200)
+		return
+	}
+	ctx.Redirect(lrep.Path, 302)
+}
+
+// RedirectResponse 页面跳转，HTMX 请求返回 HX-Redirect，普通请求返回 302
+type RedirectResponse struct {
+	URL string
+}
+
+// Dispatch .
+func (rrep RedirectResponse) Dispatch(ctx freedom.Context) {
+	if ctx.GetHeader("HX-Request") == "true" {
+		ctx.Header("HX-Redirect", rrep.URL)
+		ctx.StatusCode(200)
+		return
+	}
+	ctx.Redirect(rrep.URL, 302)
+}
@@ -0,0 +1,170 @@
+package infra
+
+import (
+	"godash/config"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/8treenet/freedom"
+	"github.com/8treenet/iris/v12/sessions"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindInfra(false, func() *Session {
+			return &Session{}
+		})
+		initiator.InjectController(func(ctx freedom.Context) (com *Session) {
+			initiator.FetchInfra(ctx, &com)
+			return
+		})
+	})
+}
+
+// 会话中保存的键
+const (
+	sessionUserID   = "user_id"
+	sessionUsername = "username"
+	sessionRealName = "real_name"
+	sessionRole     = "role"
+)
+
+// currentUserKey 认证中间件写入 ctx.Values() 的当前用户
+const currentUserKey = "current_user"
+
+// LoginPath 登录页地址
+const LoginPath = "/login"
+
+var (
+	sessionsOnce sync.Once
+	sessionsMgr  *sessions.Sessions
+)
+
+// getSessions 按配置创建会话管理器，redis 存储在首次使用时才连接
+func getSessions() *sessions.Sessions {
+	sessionsOnce.Do(func() {
+		conf := config.Get().Auth
+		sessionsMgr = sessions.New(sessions.Config{
+			Cookie:       conf.Cookie,
+			Expires:      time.Duration(conf.Expires) * time.Minute,
+			AllowReclaim: true,
+		})
+		if conf.SessionStore == config.SessionStoreRedis {
+			sessionsMgr.UseDatabase(&redisSessionDB{})
+		}
+	})
+	return sessionsMgr
+}
+
+// SessionUser 会话中的登录用户
+type SessionUser struct {
+	ID       int64
+	Username string
+	RealName string
+	Role     string
+}
+
+// Session 登录会话组件
+type Session struct {
+	freedom.Infra
+	session *sessions.Session
+}
+
+// BeginRequest .
+func (s *Session) BeginRequest(worker freedom.Worker) {
+	s.Infra.BeginRequest(worker)
+	s.session = getSessions().Start(worker.IrisContext())
+}
+
+// Login 写入登录用户，并更换会话 ID 防止会话固定
+func (s *Session) Login(user SessionUser) {
+	ctx := s.Worker().IrisContext()
+	getSessions().Destroy(ctx)
+	s.session = getSessions().Start(ctx)
+	s.session.Set(sessionUserID, user.ID)
+	s.session.Set(sessionUsername, user.Username)
+	s.session.Set(sessionRealName, user.RealName)
+	s.session.Set(sessionRole, user.Role)
+	ctx.Values().Set(currentUserKey, user)
+}
+
+// Logout 销毁会话
+func (s *Session) Logout() {
+	getSessions().Destroy(s.Worker().IrisContext())
+}
+
+// User 当前登录用户
+func (s *Session) User() (SessionUser, bool) {
+	return sessionUser(s.session)
+}
+
+func sessionUser(session *sessions.Session) (SessionUser, bool) {
+	id, err := session.GetInt64(sessionUserID)
+	if err != nil || id <= 0 {
+		return SessionUser{}, false
+	}
+	return SessionUser{
+		ID:       id,
+		Username: session.GetString(sessionUsername),
+		RealName: session.GetString(sessionRealName),
+		Role:     session.GetString(sessionRole),
+	}, true
+}
+
+// CurrentUser 认证中间件放入上下文的登录用户
+func CurrentUser(ctx freedom.Context) (SessionUser, bool) {
+	user, ok := ctx.Values().Get(currentUserKey).(SessionUser)
+	return user, ok
+}
+
+// UserLoader 按会话中的用户 ID 重新读取登录用户。active 为 false 表示用户已删除、
+// 被封禁或未启用；err 为读取失败，此时不销毁会话
+type UserLoader func(ctx freedom.Context, id int64) (user SessionUser, active bool, err error)
+
+// NewAuthMiddleware 登录认证中间件，publicPaths 为免登录的路径前缀。
+// 每次请求用 load 重新读取用户，封禁、删除和角色变更立即生效，上下文中的角色总是最新的；
+// 未登录或用户已失效时销毁会话，HTMX 请求返回 HX-Redirect，普通请求 302 跳转到登录页。
+func NewAuthMiddleware(load UserLoader, publicPaths ...string) freedom.Handler {
+	return func(ctx freedom.Context) {
+		path := ctx.Path()
+		for _, prefix := range publicPaths {
+			if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+				ctx.Next()
+				return
+			}
+		}
+
+		session, ok := sessionUser(getSessions().Start(ctx))
+		if !ok {
+			redirectToLogin(ctx)
+			return
+		}
+		user, active, err := load(ctx, session.ID)
+		if err != nil {
+			freedom.ToWorker(ctx).Logger().Error("读取登录用户失败", freedom.LogFields{"id": session.ID, "error": err.Error()})
+			ctx.StatusCode(503)
+			ctx.StopExecution()
+			return
+		}
+		if !active {
+			getSessions().Destroy(ctx)
+			redirectToLogin(ctx)
+			return
+		}
+		ctx.Values().Set(currentUserKey, user)
+		ctx.Next()
+	}
+}
+
+func redirectToLogin(ctx freedom.Context) {
+	if ctx.GetHeader("HX-Request") == "true" {
+		ctx.Header("HX-Redirect", LoginPath)
+		ctx.StatusCode(401)
+		ctx.StopExecution()
+		return
+	}
+
+	ctx.Redirect(LoginPath, 302)
+	ctx.StopExecution()
+}
@@ -0,0 +1,101 @@
+package infra
+
+import (
+	"time"
+
+	"github.com/8treenet/freedom"
+	"github.com/8treenet/iris/v12/sessions"
+)
+
+// sessionKeyPrefix 会话在 redis 中的键前缀
+const sessionKeyPrefix = "godash:session:"
+
+var _ sessions.Database = (*redisSessionDB)(nil)
+
+// redisSessionDB 基于 freedom 安装的 redis 连接的会话存储，
+// 每个会话对应一个 hash，字段为会话键，值为 JSON 编码。
+type redisSessionDB struct {
+	freedom.Infra
+}
+
+func (db *redisSessionDB) key(sid string) string {
+	return sessionKeyPrefix + sid
+}
+
+// Acquire .
+func (db *redisSessionDB) Acquire(sid string, expires time.Duration) sessions.LifeTime {
+	ttl, err := db.Redis().TTL(db.key(sid)).Result()
+	if err != nil || ttl <= 0 {
+		return sessions.LifeTime{}
+	}
+	return sessions.LifeTime{Time: time.Now().Add(ttl)}
+}
+
+// OnUpdateExpiration .
+func (db *redisSessionDB) OnUpdateExpiration(sid string, newExpires time.Duration) error {
+	return db.Redis().Expire(db.key(sid), newExpires).Err()
+}
+
+// Set .
+func (db *redisSessionDB) Set(sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) {
+	data, err := sessions.DefaultTranscoder.Marshal(value)
+	if err != nil {
+		freedom.Logger().Error("session marshal", err)
+		return
+	}
+	pipe := db.Redis().TxPipeline()
+	pipe.HSet(db.key(sid), key, data)
+	if ttl := lifetime.DurationUntilExpiration(); ttl > 0 {
+		pipe.Expire(db.key(sid), ttl)
+	}
+	if _, err := pipe.Exec(); err != nil {
+		freedom.Logger().Error("session set", err)
+	}
+}
+
+// Get .
+func (db *redisSessionDB) Get(sid string, key string) (value interface{}) {
+	data, err := db.Redis().HGet(db.key(sid), key).Bytes()
+	if err != nil {
+		return nil
+	}
+	if err := sessions.DefaultTranscoder.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return
+}
+
+// Visit .
+func (db *redisSessionDB) Visit(sid string, cb func(key string, value interface{})) {
+	values, err := db.Redis().HGetAll(db.key(sid)).Result()
+	if err != nil {
+		return
+	}
+	for key, data := range values {
+		var value interface{}
+		if err := sessions.DefaultTranscoder.Unmarshal([]byte(data), &value); err != nil {
+			continue
+		}
+		cb(key, value)
+	}
+}
+
+// Len .
+func (db *redisSessionDB) Len(sid string) int {
+	return int(db.Redis().HLen(db.key(sid)).Val())
+}
+
+// Delete .
+func (db *redisSessionDB) Delete(sid string, key string) bool {
+	return db.Redis().HDel(db.key(sid), key).Val() > 0
+}
+
+// Clear .
+func (db *redisSessionDB) Clear(sid string) {
+	db.Redis().Del(db.key(sid))
+}
+
+// Release .
+func (db *redisSessionDB) Release(sid string) {
+	db.Redis().Del(db.key(sid))
+}
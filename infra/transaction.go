@@ -0,0 +1,109 @@
+package infra
+
+import (
+	"godash/config"
+
+	"github.com/8treenet/freedom"
+	"gorm.io/gorm"
+)
+
+func init() {
+	freedom.Prepare(func(initiator freedom.Initiator) {
+		initiator.BindInfra(false, func() *Transaction {
+			return &Transaction{}
+		})
+	})
+}
+
+// afterCommitKey worker 上暂存的提交后操作，同一请求中的各个 Transaction 共用，与暂存的事件相同
+const afterCommitKey = "transaction_after_commit"
+
+// Transaction 事务组件。数据库模式下 fun 内各资源库通过 FetchDB 拿到同一个事务，
+// fun 返回错误时整体回滚；内存模式没有事务，fun 返回错误时执行 fun 中登记的补偿。
+type Transaction struct {
+	freedom.Infra
+	memory        bool     // 正在执行内存模式的 fun
+	compensations []func() // 内存模式下 fun 中登记的补偿
+}
+
+// BeginRequest .
+func (t *Transaction) BeginRequest(worker freedom.Worker) {
+	t.Infra.BeginRequest(worker)
+}
+
+// Execute 在事务中执行 fun，fun 中发布的事件和登记的 AfterCommit 在事务提交后才执行
+func (t *Transaction) Execute(fun func() error) (e error) {
+	if beginEvents(t.Worker()) {
+		defer func() { endEvents(t.Worker(), e == nil) }()
+	}
+	if beginAfterCommit(t.Worker()) {
+		defer func() { endAfterCommit(t.Worker(), e == nil) }()
+	}
+	if config.Get().DB.Driver == config.DriverMemory {
+		return t.executeMemory(fun)
+	}
+	var db *gorm.DB
+	if err := t.FetchOnlyDB(&db); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		t.Worker().Store().Set(freedom.TransactionKey, tx)
+		defer t.Worker().Store().Remove(freedom.TransactionKey)
+		return fun()
+	})
+}
+
+// Compensate 登记 fun 返回错误时的补偿，只在内存模式的 Execute 中生效；数据库模式由事务回滚
+func (t *Transaction) Compensate(undo func()) {
+	if t.memory {
+		t.compensations = append(t.compensations, undo)
+	}
+}
+
+// AfterCommit 登记最外层 Execute 成功后执行的操作，如写审计日志，失败时丢弃；不在 Execute 中时立即执行
+func (t *Transaction) AfterCommit(fun func()) {
+	pending, _ := t.Worker().Store().Get(afterCommitKey).(*[]func())
+	if pending == nil {
+		fun()
+		return
+	}
+	*pending = append(*pending, fun)
+}
+
+// beginAfterCommit 开始暂存提交后操作，已在暂存中（嵌套的 Execute）时返回 false
+func beginAfterCommit(worker freedom.Worker) bool {
+	if worker == nil || worker.Store().Get(afterCommitKey) != nil {
+		return false
+	}
+	worker.Store().Set(afterCommitKey, &[]func(){})
+	return true
+}
+
+// endAfterCommit 结束暂存，commit 为 true 时按登记顺序执行，否则丢弃
+func endAfterCommit(worker freedom.Worker, commit bool) {
+	pending, _ := worker.Store().Get(afterCommitKey).(*[]func())
+	worker.Store().Remove(afterCommitKey)
+	if !commit || pending == nil {
+		return
+	}
+	for _, fun := range *pending {
+		fun()
+	}
+}
+
+// executeMemory 执行 fun，返回错误时按相反顺序执行登记的补偿；嵌套调用由外层负责补偿
+func (t *Transaction) executeMemory(fun func() error) error {
+	if t.memory {
+		return fun()
+	}
+	t.memory = true
+	err := fun()
+	compensations := t.compensations
+	t.memory, t.compensations = false, nil
+	if err != nil {
+		for i := len(compensations) - 1; i >= 0; i-- {
+			compensations[i]()
+		}
+	}
+	return err
+}
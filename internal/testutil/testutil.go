@@ -0,0 +1,33 @@
+// Package testutil 各包并发测试共用的辅助函数
+package testutil
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// run 区分同一进程内多次运行（-count）的测试数据，内存资源库的数据表是包级变量
+var run atomic.Int64
+
+// UniqueKey 本次运行中唯一的用户名、SKU 或订单号
+func UniqueKey(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), run.Add(1))
+}
+
+// Parallel 同时启动 n 个 goroutine 执行 fn 并等待全部结束
+func Parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+}
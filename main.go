@@ -2,9 +2,11 @@
 package main
 
 import (
-	_ "godash/adapter/controller" //Implicit initialization controller
+	"godash/adapter/controller"
 	_ "godash/adapter/repository" //Implicit initialization repository
 	"godash/config"
+	"godash/domain/po"
+	"godash/infra"
 	"godash/web/tmplfuncs"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/8treenet/freedom/infra/requests"
 	"github.com/8treenet/freedom/middleware"
 	"github.com/8treenet/iris/v12/view"
+	"github.com/glebarez/sqlite"
 	"github.com/go-redis/redis"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -26,6 +29,12 @@ func main() {
 	//viewEngine := view.HTML("./web/views", ".html")
 	app.Iris().RegisterView(viewEngine)
 	installMiddleware(app)
+	if config.Get().DB.Driver != config.DriverMemory {
+		installDatabase(app)
+	}
+	if config.Get().Auth.SessionStore == config.SessionStoreRedis {
+		installRedis(app)
+	}
 	runner := app.NewRunner(config.Get().App.Other["listen_addr"].(string))
 	//app.InstallParty("/api")
 	liveness(app)
@@ -35,11 +44,20 @@ func main() {
 func installMiddleware(app freedom.Application) {
 	app.InstallMiddleware(middleware.NewRecover())
 	app.InstallMiddleware(middleware.NewTrace("x-request-id"))
+	//Hide passwords from the access log, then hand the original body back.
+	redact, restore := infra.NewBodyRedactor("password")
+	app.InstallMiddleware(redact)
 	//One Loger per request New.
 	app.InstallMiddleware(middleware.NewRequestLogger("x-request-id"))
+	app.InstallMiddleware(restore)
 	//The middleware output of the log line.
 	app.Logger().Handle(middleware.DefaultLogRowHandle)
 
+	//Login required except for the login page, static files and liveness probe.
+	app.InstallMiddleware(infra.NewAuthMiddleware(controller.LoadSessionUser, infra.LoginPath, "/static", "/ping"))
+	//Maintenance mode from the system settings; admins and the allowlist pass through.
+	app.InstallMiddleware(controller.NewMaintenanceMiddleware(config.Get().Maintenance))
+
 	//Install the Prometheus middleware.
 	middle := middleware.NewClientPrometheus(config.Get().App.Other["service_name"].(string), freedom.Prometheus())
 	requests.InstallMiddleware(middle)
@@ -51,7 +69,7 @@ func installMiddleware(app freedom.Application) {
 func installDatabase(app freedom.Application) {
 	app.InstallDB(func() interface{} {
 		conf := config.Get().DB
-		db, err := gorm.Open(mysql.Open(conf.Addr), &gorm.Config{})
+		db, err := gorm.Open(dialector(conf), &gorm.Config{TranslateError: true})
 		if err != nil {
 			freedom.Logger().Fatal(err.Error())
 		}
@@ -67,10 +85,29 @@ func installDatabase(app freedom.Application) {
 		sqlDB.SetMaxIdleConns(conf.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(conf.MaxOpenConns)
 		sqlDB.SetConnMaxLifetime(time.Duration(conf.ConnMaxLifeTime) * time.Second)
+
+		if conf.AutoMigrate {
+			if err = db.AutoMigrate(po.Models()...); err != nil {
+				freedom.Logger().Fatal(err)
+			}
+			freedom.Logger().Info("auto migrate done")
+		}
 		return db
 	})
 }
 
+// dialector 根据配置的驱动选择数据库方言
+func dialector(conf config.DBConf) gorm.Dialector {
+	switch conf.Driver {
+	case config.DriverSQLite:
+		return sqlite.Open(conf.Addr)
+	case config.DriverMySQL:
+		return mysql.Open(conf.Addr)
+	}
+	freedom.Logger().Fatalf("unsupported db driver: %s", conf.Driver)
+	return nil
+}
+
 func installRedis(app freedom.Application) {
 	app.InstallRedis(func() (client redis.Cmdable) {
 		cfg := config.Get().Redis
@@ -167,8 +167,19 @@ document.addEventListener('DOMContentLoaded', function () {
         }
     });
 
-    // 监听 HTMX 错误
+    // 监听 HTMX 错误（如 403 无权限），优先显示服务端返回的提示
     document.body.addEventListener('htmx:responseError', function (event) {
+        const toastMessage = event.detail.xhr && event.detail.xhr.getResponseHeader('X-Toast-Message');
+        if (toastMessage) {
+            let decodedMessage = toastMessage;
+            try {
+                decodedMessage = decodeURIComponent(toastMessage);
+            } catch (e) {
+                // 解码失败时使用原始消息
+            }
+            showToast(decodedMessage, 'error');
+            return;
+        }
         showToast('请求失败，请稍后重试', 'error');
     });
 });
@@ -321,6 +332,32 @@ function debounce(func, wait = 300) {
     };
 }
 
+// 导出链接：点击时按 hx-include 收集当前的搜索、筛选和排序条件，拼到 data-url 后下载
+function exportList(link, format) {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(htmx.values(link))) {
+        for (const v of [].concat(value)) {
+            if (v !== '') params.append(key, v);
+        }
+    }
+    params.set('format', format);
+    link.href = link.dataset.url + '?' + params.toString();
+}
+
+// 批量选择：按全选框的状态勾选或取消 scope 内所有行的复选框
+function selectAll(source, scope) {
+    document.querySelectorAll(scope + " input[name='ids']").forEach(function (checkbox) {
+        checkbox.checked = source.checked;
+    });
+}
+
+// 批量操作完成后清空 scope 内的勾选（包括全选框）
+function clearSelection(scope) {
+    document.querySelectorAll(scope + " input[type='checkbox']").forEach(function (checkbox) {
+        checkbox.checked = false;
+    });
+}
+
 // 工具函数挂载
 window.utils = { formatDate, formatCurrency, debounce };
 
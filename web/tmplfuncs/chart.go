@@ -0,0 +1,199 @@
+package tmplfuncs
+
+import (
+	"fmt"
+	"godash/domain/vo"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 图表尺寸，SVG 按 viewBox 缩放到容器宽度
+const (
+	chartWidth  = 640.0
+	chartHeight = 220.0
+	chartLeft   = 56.0 // 左侧留给纵轴刻度
+	chartRight  = chartWidth - 12
+	chartTop    = 12.0
+	chartBottom = chartHeight - 28 // 下方留给横轴标签
+	chartTicks  = 4
+	chartLabels = 8 // 横轴最多显示的标签数
+)
+
+// Chart 服务端渲染的 SVG 图表，坐标已经换算到 viewBox，模板只负责输出元素
+type Chart struct {
+	Title  string
+	Width  float64
+	Height float64
+	Left   float64 // 绘图区域的边界
+	Right  float64
+	Top    float64
+	Bottom float64
+	YTicks []ChartTick
+	XTicks []ChartTick
+	Bars   []ChartBar
+	Lines  []ChartLine
+	Empty  bool // 所有数值都为 0
+
+	scale func(v float64) float64 // 数值 -> y 坐标
+	slot  float64                 // 每个时间段的宽度
+}
+
+// ChartTick 坐标轴刻度，Pos 为纵轴的 y 或横轴的 x
+type ChartTick struct {
+	Pos   float64
+	Label string
+}
+
+// ChartBar 柱状图的一根柱子
+type ChartBar struct {
+	X, Y, W, H float64
+	Title      string // 鼠标悬停提示
+}
+
+// ChartLine 折线，Class 为决定颜色的文字颜色类
+type ChartLine struct {
+	Name   string
+	Class  string
+	Points string // polyline 的 points 属性
+	Dots   []ChartDot
+}
+
+// ChartDot 折线上的数据点
+type ChartDot struct {
+	X, Y  float64
+	Title string
+}
+
+// seriesChart 由趋势数据生成图表：kind 为 revenue 时是收入柱状图，为 activity 时是订单数和新用户折线图，
+// 只画 series.Access 中可见的折线
+func seriesChart(series vo.Series, kind string) Chart {
+	labels := make([]string, len(series.Points))
+	for i, point := range series.Points {
+		labels[i] = point.Label
+	}
+
+	if kind == "revenue" {
+		revenue := make([]float64, len(series.Points))
+		for i, point := range series.Points {
+			revenue[i] = point.Revenue
+		}
+		chart := newChart("收入", labels, maxValue(revenue), false, formatCompactMoney)
+		chart.addBars(revenue, func(i int) string {
+			return labels[i] + " 收入 " + formatMoney(revenue[i])
+		})
+		return chart
+	}
+
+	orders := make([]float64, len(series.Points))
+	users := make([]float64, len(series.Points))
+	for i, point := range series.Points {
+		orders[i] = float64(point.Orders)
+		users[i] = float64(point.NewUsers)
+	}
+	chart := newChart("订单数和新用户", labels, max(maxValue(orders), maxValue(users)), true, formatCount)
+	// 没有权限的数据不画线，避免显示成 0
+	if series.Access.Orders {
+		chart.addLine("订单数", "text-primary", orders, labels)
+	}
+	if series.Access.Users {
+		chart.addLine("新用户", "text-secondary", users, labels)
+	}
+	return chart
+}
+
+// newChart 按最大值选取整齐的纵轴刻度，integer 为 true 时刻度间隔至少为 1
+func newChart(title string, labels []string, maxY float64, integer bool, format func(float64) string) Chart {
+	chart := Chart{
+		Title: title, Width: chartWidth, Height: chartHeight,
+		Left: chartLeft, Right: chartRight, Top: chartTop, Bottom: chartBottom,
+		Empty: maxY <= 0,
+	}
+	step := niceStep(maxY / chartTicks)
+	if integer {
+		step = math.Max(math.Ceil(step), 1)
+	}
+	top := step * chartTicks
+	chart.scale = func(v float64) float64 {
+		return chartBottom - v/top*(chartBottom-chartTop)
+	}
+	for i := 0; i <= chartTicks; i++ {
+		value := step * float64(i)
+		chart.YTicks = append(chart.YTicks, ChartTick{Pos: chart.scale(value), Label: format(value)})
+	}
+
+	chart.slot = (chartRight - chartLeft) / float64(max(len(labels), 1))
+	every := (len(labels) + chartLabels - 1) / chartLabels
+	for i, label := range labels {
+		if i%every == 0 {
+			chart.XTicks = append(chart.XTicks, ChartTick{Pos: chart.center(i), Label: label})
+		}
+	}
+	return chart
+}
+
+// addBars 每个数值一根柱子，占时间段宽度的 70%
+func (c *Chart) addBars(values []float64, title func(i int) string) {
+	for i, value := range values {
+		y := c.scale(value)
+		c.Bars = append(c.Bars, ChartBar{
+			X: chartLeft + c.slot*(float64(i)+0.15), Y: y, W: c.slot * 0.7, H: chartBottom - y,
+			Title: title(i),
+		})
+	}
+}
+
+// addLine 添加一条折线，数据点位于各时间段的中间
+func (c *Chart) addLine(name, class string, values []float64, labels []string) {
+	line := ChartLine{Name: name, Class: class}
+	points := make([]string, len(values))
+	for i, value := range values {
+		dot := ChartDot{X: c.center(i), Y: c.scale(value), Title: fmt.Sprintf("%s %s %s", labels[i], name, formatCount(value))}
+		points[i] = fmt.Sprintf("%.1f,%.1f", dot.X, dot.Y)
+		line.Dots = append(line.Dots, dot)
+	}
+	line.Points = strings.Join(points, " ")
+	c.Lines = append(c.Lines, line)
+}
+
+// center 第 i 个时间段中间的 x 坐标
+func (c *Chart) center(i int) float64 {
+	return chartLeft + c.slot*(float64(i)+0.5)
+}
+
+// niceStep 不小于 raw 的 1、2、5 乘以 10 的整数次幂
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 5} {
+		if raw <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// maxValue 最大值，空切片为 0
+func maxValue(values []float64) float64 {
+	result := 0.0
+	for _, value := range values {
+		result = max(result, value)
+	}
+	return result
+}
+
+// formatCount 整数计数
+func formatCount(value float64) string {
+	return strconv.FormatFloat(math.Round(value), 'f', -1, 64)
+}
+
+// formatCompactMoney 纵轴金额，一万及以上以"万"为单位
+func formatCompactMoney(value float64) string {
+	symbol := currencySymbol()
+	if value >= 10000 {
+		return symbol + strconv.FormatFloat(math.Round(value/100)/100, 'f', -1, 64) + "万"
+	}
+	return symbol + strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
@@ -3,6 +3,8 @@ package tmplfuncs
 import (
 	"encoding/json"
 	"fmt"
+	"godash/domain"
+	"godash/domain/vo"
 	"html/template"
 	"strings"
 	"time"
@@ -38,6 +40,18 @@ func Register(engine *view.HTMLEngine) {
 	engine.AddFunc("formatDate", formatDate)
 	engine.AddFunc("formatDateTime", formatDateTime)
 	engine.AddFunc("formatDateTimeFull", formatDateTimeFull)
+	engine.AddFunc("timeAgo", timeAgo)
+
+	// 金额格式化
+	engine.AddFunc("formatMoney", formatMoney)
+	engine.AddFunc("currencySymbol", currencySymbol)
+
+	// 业务状态名称
+	engine.AddFunc("orderStatusText", vo.OrderStatusText)
+	engine.AddFunc("refundStatusText", vo.RefundStatusText)
+
+	// 图表
+	engine.AddFunc("seriesChart", seriesChart)
 }
 
 // substr 截取字符串
@@ -149,17 +163,44 @@ func formatTime(t time.Time, layout string) string {
 	return t.Format(layout)
 }
 
-// formatDate 格式化日期（年-月-日）
+// formatDate 按系统设置的时区和日期格式输出日期
 func formatDate(t time.Time) string {
-	return t.Format("2006-01-02")
+	return domain.CurrentSettings().FormatDate(t)
 }
 
-// formatDateTime 格式化日期时间（年-月-日 时:分）
+// formatDateTime 按系统设置输出日期和时分
 func formatDateTime(t time.Time) string {
-	return t.Format("2006-01-02 15:04")
+	return domain.CurrentSettings().FormatDateTime(t)
 }
 
-// formatDateTimeFull 格式化完整日期时间（年-月-日 时:分:秒）
+// formatDateTimeFull 按系统设置输出日期和时分秒
 func formatDateTimeFull(t time.Time) string {
-	return t.Format("2006-01-02 15:04:05")
+	settings := domain.CurrentSettings()
+	return t.In(settings.Location()).Format(settings.DateLayout() + " 15:04:05")
+}
+
+// timeAgo 相对时间，如“5 分钟前”，超过 30 天显示日期
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "刚刚"
+	case d < time.Hour:
+		return fmt.Sprintf("%d 分钟前", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d 小时前", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%d 天前", int(d/(24*time.Hour)))
+	}
+	return formatDate(t)
+}
+
+// formatMoney 按系统设置的货币输出金额，如 ¥12.50
+func formatMoney(amount float64) string {
+	return domain.CurrentSettings().FormatMoney(amount)
+}
+
+// currencySymbol 系统设置的货币符号，用于金额输入框的前缀
+func currencySymbol() string {
+	return domain.CurrentSettings().CurrencySymbol()
 }